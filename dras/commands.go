@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// command is a dras subcommand.
+type command struct {
+	name string
+	// args is the usage of the command's arguments, after its name.
+	args    string
+	summary string
+	run     func(ctx context.Context, inv *invocation) error
+}
+
+// commands lists the subcommands in the order usage shows them. The first
+// one runs when no subcommand is given.
+var commands = []command{
+	{name: "run", summary: "Monitor the configured stations (the default).", run: runServe},
+	{name: "check", args: "[-json] STATION...", summary: "Print the current radar data of each station and exit.", run: runCheck},
+	{name: "once", summary: "Poll every configured station once, send the notifications due, and exit.\nExits non-zero if any station failed.", run: runOnce},
+	{name: "test-notify", args: "[-station ID]", summary: "Send a sample notification, with the radar image, through the configured\nnotification backends.", run: runTestNotify},
+	{name: "validate", summary: "Load and validate the configuration, print it, and exit.", run: runValidate},
+}
+
+// invocation is one run of a command: the global -config path, the
+// command's own flags and arguments, and where its output goes.
+type invocation struct {
+	configPath string
+	flags      *flag.FlagSet
+	args       []string
+	stdout     io.Writer
+}
+
+// errBadFlags reports that parsing the command's flags failed; the flag
+// package has already printed why.
+var errBadFlags = errors.New("invalid flags")
+
+// usageError is an error in how a command was invoked. It is printed with
+// the command's usage and exits with status 2.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// parse parses the command's flags.
+func (inv *invocation) parse() error {
+	if err := inv.flags.Parse(inv.args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errBadFlags
+	}
+	return nil
+}
+
+// noArgs parses the flags of a command that takes no arguments.
+func (inv *invocation) noArgs() error {
+	if err := inv.parse(); err != nil {
+		return err
+	}
+	if inv.flags.NArg() > 0 {
+		return usageError(fmt.Sprintf("unexpected arguments: %s", strings.Join(inv.flags.Args(), " ")))
+	}
+	return nil
+}
+
+// usage prints the program's usage to w.
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: dras [-config FILE] [COMMAND]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", c.name, strings.SplitN(c.summary, "\n", 2)[0])
+	}
+	fmt.Fprintf(w, "\nRun \"dras COMMAND -h\" for a command's options.\n\nFlags:\n")
+	flag.CommandLine.SetOutput(w)
+	flag.PrintDefaults()
+}
+
+// runCommand runs the subcommand named by args[0], or the monitor when
+// args is empty, and returns the process exit status. SIGINT and SIGTERM
+// cancel the command's context; a second signal exits immediately.
+func runCommand(configPath string, args []string, stdout, stderr io.Writer) int {
+	name := commands[0].name
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage(stdout)
+		return 0
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "dras: unknown command %q\n\n", name)
+		usage(stderr)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("dras "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: dras [-config FILE] %s\n\n%s\n", strings.TrimSpace(cmd.name+" "+cmd.args), cmd.summary)
+		fs.PrintDefaults()
+	}
+
+	// SIGINT/SIGTERM cancel ctx; the monitor then drains in-flight work for
+	// up to the shutdown grace period. Once ctx is cancelled the default
+	// signal behaviour is restored, so a second signal exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	// One-shot commands log to stderr so that their output on stdout stays
+	// clean; the monitor switches to stdout once its configuration is loaded.
+	slog.SetDefault(newLogger(os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"), stderr))
+
+	err := cmd.run(ctx, &invocation{configPath: configPath, flags: fs, args: args, stdout: stdout})
+	var ue usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errBadFlags):
+		return 2
+	case errors.As(err, &ue):
+		fmt.Fprintf(stderr, "dras %s: %v\n\n", cmd.name, err)
+		fs.Usage()
+		return 2
+	default:
+		fmt.Fprintf(stderr, "dras %s: %v\n", cmd.name, err)
+		return 1
+	}
+}
+
+// runServe runs the monitor.
+func runServe(ctx context.Context, inv *invocation) error {
+	if err := inv.noArgs(); err != nil {
+		return err
+	}
+	serve(ctx, inv.configPath)
+	return nil
+}
+
+// loadConfig loads and validates the configuration of a one-shot command
+// and applies its log level.
+func loadConfig(inv *invocation) (*config.Config, error) {
+	cfg, err := config.LoadFile(inv.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading configuration: %w", err)
+	}
+	slog.SetDefault(newLogger(cfg.LogLevel, os.Getenv("LOG_FORMAT"), inv.flags.Output()))
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	return cfg, nil
+}
+
+// runCheck prints the current radar data of the stations given as
+// arguments. It needs no valid configuration; only the NWS API settings
+// are read from it.
+func runCheck(ctx context.Context, inv *invocation) error {
+	asJSON := inv.flags.Bool("json", false, "print JSON instead of text")
+	if err := inv.parse(); err != nil {
+		return err
+	}
+	stationIDs, err := parseStationArgs(inv.flags.Args())
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadFile(inv.configPath)
+	if err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	fetcher := radar.New(radar.Config{BaseURL: cfg.NWSBaseURL, UserAgent: httpUserAgent()})
+	return checkStations(ctx, fetcher, stationIDs, *asJSON, inv.stdout)
+}
+
+// parseStationArgs splits, upper-cases and validates station IDs given as
+// arguments, each of which may hold several separated by commas.
+func parseStationArgs(args []string) ([]string, error) {
+	var ids, invalid []string
+	for _, arg := range args {
+		for _, id := range strings.FieldsFunc(arg, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+			id = strings.ToUpper(id)
+			if !radar.ValidateStationID(id) {
+				invalid = append(invalid, id)
+				continue
+			}
+			ids = append(ids, id)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, usageError(fmt.Sprintf("invalid station ID(s): %s (must be 4 letters)", strings.Join(invalid, ", ")))
+	}
+	if len(ids) == 0 {
+		return nil, usageError("at least one station ID is required")
+	}
+	return ids, nil
+}
+
+// checkResult is one station's entry in the output of "dras check -json".
+type checkResult struct {
+	Station        string         `json:"station"`
+	Data           *radar.Data    `json:"data,omitempty"`
+	VCPDescription string         `json:"vcp_description,omitempty"`
+	Severity       radar.Severity `json:"severity,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// checkStations fetches each station's radar data and writes it to w, as
+// text or as a JSON array. It returns an error when any fetch failed, after
+// writing the results of every station.
+func checkStations(ctx context.Context, fetcher radar.DataFetcher, stationIDs []string, asJSON bool, w io.Writer) error {
+	results := make([]checkResult, len(stationIDs))
+	failed := 0
+	for i, id := range stationIDs {
+		results[i].Station = id
+		data, err := fetcher.FetchData(ctx, id)
+		if err != nil {
+			results[i].Error = err.Error()
+			failed++
+			continue
+		}
+		// An unknown VCP still has a usable fallback description.
+		info, _ := radar.GetVCPInfo(data.VCP)
+		results[i].Data = data
+		results[i].VCPDescription = info.Description
+		results[i].Severity = radar.StateSeverity(data)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for i, r := range results {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeCheckResult(w, r)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d stations could not be fetched", failed, len(stationIDs))
+	}
+	return nil
+}
+
+// writeCheckResult writes one station's result as text.
+func writeCheckResult(w io.Writer, r checkResult) {
+	if r.Data == nil {
+		fmt.Fprintf(w, "%s\n  Error:        %s\n", r.Station, r.Error)
+		return
+	}
+	d := r.Data
+	fmt.Fprintf(w, "%s %s\n", r.Station, d.Name)
+	fmt.Fprintf(w, "  VCP:          %s (%s)\n", d.VCP, r.VCPDescription)
+	fmt.Fprintf(w, "  Mode:         %s\n", d.Mode)
+	fmt.Fprintf(w, "  Status:       %s\n", d.Status)
+	fmt.Fprintf(w, "  Operability:  %s\n", d.OperabilityStatus)
+	fmt.Fprintf(w, "  Power source: %s\n", d.PowerSource)
+	fmt.Fprintf(w, "  Generator:    %s\n", d.GenState)
+	fmt.Fprintf(w, "  Severity:     %s\n", r.Severity)
+}
+
+// runOnce polls every configured station once. Without STATE_FILE each run
+// starts from nothing and announces every station again, so it warns.
+func runOnce(ctx context.Context, inv *invocation) error {
+	if err := inv.noArgs(); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(inv)
+	if err != nil {
+		return err
+	}
+	if cfg.StateFile == "" {
+		slog.Warn("STATE_FILE is not set: every run sends a startup notification for each station instead of reporting changes")
+	}
+	m, _, err := newMonitor(cfg, httpUserAgent(), nil)
+	if err != nil {
+		return err
+	}
+	return m.RunOnce(ctx)
+}
+
+// runTestNotify sends a sample notification for one station.
+func runTestNotify(ctx context.Context, inv *invocation) error {
+	station := inv.flags.String("station", "", "station whose data and image the sample carries (default: the first configured station)")
+	if err := inv.noArgs(); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(inv)
+	if err != nil {
+		return err
+	}
+	if cfg.DryRun {
+		return errors.New("notifications are disabled in dry-run mode (DRYRUN=true)")
+	}
+
+	var stationID string
+	if *station != "" {
+		ids, err := parseStationArgs([]string{*station})
+		if err != nil {
+			return err
+		}
+		stationID = ids[0]
+	} else if ids := cfg.StationIDs(); len(ids) > 0 {
+		stationID = ids[0]
+	} else {
+		return usageError("no stations are configured; pass -station")
+	}
+
+	m, _, err := newMonitor(cfg, httpUserAgent(), nil)
+	if err != nil {
+		return err
+	}
+	if err := m.SendTestNotification(ctx, stationID); err != nil {
+		return err
+	}
+	fmt.Fprintf(inv.stdout, "Test notification for %s sent\n", stationID)
+	return nil
+}
+
+// runValidate loads and validates the configuration and prints it.
+func runValidate(_ context.Context, inv *invocation) error {
+	if err := inv.noArgs(); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(inv)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(inv.stdout, cfg.String())
+	fmt.Fprintln(inv.stdout, "Configuration is valid")
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestParseStationArgs(t *testing.T) {
+	ids, err := parseStationArgs([]string{"katx,KRAX", "KLOT"})
+	if err != nil {
+		t.Fatalf("parseStationArgs() error: %v", err)
+	}
+	if want := []string{"KATX", "KRAX", "KLOT"}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	var ue usageError
+	if _, err := parseStationArgs([]string{"KATX", "SEATTLE"}); !errors.As(err, &ue) || !strings.Contains(err.Error(), "SEATTLE") {
+		t.Errorf("invalid ID error = %v, want a usage error naming it", err)
+	}
+	if _, err := parseStationArgs(nil); !errors.As(err, &ue) {
+		t.Errorf("no IDs error = %v, want a usage error", err)
+	}
+}
+
+func TestCheckStations(t *testing.T) {
+	fetcher := radar.NewMockDataFetcher()
+	fetcher.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+	fetcher.SetError("KRAX", errors.New("boom"))
+
+	var text bytes.Buffer
+	err := checkStations(context.Background(), fetcher, []string{"KATX", "KRAX"}, false, &text)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("checkStations() error = %v, want the failed count", err)
+	}
+	for _, want := range []string{"KATX Seattle", "R35 (Clear Air, short pulse", "Severity:     info", "KRAX\n  Error:        boom"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := checkStations(context.Background(), fetcher, []string{"KATX"}, true, &out); err != nil {
+		t.Fatalf("checkStations() JSON error: %v", err)
+	}
+	var results []checkResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(results) != 1 || results[0].Station != "KATX" || results[0].Data == nil || results[0].Data.VCP != "R35" || results[0].Severity != radar.SeverityInfo {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	for _, key := range []string{"STATION_IDS", "DRYRUN", "INTERVAL", "PUSHOVER_API_TOKEN", "PUSHOVER_USER_KEY", "HTTP_ADDR"} {
+		t.Setenv(key, "")
+	}
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	writeFile(t, valid, "dry_run: true\ninterval: 5\n")
+	invalid := filepath.Join(dir, "invalid.yaml")
+	writeFile(t, invalid, "dry_run: true\ninterval: 0\n")
+
+	tests := []struct {
+		name       string
+		configPath string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "help", args: []string{"help"}, wantStdout: "test-notify"},
+		{name: "unknown command", args: []string{"frobnicate"}, wantCode: 2, wantStderr: `unknown command "frobnicate"`},
+		{name: "validate", configPath: valid, args: []string{"validate"}, wantStdout: "Configuration is valid"},
+		{name: "validate invalid", configPath: invalid, args: []string{"validate"}, wantCode: 1, wantStderr: "configuration validation failed"},
+		{name: "validate with arguments", configPath: valid, args: []string{"validate", "extra"}, wantCode: 2, wantStderr: "unexpected arguments: extra"},
+		{name: "check without stations", args: []string{"check"}, wantCode: 2, wantStderr: "at least one station ID is required"},
+		{name: "check with unknown flag", args: []string{"check", "-xml", "KATX"}, wantCode: 2, wantStderr: "flag provided but not defined"},
+		{name: "command help", args: []string{"once", "-h"}, wantStderr: "Usage: dras [-config FILE] once"},
+		{name: "test-notify in dry-run", configPath: valid, args: []string{"test-notify"}, wantCode: 1, wantStderr: "dry-run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runCommand(tt.configPath, tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d\nstderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
@@ -2,13 +2,20 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/jacaudi/dras/internal/message"
 	"github.com/jacaudi/dras/internal/notify"
 	"github.com/jacaudi/dras/internal/radar"
 	notify_lib "github.com/nikoksr/notify"
@@ -17,9 +24,13 @@ import (
 
 // Config holds all configuration for the DRAS application.
 type Config struct {
-	StationInput        string
-	PushoverAPIToken    string
-	PushoverUserKey     string
+	StationInput     string
+	PushoverAPIToken string
+	PushoverUserKey  string
+	// PushoverMessages are the Pushover priority, sound, URL and other
+	// options per kind of notification, keyed as in
+	// notify.PushoverMessageKeys.
+	PushoverMessages    map[string]notify.PushoverMessage
 	DryRun              bool
 	CheckInterval       time.Duration
 	LogLevel            string
@@ -29,144 +40,758 @@ type Config struct {
 	RadarImageRetention time.Duration
 	RendererURL         string
 	RendererTimeout     time.Duration
+	StateFile           string
+	// NWSBaseURL is the root of the NWS API that radar station data is
+	// fetched from; empty uses radar.DefaultBaseURL. It lets DRAS target a
+	// local stand-in of the API.
+	NWSBaseURL string
+	// NWSBulk fetches every station's data from the NWS API's station
+	// collection in one request per poll, instead of one request per
+	// station, when more than one station is due.
+	NWSBulk bool
+	// PollConcurrency bounds how many stations are polled at once; zero
+	// means DefaultPollConcurrency.
+	PollConcurrency int
+	// HTTPAddr is the listen address of the HTTP server that serves
+	// /metrics; empty disables the server.
+	HTTPAddr string
+	// AdminToken is the bearer token the admin API requires; empty
+	// disables the admin API.
+	AdminToken string
+
+	// DebouncePolls and DebounceDuration hold back a field change until it
+	// has been observed on at least DebouncePolls consecutive polls and for
+	// at least DebounceDuration, so a field that flaps and settles back is
+	// not reported. The defaults (1 poll, 0) report every change at once;
+	// zero polls means the same as one.
+	DebouncePolls    int
+	DebounceDuration time.Duration
+
+	// UnreachableAfter is how many fetches of a station's data must fail in
+	// a row before an outage notification is sent, and StaleAfter how old
+	// the NWS data may get before one is. Zero disables either check. A
+	// notification follows when the station reports again.
+	UnreachableAfter int
+	StaleAfter       time.Duration
+
+	// QuietHours are the daily windows during which changes are suppressed,
+	// queued or downgraded, for stations without quiet hours of their own.
+	QuietHours []QuietHours
+
+	// Digest schedules a daily summary of every station; nil disables it.
+	Digest *Digest
+
+	// BatchThreshold sends the notifications due for the same notifier in
+	// one poll round as a single one when there are at least this many;
+	// zero disables batching. BatchWindow bounds how long a notification
+	// waits for the rest of its round.
+	BatchThreshold int
+	BatchWindow    time.Duration
+
+	// RateLimit caps notifications at RateLimit per RateLimitPeriod across
+	// every notifier, refilled evenly over the period. Notifications over
+	// the limit are summarized in one once it allows another. Zero
+	// disables it.
+	RateLimit       int
+	RateLimitPeriod time.Duration
+
+	// ShutdownGracePeriod bounds how long in-flight polls and notifications
+	// may keep running after SIGTERM/SIGINT before they are cancelled.
+	ShutdownGracePeriod time.Duration
+	// ShutdownNotify sends a "DRAS Shutdown" notification on a graceful stop.
+	ShutdownNotify bool
+
+	// Notifiers are the notification backends in addition to Pushover, from
+	// the config file's notifiers list and the NTFY_*, GOTIFY_*, SLACK_*,
+	// DISCORD_* and WEBHOOK_* env vars.
+	Notifiers []notify.BackendConfig
+
+	// Templates are the notification title and body templates; empty
+	// fields use the built-in wording.
+	Templates message.Config
+
+	// ConfigFile is the YAML file the configuration was read from, if any.
+	ConfigFile string
+	// Stations holds the per-station entries declared in the config file.
+	Stations []StationOverride
+
+	// runtime is the station management done through the admin API; see
+	// WithRuntime.
+	runtime Runtime
+
+	// sources maps a config-file key path (e.g. "interval",
+	// "stations[0].interval") to the "file:line" it was declared on. Keys
+	// overridden by an env var are removed so errors name the env var.
+	sources map[string]string
+}
+
+// AlertOverride is a partial radar.AlertConfig: nil fields leave the
+// underlying toggle alone.
+type AlertOverride struct {
+	VCP         *bool `yaml:"vcp" json:"vcp,omitempty"`
+	Status      *bool `yaml:"status" json:"status,omitempty"`
+	Operability *bool `yaml:"operability" json:"operability,omitempty"`
+	PowerSource *bool `yaml:"power_source" json:"power_source,omitempty"`
+	GenState    *bool `yaml:"gen_state" json:"gen_state,omitempty"`
+}
+
+// Apply returns base with every toggle set in a applied on top.
+func (a AlertOverride) Apply(base radar.AlertConfig) radar.AlertConfig {
+	if a.VCP != nil {
+		base.VCP = *a.VCP
+	}
+	if a.Status != nil {
+		base.Status = *a.Status
+	}
+	if a.Operability != nil {
+		base.Operability = *a.Operability
+	}
+	if a.PowerSource != nil {
+		base.PowerSource = *a.PowerSource
+	}
+	if a.GenState != nil {
+		base.GenState = *a.GenState
+	}
+	return base
 }
 
-// Load loads configuration from environment variables with proper error handling.
+// StationOverride is a station entry from the config file. Zero / nil fields
+// inherit the global setting.
+type StationOverride struct {
+	ID              string
+	Alerts          AlertOverride
+	CheckInterval   time.Duration
+	ImageEnabled    *bool
+	PushoverUserKey string
+	// QuietHours replace the global quiet hours when non-nil; empty turns
+	// them off for the station.
+	QuietHours []QuietHours
+	// Source is the "file:line" the entry was declared on.
+	Source string
+}
+
+// StationConfig is the effective configuration for one station: the global
+// settings with the station's overrides, if any, applied.
+type StationConfig struct {
+	ID            string
+	AlertConfig   radar.AlertConfig
+	CheckInterval time.Duration
+	// ImageEnabled reports whether radar images may be attached for this
+	// station. A station can opt out of a configured image source but
+	// cannot enable one that is globally disabled.
+	ImageEnabled bool
+	// PushoverUserKey is the Pushover recipient for this station; empty
+	// means the global PushoverUserKey.
+	PushoverUserKey string
+	// QuietHours are the station's quiet-hour windows.
+	QuietHours []QuietHours
+	// Paused reports whether polling the station is paused at runtime.
+	Paused bool
+}
+
+// DefaultHTTPAddr is where the HTTP server listens unless HTTP_ADDR says
+// otherwise.
+const DefaultHTTPAddr = ":9090"
+
+// DefaultPollConcurrency is how many stations are polled at once unless
+// POLL_CONCURRENCY says otherwise.
+const DefaultPollConcurrency = 10
+
+// httpAddrOff is the HTTP_ADDR value that disables the HTTP server.
+const httpAddrOff = "off"
+
+// dryRunStations are the stations monitored in dry-run mode: Seattle, WA and
+// Raleigh, NC.
+var dryRunStations = []string{"KATX", "KRAX"}
+
+// Load loads configuration from the YAML file named by $DRAS_CONFIG, if set,
+// and from environment variables.
 func Load() (*Config, error) {
-	cfg := &Config{}
+	return LoadFile(os.Getenv("DRAS_CONFIG"))
+}
+
+// LoadFile loads configuration from the YAML file at path (skipped when path
+// is empty) and from environment variables. Precedence, lowest first:
+// built-in defaults, the config file, environment variables. An empty env
+// var counts as unset.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{
+		CheckInterval:       10 * time.Minute,
+		LogLevel:            "INFO",
+		AlertConfig:         radar.AlertConfig{VCP: true},
+		RadarImageEnabled:   true,
+		RadarImageRetention: time.Hour,
+		DebouncePolls:       1,
+		PollConcurrency:     DefaultPollConcurrency,
+		UnreachableAfter:    3,
+		StaleAfter:          time.Hour,
+		BatchWindow:         10 * time.Second,
+		RateLimitPeriod:     time.Hour,
+		HTTPAddr:            DefaultHTTPAddr,
+		// 60s default: a cold-start renderer (fresh pod, Py-ART + matplotlib
+		// font cache build on first import) plus a worst-case render of a
+		// busy station's Level II volume can hit ~30–40s. The previous 30s
+		// default tripped Client.Timeout on the first request after a pod
+		// restart. Issue #107 follow-up.
+		RendererTimeout: 60 * time.Second,
+		// Kubernetes sends SIGKILL 30s after SIGTERM by default; finishing
+		// a few seconds early leaves room to log and exit cleanly.
+		ShutdownGracePeriod: 25 * time.Second,
+	}
+
+	if path != "" {
+		if err := cfg.applyFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides cfg with every environment variable that is set.
+func (c *Config) applyEnv() error {
 	var err error
 
-	// Required fields (checked later in Validate if not dryrun)
-	cfg.StationInput = os.Getenv("STATION_IDS")
-	cfg.PushoverAPIToken = os.Getenv("PUSHOVER_API_TOKEN")
-	cfg.PushoverUserKey = os.Getenv("PUSHOVER_USER_KEY")
+	if v := os.Getenv("STATION_IDS"); v != "" {
+		c.StationInput = v
+	}
+	if v := os.Getenv("PUSHOVER_API_TOKEN"); v != "" {
+		c.PushoverAPIToken = v
+		c.clearSource("pushover.api_token")
+	}
+	if v := os.Getenv("PUSHOVER_USER_KEY"); v != "" {
+		c.PushoverUserKey = v
+		c.clearSource("pushover.user_key")
+	}
+
+	if v := os.Getenv("PUSHOVER_PRIORITY"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid PUSHOVER_PRIORITY value '%s': %w", v, err)
+		}
+		def := c.pushoverMessage(notify.PushoverDefault)
+		def.Priority = p
+		c.PushoverMessages[notify.PushoverDefault] = def
+		c.clearSource("pushover.messages.default")
+	}
+	if v := os.Getenv("PUSHOVER_SOUND"); v != "" {
+		def := c.pushoverMessage(notify.PushoverDefault)
+		def.Sound = v
+		c.PushoverMessages[notify.PushoverDefault] = def
+		c.clearSource("pushover.messages.default")
+	}
 
 	// Parse DryRun
 	if dryrunStr := os.Getenv("DRYRUN"); dryrunStr != "" {
-		cfg.DryRun, err = strconv.ParseBool(dryrunStr)
+		c.DryRun, err = strconv.ParseBool(dryrunStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid DRYRUN value '%s': %w", dryrunStr, err)
+			return fmt.Errorf("invalid DRYRUN value '%s': %w", dryrunStr, err)
 		}
+		c.clearSource("dry_run")
 	}
 
 	// Parse CheckInterval
-	intervalStr := os.Getenv("INTERVAL")
-	if intervalStr != "" {
+	if intervalStr := os.Getenv("INTERVAL"); intervalStr != "" {
 		intervalMin, err := strconv.ParseInt(intervalStr, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid INTERVAL value '%s': %w", intervalStr, err)
+			return fmt.Errorf("invalid INTERVAL value '%s': %w", intervalStr, err)
 		}
-		cfg.CheckInterval = time.Duration(intervalMin) * time.Minute
-	} else {
-		cfg.CheckInterval = 10 * time.Minute // Default
+		c.CheckInterval = time.Duration(intervalMin) * time.Minute
+		c.clearSource("interval")
 	}
 
 	// Parse LogLevel
-	cfg.LogLevel = getEnvDefault("LOG_LEVEL", "INFO")
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		c.LogLevel = v
+		c.clearSource("log_level")
+	}
 
 	// Parse alert configuration
-	cfg.AlertConfig.VCP, err = parseBoolEnv("ALERT_VCP", "true")
-	if err != nil {
-		return nil, err
+	for _, b := range []struct {
+		env    string
+		source string
+		dst    *bool
+	}{
+		{"ALERT_VCP", "alerts.vcp", &c.AlertConfig.VCP},
+		{"ALERT_STATUS", "alerts.status", &c.AlertConfig.Status},
+		{"ALERT_OPERABILITY", "alerts.operability", &c.AlertConfig.Operability},
+		{"ALERT_POWER_SOURCE", "alerts.power_source", &c.AlertConfig.PowerSource},
+		{"ALERT_GEN_STATE", "alerts.gen_state", &c.AlertConfig.GenState},
+		{"RADAR_IMAGE_ENABLED", "radar_image.enabled", &c.RadarImageEnabled},
+		{"SHUTDOWN_NOTIFY", "shutdown.notify", &c.ShutdownNotify},
+		{"NWS_BULK", "nws.bulk", &c.NWSBulk},
+	} {
+		if err := parseBoolEnv(b.env, b.dst); err != nil {
+			return err
+		}
+		if os.Getenv(b.env) != "" {
+			c.clearSource(b.source)
+		}
 	}
 
-	cfg.AlertConfig.Status, err = parseBoolEnv("ALERT_STATUS", "false")
-	if err != nil {
-		return nil, err
+	if v := os.Getenv("RADAR_IMAGE_URL_TEMPLATE"); v != "" {
+		c.RadarImageURLTmpl = v
+		c.clearSource("radar_image.url_template")
 	}
 
-	cfg.AlertConfig.Operability, err = parseBoolEnv("ALERT_OPERABILITY", "false")
-	if err != nil {
-		return nil, err
+	if retentionStr := os.Getenv("RADAR_IMAGE_RETENTION"); retentionStr != "" {
+		c.RadarImageRetention, err = time.ParseDuration(retentionStr)
+		if err != nil {
+			return fmt.Errorf("invalid RADAR_IMAGE_RETENTION value '%s': %w", retentionStr, err)
+		}
+		c.clearSource("radar_image.retention")
 	}
 
-	cfg.AlertConfig.PowerSource, err = parseBoolEnv("ALERT_POWER_SOURCE", "false")
-	if err != nil {
-		return nil, err
+	if v := strings.TrimSpace(os.Getenv("RENDERER_URL")); v != "" {
+		c.RendererURL = v
+		c.clearSource("renderer.url")
 	}
 
-	cfg.AlertConfig.GenState, err = parseBoolEnv("ALERT_GEN_STATE", "false")
-	if err != nil {
-		return nil, err
+	if v := strings.TrimSpace(os.Getenv("NWS_BASE_URL")); v != "" {
+		c.NWSBaseURL = v
+		c.clearSource("nws.base_url")
 	}
 
-	cfg.RadarImageEnabled, err = parseBoolEnv("RADAR_IMAGE_ENABLED", "true")
-	if err != nil {
-		return nil, err
+	if v := os.Getenv("RENDERER_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parse RENDERER_TIMEOUT %q: %w", v, err)
+		}
+		c.RendererTimeout = d
+		c.clearSource("renderer.timeout")
 	}
 
-	cfg.RadarImageURLTmpl = os.Getenv("RADAR_IMAGE_URL_TEMPLATE")
+	// Empty disables persistence: monitor state lives in memory only and
+	// every restart re-announces each station.
+	if v := strings.TrimSpace(os.Getenv("STATE_FILE")); v != "" {
+		c.StateFile = v
+		c.clearSource("state_file")
+	}
 
-	retentionStr := os.Getenv("RADAR_IMAGE_RETENTION")
-	if retentionStr == "" {
-		cfg.RadarImageRetention = time.Hour
-	} else {
-		cfg.RadarImageRetention, err = time.ParseDuration(retentionStr)
+	if v := strings.TrimSpace(os.Getenv("HTTP_ADDR")); v != "" {
+		c.HTTPAddr = v
+		if strings.EqualFold(v, httpAddrOff) {
+			c.HTTPAddr = ""
+		}
+		c.clearSource("http.addr")
+	}
+	if v := strings.TrimSpace(os.Getenv("ADMIN_TOKEN")); v != "" {
+		c.AdminToken = v
+		c.clearSource("http.admin_token")
+	}
+
+	if v := os.Getenv("DEBOUNCE_POLLS"); v != "" {
+		n, err := strconv.Atoi(v)
 		if err != nil {
-			return nil, fmt.Errorf("invalid RADAR_IMAGE_RETENTION value '%s': %w", retentionStr, err)
+			return fmt.Errorf("invalid DEBOUNCE_POLLS value '%s': %w", v, err)
 		}
+		c.DebouncePolls = n
+		c.clearSource("debounce.polls")
 	}
 
-	cfg.RendererURL = strings.TrimSpace(os.Getenv("RENDERER_URL"))
+	if v := os.Getenv("UNREACHABLE_AFTER"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid UNREACHABLE_AFTER value '%s': %w", v, err)
+		}
+		c.UnreachableAfter = n
+		c.clearSource("outage.unreachable_after")
+	}
 
-	// 60s default: a cold-start renderer (fresh pod, Py-ART + matplotlib
-	// font cache build on first import) plus a worst-case render of a
-	// busy station's Level II volume can hit ~30–40s. The previous 30s
-	// default tripped Client.Timeout on the first request after a pod
-	// restart. Issue #107 follow-up.
-	cfg.RendererTimeout = 60 * time.Second
-	if v := os.Getenv("RENDERER_TIMEOUT"); v != "" {
+	if v := os.Getenv("STALE_AFTER"); v != "" {
 		d, err := time.ParseDuration(v)
 		if err != nil {
-			return nil, fmt.Errorf("parse RENDERER_TIMEOUT %q: %w", v, err)
+			return fmt.Errorf("parse STALE_AFTER %q: %w", v, err)
 		}
-		cfg.RendererTimeout = d
+		c.StaleAfter = d
+		c.clearSource("outage.stale_after")
 	}
 
-	return cfg, nil
+	if v := os.Getenv("POLL_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid POLL_CONCURRENCY value '%s': %w", v, err)
+		}
+		c.PollConcurrency = n
+		c.clearSource("poll_concurrency")
+	}
+
+	if v := os.Getenv("DEBOUNCE_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parse DEBOUNCE_DURATION %q: %w", v, err)
+		}
+		c.DebounceDuration = d
+		c.clearSource("debounce.duration")
+	}
+
+	if v := os.Getenv("BATCH_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid BATCH_THRESHOLD value '%s': %w", v, err)
+		}
+		c.BatchThreshold = n
+		c.clearSource("batch.threshold")
+	}
+
+	if v := os.Getenv("BATCH_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parse BATCH_WINDOW %q: %w", v, err)
+		}
+		c.BatchWindow = d
+		c.clearSource("batch.window")
+	}
+
+	if v := os.Getenv("RATE_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid RATE_LIMIT value '%s': %w", v, err)
+		}
+		c.RateLimit = n
+		c.clearSource("rate_limit.count")
+	}
+
+	if v := os.Getenv("RATE_LIMIT_PERIOD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parse RATE_LIMIT_PERIOD %q: %w", v, err)
+		}
+		c.RateLimitPeriod = d
+		c.clearSource("rate_limit.period")
+	}
+
+	if err := c.applyQuietHoursEnv(); err != nil {
+		return err
+	}
+	if err := c.applyDigestEnv(); err != nil {
+		return err
+	}
+
+	c.applyNotifierEnv()
+
+	for _, t := range c.templateSettings() {
+		if v := os.Getenv(t.env); v != "" {
+			*t.dst = v
+			c.clearSource(t.source)
+		}
+	}
+
+	if v := os.Getenv("SHUTDOWN_GRACE_PERIOD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("parse SHUTDOWN_GRACE_PERIOD %q: %w", v, err)
+		}
+		c.ShutdownGracePeriod = d
+		c.clearSource("shutdown.grace_period")
+	}
+
+	return nil
+}
+
+// applyNotifierEnv adds a backend for each notifier env var group that is
+// set. An env backend replaces a config-file backend of the same type that
+// has no name of its own (or is named after its type), and is otherwise
+// appended.
+func (c *Config) applyNotifierEnv() {
+	var fromEnv []notify.BackendConfig
+	if topic := os.Getenv("NTFY_TOPIC"); topic != "" {
+		fromEnv = append(fromEnv, notify.BackendConfig{
+			Type:  "ntfy",
+			URL:   strings.TrimSpace(os.Getenv("NTFY_URL")),
+			Topic: topic,
+			Token: os.Getenv("NTFY_TOKEN"),
+		})
+	}
+	if server := strings.TrimSpace(os.Getenv("GOTIFY_URL")); server != "" {
+		fromEnv = append(fromEnv, notify.BackendConfig{
+			Type:  "gotify",
+			URL:   server,
+			Token: os.Getenv("GOTIFY_TOKEN"),
+		})
+	}
+	for _, hook := range []struct{ env, backendType string }{
+		{"SLACK_WEBHOOK_URL", "slack"},
+		{"DISCORD_WEBHOOK_URL", "discord"},
+	} {
+		if u := strings.TrimSpace(os.Getenv(hook.env)); u != "" {
+			fromEnv = append(fromEnv, notify.BackendConfig{Type: hook.backendType, URL: u})
+		}
+	}
+	if u := strings.TrimSpace(os.Getenv("WEBHOOK_URL")); u != "" {
+		fromEnv = append(fromEnv, notify.BackendConfig{
+			Type:      "webhook",
+			URL:       u,
+			Secret:    os.Getenv("WEBHOOK_SECRET"),
+			ImageMode: os.Getenv("WEBHOOK_IMAGE"),
+		})
+	}
+
+	for _, b := range fromEnv {
+		replaced := false
+		for i, existing := range c.Notifiers {
+			if strings.EqualFold(existing.Type, b.Type) && existing.DisplayName() == existing.Type {
+				c.Notifiers[i] = b
+				c.clearSource(fmt.Sprintf("notifiers[%d]", i))
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			c.Notifiers = append(c.Notifiers, b)
+		}
+	}
+}
+
+// templateSetting ties a notification template to its env var and
+// config-file key.
+type templateSetting struct {
+	env    string
+	source string
+	dst    *string
+	kind   message.Kind
+}
+
+func (c *Config) templateSettings() []templateSetting {
+	return []templateSetting{
+		{"TEMPLATE_STARTUP_TITLE", "templates.startup.title", &c.Templates.Startup.Title, message.Startup},
+		{"TEMPLATE_STARTUP_BODY", "templates.startup.body", &c.Templates.Startup.Body, message.Startup},
+		{"TEMPLATE_CHANGE_TITLE", "templates.change.title", &c.Templates.Change.Title, message.Change},
+		{"TEMPLATE_CHANGE_BODY", "templates.change.body", &c.Templates.Change.Body, message.Change},
+		{"TEMPLATE_RECOVERY_TITLE", "templates.recovery.title", &c.Templates.Recovery.Title, message.Recovery},
+		{"TEMPLATE_RECOVERY_BODY", "templates.recovery.body", &c.Templates.Recovery.Body, message.Recovery},
+		{"TEMPLATE_OUTAGE_TITLE", "templates.outage.title", &c.Templates.Outage.Title, message.Outage},
+		{"TEMPLATE_OUTAGE_BODY", "templates.outage.body", &c.Templates.Outage.Body, message.Outage},
+		{"TEMPLATE_RESUMED_TITLE", "templates.resumed.title", &c.Templates.Resumed.Title, message.Resumed},
+		{"TEMPLATE_RESUMED_BODY", "templates.resumed.body", &c.Templates.Resumed.Body, message.Resumed},
+		{"TEMPLATE_DIGEST_TITLE", "templates.digest.title", &c.Templates.Digest.Title, message.Digest},
+		{"TEMPLATE_DIGEST_BODY", "templates.digest.body", &c.Templates.Digest.Body, message.Digest},
+		{"TEMPLATE_BATCH_TITLE", "templates.batch.title", &c.Templates.Batch.Title, message.Batch},
+		{"TEMPLATE_BATCH_BODY", "templates.batch.body", &c.Templates.Batch.Body, message.Batch},
+	}
+}
+
+// pushoverMessage returns the Pushover message options for key, creating
+// the map if needed.
+func (c *Config) pushoverMessage(key string) notify.PushoverMessage {
+	if c.PushoverMessages == nil {
+		c.PushoverMessages = make(map[string]notify.PushoverMessage)
+	}
+	return c.PushoverMessages[key]
+}
+
+// NotifierBackends returns every configured notification backend: Pushover
+// (named "pushover") when its credentials are set, followed by Notifiers.
+// With no other backends configured Pushover is always included, since it
+// is then required.
+func (c *Config) NotifierBackends() []notify.BackendConfig {
+	var backends []notify.BackendConfig
+	if c.pushoverEnabled() {
+		backends = append(backends, notify.BackendConfig{
+			Type:    "pushover",
+			Name:    "pushover",
+			Token:   c.PushoverAPIToken,
+			UserKey: c.PushoverUserKey,
+
+			PushoverMessages: c.PushoverMessages,
+		})
+	}
+	return append(backends, c.Notifiers...)
+}
+
+// pushoverEnabled reports whether the global Pushover backend is in use: it
+// is the default, and optional once another backend is configured.
+func (c *Config) pushoverEnabled() bool {
+	return len(c.Notifiers) == 0 || c.PushoverAPIToken != "" || c.PushoverUserKey != ""
+}
+
+// StationIDs returns the stations to monitor. In dry-run mode these are the
+// fixed test stations. Otherwise STATION_IDS, when set, wins over the config
+// file's station list; per-station file settings still apply to any ID that
+// appears in both. Stations added or removed at runtime (see WithRuntime)
+// are applied last.
+func (c *Config) StationIDs() []string {
+	ids := c.configuredStationIDs()
+	if c.runtime.IsZero() {
+		return ids
+	}
+	ids = slices.DeleteFunc(ids, func(id string) bool {
+		return slices.Contains(c.runtime.Removed, id)
+	})
+	for _, id := range c.runtime.Added {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Station returns the effective configuration for the station.
+func (c *Config) Station(id string) StationConfig {
+	sc := StationConfig{
+		ID:            id,
+		AlertConfig:   c.AlertConfig,
+		CheckInterval: c.CheckInterval,
+		ImageEnabled:  true,
+		QuietHours:    c.QuietHours,
+	}
+	for _, st := range c.Stations {
+		if st.ID != id {
+			continue
+		}
+		sc.AlertConfig = st.Alerts.Apply(sc.AlertConfig)
+		if st.CheckInterval > 0 {
+			sc.CheckInterval = st.CheckInterval
+		}
+		if st.ImageEnabled != nil {
+			sc.ImageEnabled = *st.ImageEnabled
+		}
+		sc.PushoverUserKey = st.PushoverUserKey
+		if st.QuietHours != nil {
+			sc.QuietHours = st.QuietHours
+		}
+		break
+	}
+	if a, ok := c.runtime.Alerts[id]; ok {
+		sc.AlertConfig = a.Apply(sc.AlertConfig)
+	}
+	sc.Paused = slices.Contains(c.runtime.Paused, id)
+	return sc
+}
+
+// PollInterval returns the shortest check interval across the monitored
+// stations, which is how often the monitor needs to wake up.
+func (c *Config) PollInterval() time.Duration {
+	interval := c.CheckInterval
+	for _, id := range c.StationIDs() {
+		if d := c.Station(id).CheckInterval; d > 0 && (interval <= 0 || d < interval) {
+			interval = d
+		}
+	}
+	return interval
 }
 
 // Validate checks if the required environment variables are set and validates their format.
 // If any of the required variables are missing or invalid, it returns an error.
+// Values that came from the config file are reported with their file:line.
 func (c *Config) Validate() error {
 	var errors []string
 
 	if !c.DryRun {
 		// Check required fields
-		if c.StationInput == "" {
-			errors = append(errors, "STATION_IDS is required")
-		} else if err := validateStationIDs(c.StationInput); err != nil {
-			errors = append(errors, fmt.Sprintf("STATION_IDS validation failed: %v", err))
+		switch {
+		case c.StationInput != "":
+			if err := validateStationIDs(c.StationInput); err != nil {
+				errors = append(errors, fmt.Sprintf("STATION_IDS validation failed: %v", err))
+			}
+		case len(c.Stations) == 0:
+			errors = append(errors, "STATION_IDS (or stations in the config file) is required")
 		}
 
-		if c.PushoverAPIToken == "" {
-			errors = append(errors, "PUSHOVER_API_TOKEN is required")
-		} else if err := notify.ValidateAPIToken(c.PushoverAPIToken); err != nil {
-			errors = append(errors, fmt.Sprintf("PUSHOVER_API_TOKEN validation failed: %v", err))
-		}
+		// Pushover is required unless another backend is configured.
+		if c.pushoverEnabled() {
+			if c.PushoverAPIToken == "" {
+				errors = append(errors, "PUSHOVER_API_TOKEN is required")
+			} else if err := notify.ValidateAPIToken(c.PushoverAPIToken); err != nil {
+				errors = append(errors, fmt.Sprintf("%s validation failed: %v", c.label("pushover.api_token", "PUSHOVER_API_TOKEN"), err))
+			}
+
+			if c.PushoverUserKey == "" {
+				errors = append(errors, "PUSHOVER_USER_KEY is required")
+			} else if err := notify.ValidateUserKey(c.PushoverUserKey); err != nil {
+				errors = append(errors, fmt.Sprintf("%s validation failed: %v", c.label("pushover.user_key", "PUSHOVER_USER_KEY"), err))
+			}
 
-		if c.PushoverUserKey == "" {
-			errors = append(errors, "PUSHOVER_USER_KEY is required")
-		} else if err := notify.ValidateUserKey(c.PushoverUserKey); err != nil {
-			errors = append(errors, fmt.Sprintf("PUSHOVER_USER_KEY validation failed: %v", err))
+			errors = append(errors, c.validatePushoverMessages()...)
 		}
+
+		errors = append(errors, c.validateNotifiers()...)
 	}
 
 	// Validate optional fields
 	if c.CheckInterval < time.Minute {
-		errors = append(errors, "INTERVAL must be at least 1 minute")
+		errors = append(errors, fmt.Sprintf("%s must be at least 1 minute", c.label("interval", "INTERVAL")))
 	}
 
 	if c.LogLevel != "" {
 		if err := validateLogLevel(c.LogLevel); err != nil {
-			errors = append(errors, fmt.Sprintf("LOG_LEVEL validation failed: %v", err))
+			errors = append(errors, fmt.Sprintf("%s validation failed: %v", c.label("log_level", "LOG_LEVEL"), err))
 		}
 	}
 
 	if c.RadarImageEnabled && c.RadarImageRetention <= 0 {
-		errors = append(errors, "RADAR_IMAGE_RETENTION must be positive (e.g. 1h, 30m)")
+		errors = append(errors, fmt.Sprintf("%s must be positive (e.g. 1h, 30m)", c.label("radar_image.retention", "RADAR_IMAGE_RETENTION")))
 	}
 
+	if c.UnreachableAfter < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("outage.unreachable_after", "UNREACHABLE_AFTER")))
+	}
+	if c.StaleAfter < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("outage.stale_after", "STALE_AFTER")))
+	}
+
+	errors = append(errors, c.validateQuietHours("quiet_hours", c.QuietHours)...)
+
+	switch {
+	case c.BatchThreshold < 0:
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("batch.threshold", "BATCH_THRESHOLD")))
+	case c.BatchThreshold == 1:
+		errors = append(errors, fmt.Sprintf("%s must be at least 2, or 0 to disable batching", c.label("batch.threshold", "BATCH_THRESHOLD")))
+	case c.BatchThreshold > 0 && c.BatchWindow <= 0:
+		errors = append(errors, fmt.Sprintf("%s must be positive (e.g. 10s)", c.label("batch.window", "BATCH_WINDOW")))
+	}
+	switch {
+	case c.RateLimit < 0:
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("rate_limit.count", "RATE_LIMIT")))
+	case c.RateLimit > 0 && c.RateLimitPeriod <= 0:
+		errors = append(errors, fmt.Sprintf("%s must be positive (e.g. 1h)", c.label("rate_limit.period", "RATE_LIMIT_PERIOD")))
+	}
+
+	if c.PollConcurrency < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("poll_concurrency", "POLL_CONCURRENCY")))
+	}
+
+	if c.DebouncePolls < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("debounce.polls", "DEBOUNCE_POLLS")))
+	}
+	if c.DebounceDuration < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("debounce.duration", "DEBOUNCE_DURATION")))
+	}
+
+	if c.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(c.HTTPAddr); err != nil {
+			errors = append(errors, fmt.Sprintf("%s must be host:port, :port or \"off\": %v", c.label("http.addr", "HTTP_ADDR"), err))
+		}
+	}
+	if c.AdminToken != "" {
+		switch {
+		case c.HTTPAddr == "":
+			errors = append(errors, fmt.Sprintf("%s requires the HTTP server, but HTTP_ADDR is off", c.label("http.admin_token", "ADMIN_TOKEN")))
+		case strings.ContainsFunc(c.AdminToken, unicode.IsSpace):
+			errors = append(errors, fmt.Sprintf("%s cannot contain whitespace", c.label("http.admin_token", "ADMIN_TOKEN")))
+		}
+	}
+
+	if c.NWSBaseURL != "" {
+		if u, err := url.Parse(c.NWSBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errors = append(errors, fmt.Sprintf("%s must be an http(s) URL, got %q", c.label("nws.base_url", "NWS_BASE_URL"), c.NWSBaseURL))
+		}
+	}
+
+	if c.ShutdownGracePeriod < 0 {
+		errors = append(errors, fmt.Sprintf("%s cannot be negative", c.label("shutdown.grace_period", "SHUTDOWN_GRACE_PERIOD")))
+	}
+
+	if _, err := message.Parse(c.Templates); err != nil {
+		errors = append(errors, c.templateError(err))
+	}
+
+	errors = append(errors, c.validateStations()...)
+
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration validation failed:\n  - %s", strings.Join(errors, "\n  - "))
 	}
@@ -174,6 +799,134 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateStations checks the config file's station entries, prefixing each
+// error with the file:line of the offending entry.
+func (c *Config) validateStations() []string {
+	var errors []string
+	seen := make(map[string]string, len(c.Stations))
+	for i, st := range c.Stations {
+		at := func(key string) string {
+			if src := c.sources[fmt.Sprintf("stations[%d].%s", i, key)]; src != "" {
+				return src + ": "
+			}
+			if st.Source != "" {
+				return st.Source + ": "
+			}
+			return ""
+		}
+
+		switch {
+		case st.ID == "":
+			errors = append(errors, fmt.Sprintf("%sstations[%d]: id is required", at("id"), i))
+			continue
+		case !radar.ValidateStationID(st.ID):
+			errors = append(errors, fmt.Sprintf("%sstation %q: invalid radar station ID (must be a 4-letter code)", at("id"), st.ID))
+		}
+		if first, dup := seen[st.ID]; dup {
+			errors = append(errors, fmt.Sprintf("%sstation %q is declared more than once (first at %s)", at("id"), st.ID, first))
+		} else {
+			seen[st.ID] = st.Source
+		}
+
+		if st.CheckInterval != 0 && st.CheckInterval < time.Minute {
+			errors = append(errors, fmt.Sprintf("%sstation %q: interval must be at least 1 minute", at("interval"), st.ID))
+		}
+		errors = append(errors, c.validateQuietHours(fmt.Sprintf("stations[%d].quiet_hours", i), st.QuietHours)...)
+		if st.PushoverUserKey != "" {
+			if err := notify.ValidateUserKey(st.PushoverUserKey); err != nil {
+				errors = append(errors, fmt.Sprintf("%sstation %q: pushover.user_key validation failed: %v", at("pushover.user_key"), st.ID, err))
+			}
+			// The station's recipient is sent to with the global API token,
+			// which Validate only checks while global Pushover is in use.
+			if !c.DryRun && !c.pushoverEnabled() {
+				errors = append(errors, fmt.Sprintf("%sstation %q: pushover.user_key needs PUSHOVER_API_TOKEN (or pushover.api_token)", at("pushover.user_key"), st.ID))
+			}
+		}
+	}
+	return errors
+}
+
+// validatePushoverMessages checks each entry of PushoverMessages, naming
+// its file:line when it came from the config file.
+func (c *Config) validatePushoverMessages() []string {
+	keys := make([]string, 0, len(c.PushoverMessages))
+	for k := range c.PushoverMessages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errors []string
+	for _, k := range keys {
+		err := notify.ValidatePushoverMessages(map[string]notify.PushoverMessage{k: c.PushoverMessages[k]})
+		if err == nil {
+			continue
+		}
+		if src := c.sources["pushover.messages."+k]; src != "" {
+			errors = append(errors, fmt.Sprintf("%s: pushover.messages.%v", src, err))
+		} else {
+			errors = append(errors, fmt.Sprintf("PUSHOVER_PRIORITY/PUSHOVER_SOUND: %v", err))
+		}
+	}
+	return errors
+}
+
+// validateNotifiers checks each backend in Notifiers by building it, and
+// that backend names are unique.
+func (c *Config) validateNotifiers() []string {
+	var errors []string
+	seen := make(map[string]bool, len(c.Notifiers)+1)
+	if c.pushoverEnabled() {
+		seen["pushover"] = true
+	}
+	for i, b := range c.Notifiers {
+		at := ""
+		if src := c.sources[fmt.Sprintf("notifiers[%d]", i)]; src != "" {
+			at = src + ": "
+		}
+		if b.Type == "" {
+			errors = append(errors, fmt.Sprintf("%snotifiers[%d]: type is required (one of %s)", at, i, strings.Join(notify.Types(), ", ")))
+			continue
+		}
+		name := b.DisplayName()
+		if seen[name] {
+			errors = append(errors, fmt.Sprintf("%snotifier %q is declared more than once; give each a distinct name", at, name))
+		}
+		seen[name] = true
+		if _, err := notify.Build(b); err != nil {
+			errors = append(errors, at+err.Error())
+		}
+	}
+	return errors
+}
+
+// templateError describes a message.Parse error, naming the env var or
+// file:line of the template at fault.
+func (c *Config) templateError(err error) string {
+	var te *message.Error
+	if errors.As(err, &te) {
+		for _, t := range c.templateSettings() {
+			if t.kind == te.Kind && strings.HasSuffix(t.source, "."+te.Part) {
+				return fmt.Sprintf("%s: %v", c.label(t.source, t.env), te.Err)
+			}
+		}
+	}
+	return err.Error()
+}
+
+// label names a setting in error messages: "file:line: key" when the value
+// came from the config file, otherwise the env var name.
+func (c *Config) label(key, env string) string {
+	if src, ok := c.sources[key]; ok {
+		return fmt.Sprintf("%s: %s", src, key)
+	}
+	return env
+}
+
+// clearSource forgets the file location of key after an env var overrode it.
+func (c *Config) clearSource(key string) {
+	delete(c.sources, key)
+}
+
 // ValidateConnectivity tests Pushover connectivity if not in dry run mode
 func (c *Config) ValidateConnectivity(ctx context.Context) error {
 	if c.DryRun {
@@ -246,6 +999,9 @@ func validateLogLevel(level string) error {
 func (c *Config) String() string {
 	var parts []string
 
+	if c.ConfigFile != "" {
+		parts = append(parts, fmt.Sprintf("Config File: %s", c.ConfigFile))
+	}
 	parts = append(parts, fmt.Sprintf("Dry Run: %t", c.DryRun))
 	parts = append(parts, fmt.Sprintf("Check Interval: %v", c.CheckInterval))
 	parts = append(parts, fmt.Sprintf("Log Level: %s", c.LogLevel))
@@ -255,7 +1011,7 @@ func (c *Config) String() string {
 		maskedToken := maskString(c.PushoverAPIToken, 6)
 		maskedUserKey := maskString(c.PushoverUserKey, 6)
 
-		parts = append(parts, fmt.Sprintf("Station IDs: %s", c.StationInput))
+		parts = append(parts, fmt.Sprintf("Station IDs: %s", strings.Join(c.StationIDs(), ",")))
 		parts = append(parts, fmt.Sprintf("Pushover Token: %s", maskedToken))
 		parts = append(parts, fmt.Sprintf("Pushover User Key: %s", maskedUserKey))
 	} else {
@@ -287,6 +1043,69 @@ func (c *Config) String() string {
 		parts = append(parts, "Alert Types: none")
 	}
 
+	if c.DebouncePolls > 1 || c.DebounceDuration > 0 {
+		parts = append(parts, fmt.Sprintf("Debounce: %d polls, %v", c.DebouncePolls, c.DebounceDuration))
+	}
+
+	var outage []string
+	if c.UnreachableAfter > 0 {
+		outage = append(outage, fmt.Sprintf("unreachable after %d failed polls", c.UnreachableAfter))
+	}
+	if c.StaleAfter > 0 {
+		outage = append(outage, fmt.Sprintf("stale after %v", c.StaleAfter))
+	}
+	if len(outage) > 0 {
+		parts = append(parts, fmt.Sprintf("Outage Alerts: %s", strings.Join(outage, ", ")))
+	} else {
+		parts = append(parts, "Outage Alerts: disabled")
+	}
+
+	for _, q := range c.QuietHours {
+		parts = append(parts, fmt.Sprintf("Quiet Hours: %s", q))
+	}
+	if c.Digest != nil {
+		parts = append(parts, fmt.Sprintf("Digest: daily at %s", c.Digest))
+	}
+	if c.BatchThreshold > 0 {
+		parts = append(parts, fmt.Sprintf("Batching: %d or more notifications per poll round, waiting up to %v", c.BatchThreshold, c.BatchWindow))
+	}
+	if c.RateLimit > 0 {
+		parts = append(parts, fmt.Sprintf("Rate Limit: %d notifications per %v", c.RateLimit, c.RateLimitPeriod))
+	}
+
+	if c.HTTPAddr != "" {
+		parts = append(parts, fmt.Sprintf("HTTP Server: %s", c.HTTPAddr))
+		if c.AdminToken != "" {
+			parts = append(parts, "Admin API: enabled")
+		} else {
+			parts = append(parts, "Admin API: disabled")
+		}
+	} else {
+		parts = append(parts, "HTTP Server: disabled")
+	}
+
+	if c.NWSBaseURL != "" {
+		parts = append(parts, fmt.Sprintf("NWS API: %s", c.NWSBaseURL))
+	}
+	if c.NWSBulk {
+		parts = append(parts, "NWS Bulk Fetch: enabled")
+	}
+	if c.PollConcurrency > 0 {
+		parts = append(parts, fmt.Sprintf("Poll Concurrency: %d", c.PollConcurrency))
+	}
+
+	if len(c.Notifiers) > 0 {
+		names := make([]string, 0, len(c.Notifiers))
+		for _, b := range c.Notifiers {
+			names = append(names, b.DisplayName())
+		}
+		parts = append(parts, fmt.Sprintf("Additional Notifiers: %s", strings.Join(names, ", ")))
+	}
+
+	if len(c.Stations) > 0 {
+		parts = append(parts, fmt.Sprintf("Per-station Overrides: %d", len(c.Stations)))
+	}
+
 	return strings.Join(parts, "\n")
 }
 
@@ -298,20 +1117,17 @@ func maskString(s string, show int) string {
 	return s[:show] + strings.Repeat("*", len(s)-show)
 }
 
-// getEnvDefault returns the value of the environment variable if set, otherwise returns the default value.
-func getEnvDefault(key, defaultVal string) string {
+// parseBoolEnv parses a boolean environment variable into dst, leaving dst
+// untouched when the variable is unset.
+func parseBoolEnv(key string, dst *bool) error {
 	val := os.Getenv(key)
 	if val == "" {
-		return defaultVal
+		return nil
 	}
-	return val
-}
-
-// parseBoolEnv parses a boolean environment variable with error handling
-func parseBoolEnv(key, defaultVal string) (bool, error) {
-	val, err := strconv.ParseBool(getEnvDefault(key, defaultVal))
+	parsed, err := strconv.ParseBool(val)
 	if err != nil {
-		return false, fmt.Errorf("invalid %s value: %w", key, err)
+		return fmt.Errorf("invalid %s value: %w", key, err)
 	}
-	return val, nil
+	*dst = parsed
+	return nil
 }
@@ -27,6 +27,55 @@ func TestLoad(t *testing.T) {
 		"RADAR_IMAGE_RETENTION",
 		"RENDERER_URL",
 		"RENDERER_TIMEOUT",
+		"NWS_BASE_URL",
+		"NWS_BULK",
+		"POLL_CONCURRENCY",
+		"UNREACHABLE_AFTER",
+		"STALE_AFTER",
+		"QUIET_HOURS",
+		"QUIET_HOURS_TIMEZONE",
+		"QUIET_HOURS_FIELDS",
+		"QUIET_HOURS_ACTION",
+		"DIGEST_AT",
+		"DIGEST_TIMEZONE",
+		"DIGEST_IMAGE",
+		"TEMPLATE_DIGEST_TITLE",
+		"TEMPLATE_DIGEST_BODY",
+		"BATCH_THRESHOLD",
+		"BATCH_WINDOW",
+		"RATE_LIMIT",
+		"RATE_LIMIT_PERIOD",
+		"TEMPLATE_BATCH_TITLE",
+		"TEMPLATE_BATCH_BODY",
+		"TEMPLATE_OUTAGE_TITLE",
+		"TEMPLATE_OUTAGE_BODY",
+		"TEMPLATE_RESUMED_TITLE",
+		"TEMPLATE_RESUMED_BODY",
+		"STATE_FILE",
+		"SHUTDOWN_GRACE_PERIOD",
+		"SHUTDOWN_NOTIFY",
+		"NTFY_URL",
+		"NTFY_TOPIC",
+		"NTFY_TOKEN",
+		"GOTIFY_URL",
+		"GOTIFY_TOKEN",
+		"SLACK_WEBHOOK_URL",
+		"DISCORD_WEBHOOK_URL",
+		"WEBHOOK_URL",
+		"WEBHOOK_SECRET",
+		"WEBHOOK_IMAGE",
+		"TEMPLATE_STARTUP_TITLE",
+		"TEMPLATE_STARTUP_BODY",
+		"TEMPLATE_CHANGE_TITLE",
+		"TEMPLATE_CHANGE_BODY",
+		"TEMPLATE_RECOVERY_TITLE",
+		"TEMPLATE_RECOVERY_BODY",
+		"DEBOUNCE_POLLS",
+		"DEBOUNCE_DURATION",
+		"PUSHOVER_PRIORITY",
+		"PUSHOVER_SOUND",
+		"HTTP_ADDR",
+		"ADMIN_TOKEN",
 	}
 
 	clearEnv := func(t *testing.T) {
@@ -291,6 +340,129 @@ func TestRendererTimeoutOverridable(t *testing.T) {
 	}
 }
 
+func TestNWSBaseURL(t *testing.T) {
+	t.Setenv("PUSHOVER_API_TOKEN", "")
+	t.Setenv("PUSHOVER_USER_KEY", "")
+	t.Setenv("STATION_IDS", "")
+	t.Setenv("DRYRUN", "true")
+
+	t.Setenv("NWS_BASE_URL", " http://127.0.0.1:8081 ")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.NWSBaseURL != "http://127.0.0.1:8081" {
+		t.Errorf("NWSBaseURL = %q", cfg.NWSBaseURL)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+
+	for _, bad := range []string{"api.weather.gov", "ftp://api.weather.gov", "http://"} {
+		t.Setenv("NWS_BASE_URL", bad)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "NWS_BASE_URL") {
+			t.Errorf("Validate(%q) error = %v, want an NWS_BASE_URL error", bad, err)
+		}
+	}
+}
+
+func TestPollConcurrency(t *testing.T) {
+	t.Setenv("PUSHOVER_API_TOKEN", "")
+	t.Setenv("PUSHOVER_USER_KEY", "")
+	t.Setenv("STATION_IDS", "")
+	t.Setenv("DRYRUN", "true")
+
+	t.Setenv("POLL_CONCURRENCY", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.PollConcurrency != DefaultPollConcurrency {
+		t.Errorf("PollConcurrency = %d, want %d", cfg.PollConcurrency, DefaultPollConcurrency)
+	}
+
+	t.Setenv("POLL_CONCURRENCY", "4")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.PollConcurrency != 4 {
+		t.Errorf("PollConcurrency = %d, want 4", cfg.PollConcurrency)
+	}
+
+	t.Setenv("POLL_CONCURRENCY", "-1")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "POLL_CONCURRENCY cannot be negative") {
+		t.Errorf("Validate() error = %v, want a POLL_CONCURRENCY error", err)
+	}
+
+	t.Setenv("POLL_CONCURRENCY", "many")
+	if _, err := Load(); err == nil {
+		t.Error("Load() with POLL_CONCURRENCY=many succeeded")
+	}
+}
+
+func TestStateFileLoadedFromEnv(t *testing.T) {
+	t.Setenv("STATE_FILE", " /var/lib/dras/state.json ")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.StateFile != "/var/lib/dras/state.json" {
+		t.Errorf("StateFile = %q", cfg.StateFile)
+	}
+}
+
+func TestShutdownSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("SHUTDOWN_GRACE_PERIOD", "")
+		t.Setenv("SHUTDOWN_NOTIFY", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.ShutdownGracePeriod != 25*time.Second {
+			t.Errorf("ShutdownGracePeriod = %v, want 25s", cfg.ShutdownGracePeriod)
+		}
+		if cfg.ShutdownNotify {
+			t.Error("ShutdownNotify = true, want false by default")
+		}
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv("SHUTDOWN_GRACE_PERIOD", "10s")
+		t.Setenv("SHUTDOWN_NOTIFY", "true")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.ShutdownGracePeriod != 10*time.Second || !cfg.ShutdownNotify {
+			t.Errorf("ShutdownGracePeriod = %v, ShutdownNotify = %t", cfg.ShutdownGracePeriod, cfg.ShutdownNotify)
+		}
+	})
+
+	t.Run("invalid grace period", func(t *testing.T) {
+		t.Setenv("SHUTDOWN_GRACE_PERIOD", "soon")
+		if _, err := Load(); err == nil {
+			t.Error("Load() error = nil, want parse error")
+		}
+	})
+
+	t.Run("negative grace period fails validation", func(t *testing.T) {
+		cfg := &Config{DryRun: true, CheckInterval: time.Minute, ShutdownGracePeriod: -time.Second}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "SHUTDOWN_GRACE_PERIOD cannot be negative") {
+			t.Errorf("Validate() error = %v, want SHUTDOWN_GRACE_PERIOD error", err)
+		}
+	})
+}
+
 func TestConfig_String(t *testing.T) {
 	t.Run("dry run mode", func(t *testing.T) {
 		cfg := &Config{
@@ -359,3 +531,232 @@ func TestConfig_String(t *testing.T) {
 		}
 	})
 }
+
+func TestDebounceSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("DEBOUNCE_POLLS", "")
+		t.Setenv("DEBOUNCE_DURATION", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.DebouncePolls != 1 || cfg.DebounceDuration != 0 {
+			t.Errorf("DebouncePolls = %d, DebounceDuration = %v, want 1, 0", cfg.DebouncePolls, cfg.DebounceDuration)
+		}
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv("DEBOUNCE_POLLS", "3")
+		t.Setenv("DEBOUNCE_DURATION", "15m")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.DebouncePolls != 3 || cfg.DebounceDuration != 15*time.Minute {
+			t.Errorf("DebouncePolls = %d, DebounceDuration = %v, want 3, 15m", cfg.DebouncePolls, cfg.DebounceDuration)
+		}
+	})
+
+	t.Run("invalid polls", func(t *testing.T) {
+		t.Setenv("DEBOUNCE_POLLS", "few")
+		if _, err := Load(); err == nil {
+			t.Error("Load() error = nil, want parse error")
+		}
+	})
+
+	t.Run("negative values fail validation", func(t *testing.T) {
+		cfg := &Config{DryRun: true, CheckInterval: time.Minute, DebouncePolls: -1, DebounceDuration: -time.Minute}
+		err := cfg.Validate()
+		for _, want := range []string{"DEBOUNCE_POLLS cannot be negative", "DEBOUNCE_DURATION cannot be negative"} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error = %v, want %q", err, want)
+			}
+		}
+	})
+}
+
+func TestOutageSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("UNREACHABLE_AFTER", "")
+		t.Setenv("STALE_AFTER", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.UnreachableAfter != 3 || cfg.StaleAfter != time.Hour {
+			t.Errorf("UnreachableAfter = %d, StaleAfter = %v, want 3, 1h", cfg.UnreachableAfter, cfg.StaleAfter)
+		}
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv("UNREACHABLE_AFTER", "0")
+		t.Setenv("STALE_AFTER", "30m")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.UnreachableAfter != 0 || cfg.StaleAfter != 30*time.Minute {
+			t.Errorf("UnreachableAfter = %d, StaleAfter = %v, want 0, 30m", cfg.UnreachableAfter, cfg.StaleAfter)
+		}
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		t.Setenv("STALE_AFTER", "soon")
+		if _, err := Load(); err == nil {
+			t.Error("Load() error = nil, want parse error")
+		}
+	})
+
+	t.Run("negative values fail validation", func(t *testing.T) {
+		cfg := &Config{DryRun: true, CheckInterval: time.Minute, UnreachableAfter: -1, StaleAfter: -time.Minute}
+		err := cfg.Validate()
+		for _, want := range []string{"UNREACHABLE_AFTER cannot be negative", "STALE_AFTER cannot be negative"} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error = %v, want %q", err, want)
+			}
+		}
+	})
+}
+
+func TestBatchAndRateLimitSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearConfigEnv(t)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.BatchThreshold != 0 || cfg.BatchWindow != 10*time.Second || cfg.RateLimit != 0 || cfg.RateLimitPeriod != time.Hour {
+			t.Errorf("batch %d/%v, rate limit %d/%v; want off, 10s, off, 1h", cfg.BatchThreshold, cfg.BatchWindow, cfg.RateLimit, cfg.RateLimitPeriod)
+		}
+	})
+
+	t.Run("file and env", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, `dry_run: true
+batch:
+  threshold: 3
+  window: 30s
+rate_limit:
+  count: 20
+  period: 2h
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if cfg.BatchThreshold != 3 || cfg.BatchWindow != 30*time.Second || cfg.RateLimit != 20 || cfg.RateLimitPeriod != 2*time.Hour {
+			t.Errorf("batch %d/%v, rate limit %d/%v; want the file's", cfg.BatchThreshold, cfg.BatchWindow, cfg.RateLimit, cfg.RateLimitPeriod)
+		}
+		s := cfg.String()
+		for _, want := range []string{"Batching: 3 or more notifications per poll round, waiting up to 30s", "Rate Limit: 20 notifications per 2h0m0s"} {
+			if !strings.Contains(s, want) {
+				t.Errorf("String() = %q, want %q", s, want)
+			}
+		}
+
+		t.Setenv("BATCH_THRESHOLD", "0")
+		t.Setenv("RATE_LIMIT_PERIOD", "24h")
+		if cfg, err = LoadFile(path); err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if cfg.BatchThreshold != 0 || cfg.RateLimitPeriod != 24*time.Hour {
+			t.Errorf("BatchThreshold = %d, RateLimitPeriod = %v, want the env's", cfg.BatchThreshold, cfg.RateLimitPeriod)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for key, value := range map[string]string{"BATCH_THRESHOLD": "some", "BATCH_WINDOW": "10", "RATE_LIMIT": "lots", "RATE_LIMIT_PERIOD": "hourly"} {
+			clearConfigEnv(t)
+			t.Setenv(key, value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("%s=%q: Load() error = %v", key, value, err)
+			}
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		cfg := &Config{DryRun: true, CheckInterval: time.Minute, BatchThreshold: 1, RateLimit: -1}
+		err := cfg.Validate()
+		for _, want := range []string{"BATCH_THRESHOLD must be at least 2", "RATE_LIMIT cannot be negative"} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error = %v, want %q", err, want)
+			}
+		}
+		cfg = &Config{DryRun: true, CheckInterval: time.Minute, BatchThreshold: 2, RateLimit: 5}
+		err = cfg.Validate()
+		for _, want := range []string{"BATCH_WINDOW must be positive", "RATE_LIMIT_PERIOD must be positive"} {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error = %v, want %q", err, want)
+			}
+		}
+	})
+}
+
+func TestHTTPAddr(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		t.Setenv("HTTP_ADDR", "")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.HTTPAddr != DefaultHTTPAddr {
+			t.Errorf("HTTPAddr = %q, want %q", cfg.HTTPAddr, DefaultHTTPAddr)
+		}
+	})
+
+	t.Run("env override", func(t *testing.T) {
+		t.Setenv("HTTP_ADDR", "127.0.0.1:9100")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.HTTPAddr != "127.0.0.1:9100" {
+			t.Errorf("HTTPAddr = %q, want 127.0.0.1:9100", cfg.HTTPAddr)
+		}
+	})
+
+	t.Run("off disables the server", func(t *testing.T) {
+		t.Setenv("HTTP_ADDR", "OFF")
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if cfg.HTTPAddr != "" {
+			t.Errorf("HTTPAddr = %q, want empty", cfg.HTTPAddr)
+		}
+	})
+
+	t.Run("invalid address fails validation", func(t *testing.T) {
+		cfg := &Config{DryRun: true, CheckInterval: time.Minute, HTTPAddr: "9090"}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "HTTP_ADDR must be host:port") {
+			t.Errorf("Validate() error = %v, want HTTP_ADDR error", err)
+		}
+	})
+}
+
+func TestAdminToken(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", " s3cret ")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.AdminToken != "s3cret" {
+		t.Errorf("AdminToken = %q, want s3cret", cfg.AdminToken)
+	}
+	if s := cfg.String(); !strings.Contains(s, "Admin API: enabled") || strings.Contains(s, "s3cret") {
+		t.Errorf("String() = %q, want the admin API enabled without the token", s)
+	}
+
+	for _, tt := range []struct {
+		cfg  Config
+		want string
+	}{
+		{Config{AdminToken: "s3cret"}, "ADMIN_TOKEN requires the HTTP server"},
+		{Config{AdminToken: "s3 cret", HTTPAddr: DefaultHTTPAddr}, "ADMIN_TOKEN cannot contain whitespace"},
+	} {
+		tt.cfg.DryRun, tt.cfg.CheckInterval = true, time.Minute
+		if err := tt.cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// digestOff is the DIGEST_AT value that turns off a digest scheduled in the
+// config file.
+const digestOff = "off"
+
+// Digest schedules a daily notification summarizing every monitored
+// station: its current VCP, mode and status and the changes reported since
+// the previous digest.
+type Digest struct {
+	// At is the time of day the digest is sent, as an offset from midnight
+	// in Location.
+	At time.Duration
+	// Location is the digest's time zone; nil means the local time zone.
+	Location *time.Location
+	// Image attaches a contact sheet of the stations' latest radar images.
+	Image bool
+}
+
+// Next returns the first time after t that the digest is due. On a day
+// whose clock skips At (a daylight-saving change), it is due at the
+// equivalent instant time.Date normalizes to.
+func (d Digest) Next(t time.Time) time.Time {
+	loc := d.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	hour, minute := int(d.At/time.Hour), int(d.At%time.Hour/time.Minute)
+	for day := 0; ; day++ {
+		next := time.Date(t.Year(), t.Month(), t.Day()+day, hour, minute, 0, 0, loc)
+		if next.After(t) {
+			return next
+		}
+	}
+}
+
+// String formats the schedule as "07:00 America/Chicago, with images".
+func (d Digest) String() string {
+	loc := "local"
+	if d.Location != nil {
+		loc = d.Location.String()
+	}
+	s := fmt.Sprintf("%s %s", formatClock(d.At), loc)
+	if d.Image {
+		s += ", with images"
+	}
+	return s
+}
+
+// applyDigestEnv schedules the digest at DIGEST_AT ("07:00"), or turns it
+// off when that is "off", and adjusts the scheduled digest with
+// DIGEST_TIMEZONE and DIGEST_IMAGE.
+func (c *Config) applyDigestEnv() error {
+	if v := strings.TrimSpace(os.Getenv("DIGEST_AT")); v != "" {
+		if strings.EqualFold(v, digestOff) {
+			c.Digest = nil
+		} else {
+			at, err := parseClock(v)
+			if err != nil {
+				return fmt.Errorf("invalid DIGEST_AT value '%s': %w", v, err)
+			}
+			if c.Digest == nil {
+				c.Digest = &Digest{}
+			}
+			c.Digest.At = at
+		}
+		c.clearSource("digest.at")
+	}
+
+	if tz := strings.TrimSpace(os.Getenv("DIGEST_TIMEZONE")); tz != "" {
+		if c.Digest == nil {
+			return errors.New("DIGEST_TIMEZONE is set but no digest is scheduled (set DIGEST_AT)")
+		}
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return fmt.Errorf("invalid DIGEST_TIMEZONE value '%s': %w", tz, err)
+		}
+		c.Digest.Location = loc
+		c.clearSource("digest.timezone")
+	}
+
+	if v := os.Getenv("DIGEST_IMAGE"); v != "" {
+		if c.Digest == nil {
+			return errors.New("DIGEST_IMAGE is set but no digest is scheduled (set DIGEST_AT)")
+		}
+		image, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DIGEST_IMAGE value '%s': %w", v, err)
+		}
+		c.Digest.Image = image
+		c.clearSource("digest.image")
+	}
+	return nil
+}
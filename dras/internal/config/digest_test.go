@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigestNext(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := Digest{At: 7 * time.Hour, Location: chicago}
+
+	for _, tt := range []struct {
+		after, want string // UTC; Chicago is UTC-5 in May, UTC-6 in November
+	}{
+		{"2024-05-01T11:59:00Z", "2024-05-01T12:00:00Z"},
+		{"2024-05-01T12:00:00Z", "2024-05-02T12:00:00Z"},
+		{"2024-05-01T23:00:00Z", "2024-05-02T12:00:00Z"},
+		// Clocks go back on November 3rd: 07:00 is an hour later in UTC.
+		{"2024-11-02T12:30:00Z", "2024-11-03T13:00:00Z"},
+	} {
+		after, _ := time.Parse(time.RFC3339, tt.after)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := d.Next(after); !got.Equal(want) {
+			t.Errorf("Next(%s) = %s, want %s", tt.after, got.UTC().Format(time.RFC3339), tt.want)
+		}
+	}
+
+	midnight := Digest{At: 24 * time.Hour, Location: time.UTC}
+	if got, want := midnight.Next(time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)), time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("24:00 Next() = %s, want %s", got, want)
+	}
+}
+
+func TestDigestFromFileAndEnv(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, `dry_run: true
+digest:
+  at: "07:00"
+  timezone: America/Chicago
+  image: true
+templates:
+  digest:
+    title: "Radar digest"
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if cfg.Digest == nil || cfg.Digest.String() != "07:00 America/Chicago, with images" {
+		t.Errorf("Digest = %v", cfg.Digest)
+	}
+	if cfg.Templates.Digest.Title != "Radar digest" {
+		t.Errorf("digest title template = %q", cfg.Templates.Digest.Title)
+	}
+
+	t.Setenv("DIGEST_AT", "18:30")
+	t.Setenv("DIGEST_IMAGE", "false")
+	if cfg, err = LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if cfg.Digest == nil || cfg.Digest.String() != "18:30 America/Chicago" {
+		t.Errorf("Digest = %v, want the env time without images", cfg.Digest)
+	}
+
+	t.Setenv("DIGEST_AT", "off")
+	t.Setenv("DIGEST_IMAGE", "")
+	if cfg, err = LoadFile(path); err != nil || cfg.Digest != nil {
+		t.Errorf("DIGEST_AT=off: Digest = %v, error %v; want it off", cfg.Digest, err)
+	}
+
+	t.Setenv("DIGEST_AT", "")
+	t.Setenv("DIGEST_TIMEZONE", "UTC")
+	if _, err := LoadFile(""); err == nil || !strings.Contains(err.Error(), "set DIGEST_AT") {
+		t.Errorf("DIGEST_TIMEZONE without a digest: error = %v", err)
+	}
+	t.Setenv("DIGEST_TIMEZONE", "")
+
+	for _, bad := range []string{"7am", "24:30"} {
+		t.Setenv("DIGEST_AT", bad)
+		if _, err := LoadFile(""); err == nil {
+			t.Errorf("DIGEST_AT=%q: Load() error = nil", bad)
+		}
+	}
+	t.Setenv("DIGEST_AT", "")
+
+	if _, err := LoadFile(writeConfigFile(t, "digest:\n  image: true\n")); err == nil || !strings.Contains(err.Error(), ":1: digest.at is required") {
+		t.Errorf("digest without at: error = %v", err)
+	}
+}
@@ -0,0 +1,488 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the YAML shape of a DRAS config file. Every field is
+// optional; pointers distinguish "unset" from the zero value so the file only
+// overrides what it mentions.
+type fileConfig struct {
+	DryRun          *bool            `yaml:"dry_run"`
+	Interval        *fileInterval    `yaml:"interval"`
+	LogLevel        string           `yaml:"log_level"`
+	StateFile       string           `yaml:"state_file"`
+	PollConcurrency *int             `yaml:"poll_concurrency"`
+	HTTP            fileHTTP         `yaml:"http"`
+	NWS             fileNWS          `yaml:"nws"`
+	Pushover        filePushover     `yaml:"pushover"`
+	Alerts          AlertOverride    `yaml:"alerts"`
+	RadarImage      fileRadarImage   `yaml:"radar_image"`
+	Renderer        fileRenderer     `yaml:"renderer"`
+	Debounce        fileDebounce     `yaml:"debounce"`
+	Outage          fileOutage       `yaml:"outage"`
+	QuietHours      []fileQuietHours `yaml:"quiet_hours"`
+	Digest          *fileDigest      `yaml:"digest"`
+	Batch           fileBatch        `yaml:"batch"`
+	RateLimit       fileRateLimit    `yaml:"rate_limit"`
+	Shutdown        fileShutdown     `yaml:"shutdown"`
+	Notifiers       []fileNotifier   `yaml:"notifiers"`
+	Templates       fileTemplates    `yaml:"templates"`
+	Stations        []fileStation    `yaml:"stations"`
+}
+
+type filePushover struct {
+	APIToken string                         `yaml:"api_token"`
+	UserKey  string                         `yaml:"user_key"`
+	Messages map[string]filePushoverMessage `yaml:"messages"`
+}
+
+type filePushoverMessage struct {
+	Priority int           `yaml:"priority"`
+	Sound    string        `yaml:"sound"`
+	URL      string        `yaml:"url"`
+	URLTitle string        `yaml:"url_title"`
+	TTL      *fileDuration `yaml:"ttl"`
+	HTML     bool          `yaml:"html"`
+	Retry    *fileDuration `yaml:"retry"`
+	Expire   *fileDuration `yaml:"expire"`
+}
+
+// pushoverMessages converts the file's message options, lowercasing keys.
+func pushoverMessages(in map[string]filePushoverMessage) map[string]notify.PushoverMessage {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]notify.PushoverMessage, len(in))
+	for k, fm := range in {
+		m := notify.PushoverMessage{
+			Priority: fm.Priority,
+			Sound:    strings.TrimSpace(fm.Sound),
+			URL:      strings.TrimSpace(fm.URL),
+			URLTitle: fm.URLTitle,
+			HTML:     fm.HTML,
+		}
+		if fm.TTL != nil {
+			m.TTL = time.Duration(*fm.TTL)
+		}
+		if fm.Retry != nil {
+			m.Retry = time.Duration(*fm.Retry)
+		}
+		if fm.Expire != nil {
+			m.Expire = time.Duration(*fm.Expire)
+		}
+		out[strings.ToLower(strings.TrimSpace(k))] = m
+	}
+	return out
+}
+
+type fileHTTP struct {
+	Addr       string `yaml:"addr"`
+	AdminToken string `yaml:"admin_token"`
+}
+
+type fileNWS struct {
+	BaseURL string `yaml:"base_url"`
+	Bulk    *bool  `yaml:"bulk"`
+}
+
+type fileRadarImage struct {
+	Enabled     *bool         `yaml:"enabled"`
+	URLTemplate string        `yaml:"url_template"`
+	Retention   *fileDuration `yaml:"retention"`
+}
+
+type fileRenderer struct {
+	URL     string        `yaml:"url"`
+	Timeout *fileDuration `yaml:"timeout"`
+}
+
+type fileDebounce struct {
+	Polls    *int          `yaml:"polls"`
+	Duration *fileDuration `yaml:"duration"`
+}
+
+type fileOutage struct {
+	UnreachableAfter *int          `yaml:"unreachable_after"`
+	StaleAfter       *fileDuration `yaml:"stale_after"`
+}
+
+type fileBatch struct {
+	Threshold *int          `yaml:"threshold"`
+	Window    *fileDuration `yaml:"window"`
+}
+
+type fileRateLimit struct {
+	Count  *int          `yaml:"count"`
+	Period *fileDuration `yaml:"period"`
+}
+
+type fileShutdown struct {
+	GracePeriod *fileDuration `yaml:"grace_period"`
+	Notify      *bool         `yaml:"notify"`
+}
+
+type fileTemplates struct {
+	Startup  fileTemplate `yaml:"startup"`
+	Change   fileTemplate `yaml:"change"`
+	Recovery fileTemplate `yaml:"recovery"`
+	Outage   fileTemplate `yaml:"outage"`
+	Resumed  fileTemplate `yaml:"resumed"`
+	Digest   fileTemplate `yaml:"digest"`
+	Batch    fileTemplate `yaml:"batch"`
+}
+
+type fileTemplate struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+}
+
+type fileNotifier struct {
+	Type     string            `yaml:"type"`
+	Name     string            `yaml:"name"`
+	URL      string            `yaml:"url"`
+	URLs     []string          `yaml:"urls"`
+	Topic    string            `yaml:"topic"`
+	Token    string            `yaml:"token"`
+	UserKey  string            `yaml:"user_key"`
+	Priority int               `yaml:"priority"`
+	Headers  map[string]string `yaml:"headers"`
+	Secret   string            `yaml:"secret"`
+	Image    string            `yaml:"image"`
+
+	Messages map[string]filePushoverMessage `yaml:"messages"`
+}
+
+type fileStation struct {
+	ID       string            `yaml:"id"`
+	Interval *fileInterval     `yaml:"interval"`
+	Alerts   AlertOverride     `yaml:"alerts"`
+	Image    fileStationImage  `yaml:"image"`
+	Pushover fileStationTarget `yaml:"pushover"`
+	// QuietHours is a pointer so that an empty list, which turns the
+	// global quiet hours off for the station, differs from none.
+	QuietHours *[]fileQuietHours `yaml:"quiet_hours"`
+}
+
+type fileStationImage struct {
+	Enabled *bool `yaml:"enabled"`
+}
+
+type fileStationTarget struct {
+	UserKey string `yaml:"user_key"`
+}
+
+type fileQuietHours struct {
+	Start    fileClock    `yaml:"start"`
+	End      fileClock    `yaml:"end"`
+	Timezone fileLocation `yaml:"timezone"`
+	Fields   []string     `yaml:"fields"`
+	Action   string       `yaml:"action"`
+}
+
+// quietHours converts the file's quiet-hour windows. The action defaults
+// to queue.
+func quietHours(in []fileQuietHours) []QuietHours {
+	out := make([]QuietHours, len(in))
+	for i, fq := range in {
+		out[i] = QuietHours{
+			Start:    time.Duration(fq.Start),
+			End:      time.Duration(fq.End),
+			Location: fq.Timezone.loc,
+			Fields:   parseFields(strings.Join(fq.Fields, ",")),
+			Action:   QuietAction(strings.ToLower(strings.TrimSpace(fq.Action))),
+		}
+		if out[i].Action == "" {
+			out[i].Action = QuietQueue
+		}
+	}
+	return out
+}
+
+type fileDigest struct {
+	At       *fileClock   `yaml:"at"`
+	Timezone fileLocation `yaml:"timezone"`
+	Image    bool         `yaml:"image"`
+}
+
+// fileClock is a time of day in 24-hour "HH:MM" form.
+type fileClock time.Duration
+
+func (d *fileClock) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := parseClock(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	*d = fileClock(parsed)
+	return nil
+}
+
+// fileLocation is an IANA time zone name ("America/Chicago").
+type fileLocation struct {
+	loc *time.Location
+}
+
+func (l *fileLocation) UnmarshalYAML(value *yaml.Node) error {
+	loc, err := time.LoadLocation(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid time zone %q: %w", value.Line, value.Value, err)
+	}
+	l.loc = loc
+	return nil
+}
+
+// fileDuration is a Go duration string ("90s", "1h").
+type fileDuration time.Duration
+
+func (d *fileDuration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q: %w", value.Line, value.Value, err)
+	}
+	*d = fileDuration(parsed)
+	return nil
+}
+
+// fileInterval accepts either a bare integer (minutes, matching the INTERVAL
+// env var) or a Go duration string.
+type fileInterval time.Duration
+
+func (d *fileInterval) UnmarshalYAML(value *yaml.Node) error {
+	if minutes, err := strconv.ParseInt(value.Value, 10, 64); err == nil {
+		*d = fileInterval(time.Duration(minutes) * time.Minute)
+		return nil
+	}
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid interval %q (use minutes or a duration like \"5m\")", value.Line, value.Value)
+	}
+	*d = fileInterval(parsed)
+	return nil
+}
+
+// applyFile reads the YAML config file at path and applies every value it
+// sets on top of cfg. The line each key was declared on is recorded so that
+// Validate can point at the offending line.
+func (c *Config) applyFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	// Decode a second time into a node tree purely for line numbers.
+	var root yaml.Node
+	if err := yaml.Unmarshal(raw, &root); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	lines := make(map[string]int)
+	collectLines(&root, "", lines)
+
+	c.ConfigFile = path
+	c.sources = make(map[string]string, len(lines))
+	for key, line := range lines {
+		c.sources[key] = fmt.Sprintf("%s:%d", path, line)
+	}
+
+	if fc.DryRun != nil {
+		c.DryRun = *fc.DryRun
+	}
+	if fc.Interval != nil {
+		c.CheckInterval = time.Duration(*fc.Interval)
+	}
+	if fc.LogLevel != "" {
+		c.LogLevel = fc.LogLevel
+	}
+	if fc.StateFile != "" {
+		c.StateFile = strings.TrimSpace(fc.StateFile)
+	}
+	if v := strings.TrimSpace(fc.HTTP.Addr); v != "" {
+		c.HTTPAddr = v
+		if strings.EqualFold(v, httpAddrOff) {
+			c.HTTPAddr = ""
+		}
+	}
+	if v := strings.TrimSpace(fc.HTTP.AdminToken); v != "" {
+		c.AdminToken = v
+	}
+	if v := strings.TrimSpace(fc.NWS.BaseURL); v != "" {
+		c.NWSBaseURL = v
+	}
+	if fc.NWS.Bulk != nil {
+		c.NWSBulk = *fc.NWS.Bulk
+	}
+	if fc.PollConcurrency != nil {
+		c.PollConcurrency = *fc.PollConcurrency
+	}
+	if fc.Pushover.APIToken != "" {
+		c.PushoverAPIToken = fc.Pushover.APIToken
+	}
+	if fc.Pushover.UserKey != "" {
+		c.PushoverUserKey = fc.Pushover.UserKey
+	}
+	if m := pushoverMessages(fc.Pushover.Messages); m != nil {
+		c.PushoverMessages = m
+	}
+	c.AlertConfig = fc.Alerts.Apply(c.AlertConfig)
+	if fc.RadarImage.Enabled != nil {
+		c.RadarImageEnabled = *fc.RadarImage.Enabled
+	}
+	if fc.RadarImage.URLTemplate != "" {
+		c.RadarImageURLTmpl = fc.RadarImage.URLTemplate
+	}
+	if fc.RadarImage.Retention != nil {
+		c.RadarImageRetention = time.Duration(*fc.RadarImage.Retention)
+	}
+	if fc.Renderer.URL != "" {
+		c.RendererURL = strings.TrimSpace(fc.Renderer.URL)
+	}
+	if fc.Renderer.Timeout != nil {
+		c.RendererTimeout = time.Duration(*fc.Renderer.Timeout)
+	}
+	if fc.Debounce.Polls != nil {
+		c.DebouncePolls = *fc.Debounce.Polls
+	}
+	if fc.Debounce.Duration != nil {
+		c.DebounceDuration = time.Duration(*fc.Debounce.Duration)
+	}
+	if fc.Outage.UnreachableAfter != nil {
+		c.UnreachableAfter = *fc.Outage.UnreachableAfter
+	}
+	if fc.Outage.StaleAfter != nil {
+		c.StaleAfter = time.Duration(*fc.Outage.StaleAfter)
+	}
+	if len(fc.QuietHours) > 0 {
+		c.QuietHours = quietHours(fc.QuietHours)
+	}
+	if fc.Digest != nil {
+		if fc.Digest.At == nil {
+			return fmt.Errorf("%s: digest.at is required", c.sources["digest"])
+		}
+		c.Digest = &Digest{
+			At:       time.Duration(*fc.Digest.At),
+			Location: fc.Digest.Timezone.loc,
+			Image:    fc.Digest.Image,
+		}
+	}
+	if fc.Batch.Threshold != nil {
+		c.BatchThreshold = *fc.Batch.Threshold
+	}
+	if fc.Batch.Window != nil {
+		c.BatchWindow = time.Duration(*fc.Batch.Window)
+	}
+	if fc.RateLimit.Count != nil {
+		c.RateLimit = *fc.RateLimit.Count
+	}
+	if fc.RateLimit.Period != nil {
+		c.RateLimitPeriod = time.Duration(*fc.RateLimit.Period)
+	}
+	if fc.Shutdown.GracePeriod != nil {
+		c.ShutdownGracePeriod = time.Duration(*fc.Shutdown.GracePeriod)
+	}
+	if fc.Shutdown.Notify != nil {
+		c.ShutdownNotify = *fc.Shutdown.Notify
+	}
+
+	for _, fn := range fc.Notifiers {
+		c.Notifiers = append(c.Notifiers, notify.BackendConfig{
+			Type:      strings.ToLower(strings.TrimSpace(fn.Type)),
+			Name:      strings.TrimSpace(fn.Name),
+			URL:       strings.TrimSpace(fn.URL),
+			URLs:      fn.URLs,
+			Topic:     fn.Topic,
+			Token:     fn.Token,
+			UserKey:   fn.UserKey,
+			Priority:  fn.Priority,
+			Headers:   fn.Headers,
+			Secret:    fn.Secret,
+			ImageMode: fn.Image,
+
+			PushoverMessages: pushoverMessages(fn.Messages),
+		})
+	}
+
+	for _, t := range []struct {
+		src fileTemplate
+		dst *message.Template
+	}{
+		{fc.Templates.Startup, &c.Templates.Startup},
+		{fc.Templates.Change, &c.Templates.Change},
+		{fc.Templates.Recovery, &c.Templates.Recovery},
+		{fc.Templates.Outage, &c.Templates.Outage},
+		{fc.Templates.Resumed, &c.Templates.Resumed},
+		{fc.Templates.Digest, &c.Templates.Digest},
+		{fc.Templates.Batch, &c.Templates.Batch},
+	} {
+		if t.src.Title != "" {
+			t.dst.Title = t.src.Title
+		}
+		if t.src.Body != "" {
+			t.dst.Body = t.src.Body
+		}
+	}
+
+	c.Stations = make([]StationOverride, 0, len(fc.Stations))
+	for i, fs := range fc.Stations {
+		override := StationOverride{
+			ID:              strings.ToUpper(strings.TrimSpace(fs.ID)),
+			Alerts:          fs.Alerts,
+			ImageEnabled:    fs.Image.Enabled,
+			PushoverUserKey: fs.Pushover.UserKey,
+			Source:          c.sources[fmt.Sprintf("stations[%d]", i)],
+		}
+		if fs.QuietHours != nil {
+			override.QuietHours = quietHours(*fs.QuietHours)
+		}
+		if fs.Interval != nil {
+			override.CheckInterval = time.Duration(*fs.Interval)
+		}
+		c.Stations = append(c.Stations, override)
+	}
+
+	return nil
+}
+
+// collectLines walks a YAML node tree and records the line of every mapping
+// key and sequence item under a dotted path, e.g. "pushover.api_token" or
+// "stations[1].interval".
+func collectLines(node *yaml.Node, prefix string, out map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			collectLines(child, prefix, out)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := key.Value
+			if prefix != "" {
+				path = prefix + "." + key.Value
+			}
+			out[path] = key.Line
+			collectLines(value, path, out)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			path := fmt.Sprintf("%s[%d]", prefix, i)
+			out[path] = item.Line
+			collectLines(item, path, out)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+// writeConfigFile writes contents to a temp YAML file and returns its path.
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dras.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	return path
+}
+
+// clearConfigEnv blanks every env var LoadFile reads so the host
+// environment can't leak into a test. Empty counts as unset.
+func clearConfigEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"STATION_IDS", "PUSHOVER_API_TOKEN", "PUSHOVER_USER_KEY", "DRYRUN",
+		"INTERVAL", "LOG_LEVEL", "ALERT_VCP", "ALERT_STATUS", "ALERT_OPERABILITY",
+		"ALERT_POWER_SOURCE", "ALERT_GEN_STATE", "RADAR_IMAGE_ENABLED",
+		"RADAR_IMAGE_URL_TEMPLATE", "RADAR_IMAGE_RETENTION", "RENDERER_URL", "NWS_BASE_URL",
+		"RENDERER_TIMEOUT", "STATE_FILE", "SHUTDOWN_GRACE_PERIOD",
+		"SHUTDOWN_NOTIFY", "NTFY_URL", "NTFY_TOPIC", "NTFY_TOKEN", "GOTIFY_URL",
+		"GOTIFY_TOKEN", "SLACK_WEBHOOK_URL", "DISCORD_WEBHOOK_URL", "WEBHOOK_URL",
+		"WEBHOOK_SECRET", "WEBHOOK_IMAGE", "TEMPLATE_STARTUP_TITLE",
+		"TEMPLATE_STARTUP_BODY", "TEMPLATE_CHANGE_TITLE", "TEMPLATE_CHANGE_BODY",
+		"TEMPLATE_RECOVERY_TITLE", "TEMPLATE_RECOVERY_BODY", "DEBOUNCE_POLLS",
+		"DEBOUNCE_DURATION", "PUSHOVER_PRIORITY", "PUSHOVER_SOUND", "HTTP_ADDR", "ADMIN_TOKEN",
+		"NWS_BULK", "POLL_CONCURRENCY", "UNREACHABLE_AFTER", "STALE_AFTER",
+		"TEMPLATE_OUTAGE_TITLE", "TEMPLATE_OUTAGE_BODY", "TEMPLATE_RESUMED_TITLE",
+		"TEMPLATE_RESUMED_BODY", "QUIET_HOURS", "QUIET_HOURS_TIMEZONE", "QUIET_HOURS_FIELDS",
+		"QUIET_HOURS_ACTION", "DIGEST_AT", "DIGEST_TIMEZONE", "DIGEST_IMAGE",
+		"TEMPLATE_DIGEST_TITLE", "TEMPLATE_DIGEST_BODY", "BATCH_THRESHOLD", "BATCH_WINDOW",
+		"RATE_LIMIT", "RATE_LIMIT_PERIOD", "TEMPLATE_BATCH_TITLE", "TEMPLATE_BATCH_BODY",
+		"DRAS_CONFIG",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+const sampleConfig = `
+interval: 5
+log_level: debug
+pushover:
+  api_token: abcdefghijklmnopqrstuvwxyz1234
+  user_key: ABCDEFGHIJKLMNOPQRSTUVWXYZ1234
+alerts:
+  status: true
+radar_image:
+  retention: 30m
+debounce:
+  polls: 2
+  duration: 10m
+outage:
+  unreachable_after: 5
+  stale_after: 2h
+http:
+  addr: 127.0.0.1:9100
+  admin_token: s3cret-admin-token
+nws:
+  base_url: http://127.0.0.1:8081/
+  bulk: true
+shutdown:
+  grace_period: 10s
+  notify: true
+stations:
+  - id: katx
+    interval: 2m
+    alerts:
+      vcp: false
+      power_source: true
+    image:
+      enabled: false
+  - id: KRAX
+    pushover:
+      user_key: ZYXWVUTSRQPONMLKJIHGFEDCBA4321
+`
+
+func TestLoadFile(t *testing.T) {
+	t.Run("applies file values and per-station overrides", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, sampleConfig)
+
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() error: %v", err)
+		}
+
+		if cfg.ConfigFile != path {
+			t.Errorf("ConfigFile = %q, want %q", cfg.ConfigFile, path)
+		}
+		if cfg.CheckInterval != 5*time.Minute {
+			t.Errorf("CheckInterval = %v, want 5m (bare integer is minutes)", cfg.CheckInterval)
+		}
+		if cfg.LogLevel != "debug" {
+			t.Errorf("LogLevel = %q, want debug", cfg.LogLevel)
+		}
+		if !cfg.AlertConfig.VCP || !cfg.AlertConfig.Status {
+			t.Errorf("AlertConfig = %+v, want VCP default kept and Status enabled", cfg.AlertConfig)
+		}
+		if cfg.RadarImageRetention != 30*time.Minute {
+			t.Errorf("RadarImageRetention = %v, want 30m", cfg.RadarImageRetention)
+		}
+		if cfg.ShutdownGracePeriod != 10*time.Second || !cfg.ShutdownNotify {
+			t.Errorf("shutdown = %v / notify %t, want 10s / true", cfg.ShutdownGracePeriod, cfg.ShutdownNotify)
+		}
+		if cfg.DebouncePolls != 2 || cfg.DebounceDuration != 10*time.Minute {
+			t.Errorf("debounce = %d polls / %v, want 2 / 10m", cfg.DebouncePolls, cfg.DebounceDuration)
+		}
+		if cfg.UnreachableAfter != 5 || cfg.StaleAfter != 2*time.Hour {
+			t.Errorf("outage = %d polls / %v, want 5 / 2h", cfg.UnreachableAfter, cfg.StaleAfter)
+		}
+		if cfg.HTTPAddr != "127.0.0.1:9100" {
+			t.Errorf("HTTPAddr = %q, want 127.0.0.1:9100", cfg.HTTPAddr)
+		}
+		if cfg.AdminToken != "s3cret-admin-token" {
+			t.Errorf("AdminToken = %q, want s3cret-admin-token", cfg.AdminToken)
+		}
+		if cfg.NWSBaseURL != "http://127.0.0.1:8081/" {
+			t.Errorf("NWSBaseURL = %q, want http://127.0.0.1:8081/", cfg.NWSBaseURL)
+		}
+		if !cfg.NWSBulk {
+			t.Error("NWSBulk = false, want true")
+		}
+		if got := strings.Join(cfg.StationIDs(), ","); got != "KATX,KRAX" {
+			t.Errorf("StationIDs() = %q, want KATX,KRAX", got)
+		}
+
+		katx := cfg.Station("KATX")
+		if katx.CheckInterval != 2*time.Minute {
+			t.Errorf("KATX CheckInterval = %v, want 2m", katx.CheckInterval)
+		}
+		if katx.AlertConfig.VCP || !katx.AlertConfig.PowerSource || !katx.AlertConfig.Status {
+			t.Errorf("KATX AlertConfig = %+v, want VCP off, PowerSource on, Status inherited", katx.AlertConfig)
+		}
+		if katx.ImageEnabled {
+			t.Error("KATX ImageEnabled = true, want false")
+		}
+
+		krax := cfg.Station("KRAX")
+		if krax.CheckInterval != 5*time.Minute {
+			t.Errorf("KRAX CheckInterval = %v, want global 5m", krax.CheckInterval)
+		}
+		if krax.PushoverUserKey != "ZYXWVUTSRQPONMLKJIHGFEDCBA4321" {
+			t.Errorf("KRAX PushoverUserKey = %q", krax.PushoverUserKey)
+		}
+		if !krax.ImageEnabled {
+			t.Error("KRAX ImageEnabled = false, want true")
+		}
+
+		if got := cfg.PollInterval(); got != 2*time.Minute {
+			t.Errorf("PollInterval() = %v, want shortest station interval 2m", got)
+		}
+	})
+
+	t.Run("env vars override file values", func(t *testing.T) {
+		clearConfigEnv(t)
+		t.Setenv("INTERVAL", "15")
+		t.Setenv("ALERT_STATUS", "false")
+		t.Setenv("STATION_IDS", "KBGM")
+		path := writeConfigFile(t, sampleConfig)
+
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if cfg.CheckInterval != 15*time.Minute {
+			t.Errorf("CheckInterval = %v, want env 15m", cfg.CheckInterval)
+		}
+		if cfg.AlertConfig.Status {
+			t.Error("AlertConfig.Status = true, want env override false")
+		}
+		if got := strings.Join(cfg.StationIDs(), ","); got != "KBGM" {
+			t.Errorf("StationIDs() = %q, want STATION_IDS to win", got)
+		}
+	})
+
+	t.Run("Load reads DRAS_CONFIG", func(t *testing.T) {
+		clearConfigEnv(t)
+		t.Setenv("DRAS_CONFIG", writeConfigFile(t, "interval: 3m\n"))
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load() error: %v", err)
+		}
+		if cfg.CheckInterval != 3*time.Minute {
+			t.Errorf("CheckInterval = %v, want 3m from DRAS_CONFIG file", cfg.CheckInterval)
+		}
+	})
+
+	t.Run("rejects unknown keys with line number", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, "interval: 5\nintervall: 10\n")
+
+		_, err := LoadFile(path)
+		if err == nil {
+			t.Fatal("LoadFile() error = nil, want unknown-field error")
+		}
+		if !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("error %q should mention line 2", err)
+		}
+	})
+
+	t.Run("rejects invalid duration with line number", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, "radar_image:\n  retention: soon\n")
+
+		_, err := LoadFile(path)
+		if err == nil {
+			t.Fatal("LoadFile() error = nil, want duration error")
+		}
+		if !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("error %q should mention line 2", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		clearConfigEnv(t)
+		if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+			t.Error("LoadFile() error = nil, want error for missing file")
+		}
+	})
+}
+
+func TestValidateReportsFileLocations(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, `pushover:
+  api_token: abcdefghijklmnopqrstuvwxyz1234
+  user_key: ABCDEFGHIJKLMNOPQRSTUVWXYZ1234
+interval: 30s
+stations:
+  - id: KATX
+  - id: K1
+  - id: KATX
+    interval: 10s
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want errors")
+	}
+
+	for _, want := range []string{
+		path + ":4: interval must be at least 1 minute",
+		path + `:7: station "K1": invalid radar station ID`,
+		path + `:8: station "KATX" is declared more than once (first at ` + path + ":6)",
+		path + `:9: station "KATX": interval must be at least 1 minute`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error missing %q\ngot: %v", want, err)
+		}
+	}
+}
+
+func TestValidateEnvOverrideDropsFileLocation(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DRYRUN", "true")
+	t.Setenv("LOG_LEVEL", "loud")
+	path := writeConfigFile(t, "log_level: quiet\n")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want LOG_LEVEL error")
+	}
+	if !strings.Contains(err.Error(), "LOG_LEVEL validation failed") || strings.Contains(err.Error(), path) {
+		t.Errorf("error %q should name LOG_LEVEL, not the overridden file line", err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DRYRUN", "true")
+	t.Setenv("TEMPLATE_CHANGE_TITLE", "{{.StationID}} changed")
+	path := writeConfigFile(t, `templates:
+  change:
+    title: ignored, the env var wins
+    body: "{{range .Changes}}{{.Text}} ({{.Severity}})\n{{end}}"
+  recovery:
+    title: "{{.StationID}} is back"
+  outage:
+    body: "{{.StationID}}: {{.Outage.Reason}}"
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	want := message.Config{
+		Change:   message.Template{Title: "{{.StationID}} changed", Body: "{{range .Changes}}{{.Text}} ({{.Severity}})\n{{end}}"},
+		Recovery: message.Template{Title: "{{.StationID}} is back"},
+		Outage:   message.Template{Body: "{{.StationID}}: {{.Outage.Reason}}"},
+	}
+	if cfg.Templates != want {
+		t.Errorf("Templates = %+v, want %+v", cfg.Templates, want)
+	}
+
+	t.Run("bad template fails validation", func(t *testing.T) {
+		path := writeConfigFile(t, `templates:
+  startup:
+    body: "{{.Old.VCP}}"
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		err = cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), path+":3: templates.startup.body: ") {
+			t.Errorf("Validate() error = %v, want one naming %s:3", err, path)
+		}
+
+		t.Setenv("TEMPLATE_CHANGE_BODY", "{{.Nope}}")
+		cfg, err = LoadFile("")
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		err = cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "TEMPLATE_CHANGE_BODY: ") {
+			t.Errorf("Validate() error = %v, want one naming TEMPLATE_CHANGE_BODY", err)
+		}
+	})
+}
+
+func TestPushoverMessages(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("PUSHOVER_SOUND", "bike")
+	path := writeConfigFile(t, `pushover:
+  api_token: abcdefghijklmnopqrstuvwxyz1234
+  user_key: ABCDEFGHIJKLMNOPQRSTUVWXYZ1234
+  messages:
+    default:
+      priority: -1
+    Status:
+      priority: 2
+      sound: siren
+      url: https://radar.weather.gov/station/{station}
+      url_title: Station page
+      retry: 2m
+      expire: 1h
+    vcp:
+      ttl: 6h
+      html: true
+stations:
+  - id: KATX
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	want := map[string]notify.PushoverMessage{
+		"default": {Priority: -1, Sound: "bike"},
+		"status": {
+			Priority: 2, Sound: "siren",
+			URL: "https://radar.weather.gov/station/{station}", URLTitle: "Station page",
+			Retry: 2 * time.Minute, Expire: time.Hour,
+		},
+		"vcp": {TTL: 6 * time.Hour, HTML: true},
+	}
+	if !reflect.DeepEqual(cfg.PushoverMessages, want) {
+		t.Errorf("PushoverMessages = %+v, want %+v", cfg.PushoverMessages, want)
+	}
+	if backends := cfg.NotifierBackends(); !reflect.DeepEqual(backends[0].PushoverMessages, want) {
+		t.Errorf("pushover backend messages = %+v, want %+v", backends[0].PushoverMessages, want)
+	}
+
+	t.Run("invalid options name their line", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, `pushover:
+  api_token: abcdefghijklmnopqrstuvwxyz1234
+  user_key: ABCDEFGHIJKLMNOPQRSTUVWXYZ1234
+  messages:
+    status:
+      priority: 5
+stations:
+  - id: KATX
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		err = cfg.Validate()
+		if want := path + ":5: pushover.messages.status: priority must be between -2 and 2, got 5"; err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want %q", err, want)
+		}
+	})
+}
+
+func TestNotifierBackends(t *testing.T) {
+	t.Run("file notifiers make pushover optional", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, `
+stations:
+  - id: KATX
+notifiers:
+  - type: ntfy
+    name: ops
+    url: https://ntfy.example.com
+    topic: dras
+  - type: slack
+    url: https://hooks.slack.com/services/T0/B0/x
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() error: %v", err)
+		}
+		var names []string
+		for _, b := range cfg.NotifierBackends() {
+			names = append(names, b.DisplayName())
+		}
+		if got := strings.Join(names, ","); got != "ops,slack" {
+			t.Errorf("NotifierBackends() = %q, want ops,slack (no pushover)", got)
+		}
+	})
+
+	t.Run("station pushover recipient needs the api token", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, `
+stations:
+  - id: KATX
+    pushover:
+      user_key: ZYXWVUTSRQPONMLKJIHGFEDCBA4321
+notifiers:
+  - type: slack
+    url: https://hooks.slack.com/services/T0/B0/x
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		err = cfg.Validate()
+		if want := path + `:5: station "KATX": pushover.user_key needs PUSHOVER_API_TOKEN`; err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("Validate() error = %v, want %q", err, want)
+		}
+
+		t.Setenv("PUSHOVER_API_TOKEN", "abcdefghijklmnopqrstuvwxyz1234")
+		t.Setenv("PUSHOVER_USER_KEY", "ABCDEFGHIJKLMNOPQRSTUVWXYZ1234")
+		if cfg, err = LoadFile(path); err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() error with the token set: %v", err)
+		}
+	})
+
+	t.Run("env backend replaces unnamed file backend of the same type", func(t *testing.T) {
+		clearConfigEnv(t)
+		t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T0/B0/from-env")
+		t.Setenv("NTFY_TOPIC", "alerts")
+		path := writeConfigFile(t, `
+notifiers:
+  - type: slack
+    url: https://hooks.slack.com/services/T0/B0/from-file
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		if len(cfg.Notifiers) != 2 {
+			t.Fatalf("Notifiers = %+v, want slack (replaced) + ntfy", cfg.Notifiers)
+		}
+		if cfg.Notifiers[0].URL != "https://hooks.slack.com/services/T0/B0/from-env" {
+			t.Errorf("slack URL = %q, want the env value", cfg.Notifiers[0].URL)
+		}
+		if cfg.Notifiers[1].Type != "ntfy" || cfg.Notifiers[1].Topic != "alerts" {
+			t.Errorf("ntfy backend = %+v", cfg.Notifiers[1])
+		}
+	})
+
+	t.Run("pushover stays required without other backends", func(t *testing.T) {
+		cfg := &Config{StationInput: "KATX", CheckInterval: time.Minute}
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "PUSHOVER_API_TOKEN is required") {
+			t.Errorf("Validate() error = %v, want PUSHOVER_API_TOKEN is required", err)
+		}
+	})
+
+	t.Run("invalid and duplicate backends report file lines", func(t *testing.T) {
+		clearConfigEnv(t)
+		path := writeConfigFile(t, `stations:
+  - id: KATX
+notifiers:
+  - type: ntfy
+  - type: sms
+  - type: slack
+    name: team
+    url: https://hooks.slack.com/services/a
+  - type: discord
+    name: team
+    url: https://discord.com/api/webhooks/b
+`)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile() error: %v", err)
+		}
+		err = cfg.Validate()
+		if err == nil {
+			t.Fatal("Validate() error = nil, want errors")
+		}
+		for _, want := range []string{
+			path + ":4: ntfy notifier \"ntfy\": topic is required",
+			path + `:5: unknown notifier type "sms"`,
+			path + `:9: notifier "team" is declared more than once`,
+		} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Validate() error missing %q:\n%v", want, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// QuietAction is what happens to a change reported during quiet hours.
+type QuietAction string
+
+const (
+	// QuietSuppress drops the change: it is logged, but neither notified
+	// nor recorded in the station history.
+	QuietSuppress QuietAction = "suppress"
+	// QuietQueue holds the change and sends it, with the others held for
+	// the station, in one notification once the window ends.
+	QuietQueue QuietAction = "queue"
+	// QuietDowngrade sends the change at low priority.
+	QuietDowngrade QuietAction = "downgrade"
+)
+
+// quietActions lists the valid QuietAction values.
+var quietActions = []QuietAction{QuietSuppress, QuietQueue, QuietDowngrade}
+
+// QuietHours is a daily window during which changes to some fields are not
+// notified as usual. Outage notifications are never affected.
+type QuietHours struct {
+	// Start and End are times of day, as offsets from midnight in Location.
+	// A window whose End is not after its Start spans midnight.
+	Start time.Duration
+	End   time.Duration
+	// Location is the window's time zone; nil means the local time zone.
+	Location *time.Location
+	// Fields are the change fields the window applies to; empty means all.
+	Fields []radar.Field
+	Action QuietAction
+}
+
+// Active reports whether t falls inside the window.
+func (q QuietHours) Active(t time.Time) bool {
+	loc := q.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if q.Start < q.End {
+		return clock >= q.Start && clock < q.End
+	}
+	return clock >= q.Start || clock < q.End
+}
+
+// Covers reports whether the window applies to changes of field.
+func (q QuietHours) Covers(field radar.Field) bool {
+	return len(q.Fields) == 0 || slices.Contains(q.Fields, field)
+}
+
+// String formats the window as "22:00-07:00 America/Chicago: queue vcp".
+func (q QuietHours) String() string {
+	loc := "local"
+	if q.Location != nil {
+		loc = q.Location.String()
+	}
+	fields := "all changes"
+	if len(q.Fields) > 0 {
+		names := make([]string, len(q.Fields))
+		for i, f := range q.Fields {
+			names[i] = string(f)
+		}
+		fields = strings.Join(names, ",")
+	}
+	return fmt.Sprintf("%s-%s %s: %s %s", formatClock(q.Start), formatClock(q.End), loc, q.Action, fields)
+}
+
+// QuietAction returns the action of the first of the station's quiet-hour
+// windows that is active at now and covers field, or "" if none is.
+func (sc StationConfig) QuietAction(field radar.Field, now time.Time) QuietAction {
+	for _, q := range sc.QuietHours {
+		if q.Active(now) && q.Covers(field) {
+			return q.Action
+		}
+	}
+	return ""
+}
+
+// parseClock parses a time of day in 24-hour "HH:MM" form, "24:00"
+// included, into an offset from midnight.
+func parseClock(s string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time of day %q (use HH:MM)", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || h == 24 && m != 0 {
+		return 0, fmt.Errorf("invalid time of day %q (use HH:MM)", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// formatClock formats an offset from midnight as "HH:MM".
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// parseFields parses a comma-separated list of change fields.
+func parseFields(s string) []radar.Field {
+	var fields []radar.Field
+	for f := range strings.SplitSeq(s, ",") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			fields = append(fields, radar.Field(f))
+		}
+	}
+	return fields
+}
+
+// applyQuietHoursEnv replaces the global quiet hours with the window in
+// QUIET_HOURS ("22:00-07:00"), if set, shaped by QUIET_HOURS_TIMEZONE,
+// QUIET_HOURS_FIELDS and QUIET_HOURS_ACTION.
+func (c *Config) applyQuietHoursEnv() error {
+	v := strings.TrimSpace(os.Getenv("QUIET_HOURS"))
+	if v == "" {
+		return nil
+	}
+	start, end, ok := strings.Cut(v, "-")
+	if !ok {
+		return fmt.Errorf("invalid QUIET_HOURS value '%s': want start-end, e.g. 22:00-07:00", v)
+	}
+	q := QuietHours{Action: QuietQueue}
+	var err error
+	if q.Start, err = parseClock(strings.TrimSpace(start)); err != nil {
+		return fmt.Errorf("invalid QUIET_HOURS value '%s': %w", v, err)
+	}
+	if q.End, err = parseClock(strings.TrimSpace(end)); err != nil {
+		return fmt.Errorf("invalid QUIET_HOURS value '%s': %w", v, err)
+	}
+	if tz := strings.TrimSpace(os.Getenv("QUIET_HOURS_TIMEZONE")); tz != "" {
+		if q.Location, err = time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid QUIET_HOURS_TIMEZONE value '%s': %w", tz, err)
+		}
+	}
+	q.Fields = parseFields(os.Getenv("QUIET_HOURS_FIELDS"))
+	if a := strings.TrimSpace(os.Getenv("QUIET_HOURS_ACTION")); a != "" {
+		q.Action = QuietAction(strings.ToLower(a))
+	}
+	c.QuietHours = []QuietHours{q}
+	for key := range c.sources {
+		if strings.HasPrefix(key, "quiet_hours") {
+			c.clearSource(key)
+		}
+	}
+	return nil
+}
+
+// validateQuietHours checks the fields and action of each window in qs.
+// key is the windows' config-file key, e.g. "quiet_hours" or
+// "stations[0].quiet_hours".
+func (c *Config) validateQuietHours(key string, qs []QuietHours) []string {
+	var errors []string
+	for i, q := range qs {
+		at := fmt.Sprintf("%s[%d]", key, i)
+		if !slices.Contains(quietActions, q.Action) {
+			errors = append(errors, fmt.Sprintf("%s must be suppress, queue or downgrade, got %q", c.label(at+".action", "QUIET_HOURS_ACTION"), q.Action))
+		}
+		for _, f := range q.Fields {
+			if !slices.Contains(radar.Fields(), f) {
+				errors = append(errors, fmt.Sprintf("%s: unknown field %q", c.label(at+".fields", "QUIET_HOURS_FIELDS"), f))
+			}
+		}
+	}
+	return errors
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatal(err)
+	}
+	overnight := QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour, Location: chicago}
+	daytime := QuietHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: chicago}
+
+	for _, tt := range []struct {
+		at                 string // UTC; Chicago is UTC-5 in May
+		overnight, daytime bool
+	}{
+		{"2024-05-01T02:59:00Z", false, false},
+		{"2024-05-01T03:00:00Z", true, false},
+		{"2024-05-01T08:00:00Z", true, false},
+		{"2024-05-01T12:00:00Z", false, false},
+		{"2024-05-01T14:00:00Z", false, true},
+		{"2024-05-01T22:00:00Z", false, false},
+	} {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := overnight.Active(at); got != tt.overnight {
+			t.Errorf("overnight.Active(%s) = %t", tt.at, got)
+		}
+		if got := daytime.Active(at); got != tt.daytime {
+			t.Errorf("daytime.Active(%s) = %t", tt.at, got)
+		}
+	}
+	if allDay := (QuietHours{Location: chicago}); !allDay.Active(time.Now()) {
+		t.Error("a 00:00-00:00 window is not active")
+	}
+}
+
+func TestStationQuietAction(t *testing.T) {
+	night := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	cfg := &Config{
+		QuietHours: []QuietHours{
+			{Start: 22 * time.Hour, End: 7 * time.Hour, Location: time.UTC, Fields: []radar.Field{radar.FieldVCP}, Action: QuietQueue},
+			{Start: 22 * time.Hour, End: 7 * time.Hour, Location: time.UTC, Action: QuietDowngrade},
+		},
+		Stations: []StationOverride{{ID: "KRAX", QuietHours: []QuietHours{}}},
+	}
+
+	katx := cfg.Station("KATX")
+	if got := katx.QuietAction(radar.FieldVCP, night); got != QuietQueue {
+		t.Errorf("KATX vcp = %q, want queue", got)
+	}
+	if got := katx.QuietAction(radar.FieldStatus, night); got != QuietDowngrade {
+		t.Errorf("KATX status = %q, want downgrade", got)
+	}
+	if got := katx.QuietAction(radar.FieldVCP, night.Add(6*time.Hour)); got != "" {
+		t.Errorf("KATX vcp by day = %q, want none", got)
+	}
+	if got := cfg.Station("KRAX").QuietAction(radar.FieldVCP, night); got != "" {
+		t.Errorf("KRAX vcp = %q, want none: its empty list turns quiet hours off", got)
+	}
+}
+
+func TestQuietHoursFromEnv(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DRYRUN", "true")
+	t.Setenv("QUIET_HOURS", "22:30-06:00")
+	t.Setenv("QUIET_HOURS_TIMEZONE", "America/Chicago")
+	t.Setenv("QUIET_HOURS_FIELDS", "vcp, power_source")
+	path := writeConfigFile(t, `quiet_hours:
+  - start: "01:00"
+    end: "02:00"
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if len(cfg.QuietHours) != 1 {
+		t.Fatalf("QuietHours = %v, want the env window only", cfg.QuietHours)
+	}
+	if got, want := cfg.QuietHours[0].String(), "22:30-06:00 America/Chicago: queue vcp,power_source"; got != want {
+		t.Errorf("QuietHours[0] = %q, want %q", got, want)
+	}
+
+	t.Setenv("QUIET_HOURS_ACTION", "mute")
+	t.Setenv("QUIET_HOURS_FIELDS", "vcp,mode")
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	err = cfg.Validate()
+	for _, want := range []string{`QUIET_HOURS_ACTION must be suppress, queue or downgrade, got "mute"`, `QUIET_HOURS_FIELDS: unknown field "mode"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error = %v, want %q", err, want)
+		}
+	}
+
+	for _, bad := range []string{"22:00", "22:00-7", "25:00-07:00"} {
+		t.Setenv("QUIET_HOURS", bad)
+		if _, err := LoadFile(""); err == nil {
+			t.Errorf("QUIET_HOURS=%q: Load() error = nil", bad)
+		}
+	}
+}
+
+func TestQuietHoursFromFile(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, `dry_run: true
+quiet_hours:
+  - start: "22:00"
+    end: "07:00"
+    timezone: America/Los_Angeles
+    fields: [vcp]
+  - start: "00:00"
+    end: "06:00"
+    action: downgrade
+stations:
+  - id: KATX
+    quiet_hours:
+      - start: "23:00"
+        end: "05:00"
+        action: mute
+  - id: KRAX
+    quiet_hours: []
+`)
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	if len(cfg.QuietHours) != 2 || cfg.QuietHours[0].String() != "22:00-07:00 America/Los_Angeles: queue vcp" || cfg.QuietHours[1].Action != QuietDowngrade {
+		t.Errorf("QuietHours = %v", cfg.QuietHours)
+	}
+	if got := cfg.Station("KATX").QuietHours; len(got) != 1 || got[0].Start != 23*time.Hour {
+		t.Errorf("KATX quiet hours = %v, want its own window", got)
+	}
+	if got := cfg.Station("KRAX").QuietHours; len(got) != 0 {
+		t.Errorf("KRAX quiet hours = %v, want none", got)
+	}
+	if got := cfg.Station("KLOT").QuietHours; len(got) != 2 {
+		t.Errorf("KLOT quiet hours = %v, want the global ones", got)
+	}
+
+	err = cfg.Validate()
+	if want := path + `:15: stations[0].quiet_hours[0].action must be suppress, queue or downgrade, got "mute"`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+
+	for _, bad := range []string{
+		"quiet_hours:\n  - start: \"9pm\"\n",
+		"quiet_hours:\n  - timezone: Mars/Olympus_Mons\n",
+	} {
+		if _, err := LoadFile(writeConfigFile(t, bad)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("LoadFile(%q) error = %v, want one naming line 2", bad, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"slices"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// Runtime is station management done while dras is running, through the
+// admin API, layered over the loaded configuration so that it survives a
+// reload. Station IDs are upper-case.
+type Runtime struct {
+	// Added are stations monitored in addition to the configured ones.
+	Added []string `json:"added,omitempty"`
+	// Removed are configured stations that are no longer monitored.
+	Removed []string `json:"removed,omitempty"`
+	// Paused are monitored stations that are not polled until resumed.
+	Paused []string `json:"paused,omitempty"`
+	// Alerts are per-station alert toggles applied over the configured
+	// ones.
+	Alerts map[string]AlertOverride `json:"alerts,omitempty"`
+}
+
+// IsZero reports whether r changes nothing.
+func (r Runtime) IsZero() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Paused) == 0 && len(r.Alerts) == 0
+}
+
+// Clone returns a deep copy of r.
+func (r Runtime) Clone() Runtime {
+	out := Runtime{
+		Added:   slices.Clone(r.Added),
+		Removed: slices.Clone(r.Removed),
+		Paused:  slices.Clone(r.Paused),
+	}
+	if r.Alerts != nil {
+		out.Alerts = make(map[string]AlertOverride, len(r.Alerts))
+		for id, a := range r.Alerts {
+			out.Alerts[id] = a.Merge(AlertOverride{})
+		}
+	}
+	return out
+}
+
+// WithRuntime returns a copy of c with r applied: r's added stations are
+// monitored after the configured ones, its removed stations are not, and
+// its alert toggles and pauses apply to the station's effective settings.
+func (c *Config) WithRuntime(r Runtime) *Config {
+	out := *c
+	out.runtime = r.Clone()
+	return &out
+}
+
+// Runtime returns the runtime station management applied to c.
+func (c *Config) Runtime() Runtime {
+	return c.runtime.Clone()
+}
+
+// configuredStationIDs returns the stations from STATION_IDS or the config
+// file, before runtime changes.
+func (c *Config) configuredStationIDs() []string {
+	if c.DryRun {
+		return append([]string(nil), dryRunStations...)
+	}
+	if c.StationInput != "" {
+		return radar.SanitizeStationIDs(c.StationInput)
+	}
+	ids := make([]string, 0, len(c.Stations))
+	seen := make(map[string]bool, len(c.Stations))
+	for _, st := range c.Stations {
+		if st.ID == "" || seen[st.ID] || !radar.ValidateStationID(st.ID) {
+			continue
+		}
+		seen[st.ID] = true
+		ids = append(ids, st.ID)
+	}
+	return ids
+}
+
+// Merge returns a with every toggle set in b applied on top. The result
+// shares no pointers with a or b.
+func (a AlertOverride) Merge(b AlertOverride) AlertOverride {
+	for _, f := range []struct{ dst, src **bool }{
+		{&a.VCP, &b.VCP},
+		{&a.Status, &b.Status},
+		{&a.Operability, &b.Operability},
+		{&a.PowerSource, &b.PowerSource},
+		{&a.GenState, &b.GenState},
+	} {
+		if *f.dst != nil {
+			v := **f.dst
+			*f.dst = &v
+		}
+		if *f.src != nil {
+			v := **f.src
+			*f.dst = &v
+		}
+	}
+	return a
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestWithRuntime(t *testing.T) {
+	yes, no := true, false
+	base := &Config{
+		StationInput:  "KATX,KRAX,KTLX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, Status: true, Operability: true, PowerSource: true, GenState: true},
+		Stations: []StationOverride{
+			{ID: "KATX", Alerts: AlertOverride{Status: &no}},
+		},
+	}
+	rt := Runtime{
+		Added:   []string{"KFWS", "KATX"},
+		Removed: []string{"KRAX"},
+		Paused:  []string{"KTLX"},
+		Alerts:  map[string]AlertOverride{"KATX": {VCP: &no, PowerSource: &yes}},
+	}
+	cfg := base.WithRuntime(rt)
+
+	if got, want := cfg.StationIDs(), []string{"KATX", "KTLX", "KFWS"}; !slices.Equal(got, want) {
+		t.Errorf("StationIDs() = %v, want %v", got, want)
+	}
+	if got := base.StationIDs(); len(got) != 3 || got[1] != "KRAX" {
+		t.Errorf("base StationIDs() = %v, want it unchanged", got)
+	}
+
+	katx := cfg.Station("KATX").AlertConfig
+	if katx.VCP || katx.Status || !katx.PowerSource || !katx.GenState {
+		t.Errorf("KATX alerts = %+v, want runtime toggles over the file's", katx)
+	}
+	if !cfg.Station("KTLX").Paused || cfg.Station("KATX").Paused {
+		t.Error("want only KTLX paused")
+	}
+
+	// The config keeps its own copy.
+	rt.Paused[0] = "KATX"
+	if !cfg.Station("KTLX").Paused {
+		t.Error("mutating the Runtime changed the config")
+	}
+	if got := cfg.Runtime(); !slices.Equal(got.Removed, []string{"KRAX"}) || *got.Alerts["KATX"].PowerSource != true {
+		t.Errorf("Runtime() = %+v", got)
+	}
+}
+
+func TestAlertOverrideMerge(t *testing.T) {
+	yes, no := true, false
+	a := AlertOverride{VCP: &yes, Status: &yes}
+	got := a.Merge(AlertOverride{Status: &no, GenState: &no})
+	if *got.VCP != true || *got.Status != false || *got.GenState != false || got.PowerSource != nil {
+		t.Errorf("Merge() = %+v", got)
+	}
+	*got.VCP = false
+	if !*a.VCP {
+		t.Error("Merge() result shares pointers with its receiver")
+	}
+}
@@ -0,0 +1,198 @@
+// Package events fans the monitor's station events — startup snapshots,
+// reported changes, poll errors and outages — out to live subscribers such as the
+// HTTP event stream, and keeps the most recent ones for replay.
+//
+// Every Broker method is safe to call on a nil *Broker, so the monitor can
+// publish unconditionally and the stream be switched off by passing nil.
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// Type is the kind of a station event.
+type Type string
+
+const (
+	// Startup is the first data fetched for a station.
+	Startup Type = "startup"
+	// Change is a reported change in a station's radar state.
+	Change Type = "change"
+	// Recovery is a change that brings the radar back to normal.
+	Recovery Type = "recovery"
+	// PollError is a poll that failed, either fetching or notifying.
+	PollError Type = "poll_error"
+	// Outage is a station that stopped reporting.
+	Outage Type = "outage"
+	// Resumed is a station that reports again after an outage.
+	Resumed Type = "resumed"
+)
+
+// DefaultReplaySize is how many events a Broker keeps for replay when
+// NewBroker is given zero.
+const DefaultReplaySize = 100
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it is dropped.
+const subscriberBuffer = 64
+
+// Event is something that happened to a station.
+type Event struct {
+	// ID increases by one with each published event, starting at 1. IDs
+	// restart with the process.
+	ID      uint64         `json:"id"`
+	Type    Type           `json:"type"`
+	Station string         `json:"station"`
+	Time    time.Time      `json:"time"`
+	Old     *radar.Data    `json:"old,omitempty"`
+	New     *radar.Data    `json:"new,omitempty"`
+	Changes []radar.Change `json:"changes,omitempty"`
+	// Error is the poll's error, for PollError events.
+	Error string `json:"error,omitempty"`
+	// Outage is the station's outage, for Outage and Resumed events.
+	Outage *radar.Outage `json:"outage,omitempty"`
+	// Incident is the station's incident, for Change and Recovery events
+	// while one is open or as it ends.
+	Incident *radar.Incident `json:"incident,omitempty"`
+}
+
+// Filter selects the events a subscriber receives.
+type Filter struct {
+	// Stations limits events to these station IDs; empty means all.
+	Stations []string
+	// Replay is how many of the most recent matching events to replay on
+	// subscribing.
+	Replay int
+	// After, when non-zero, replays every kept matching event with a
+	// greater ID instead, e.g. to resume after a reconnect. It wins over
+	// Replay.
+	After uint64
+}
+
+func (f Filter) matches(ev Event) bool {
+	return len(f.Stations) == 0 || slices.Contains(f.Stations, ev.Station)
+}
+
+// Broker publishes events to its subscribers.
+type Broker struct {
+	mu     sync.Mutex
+	size   int
+	lastID uint64
+	// recent holds the last size events, oldest first.
+	recent []Event
+	subs   map[*Subscription]struct{}
+}
+
+// NewBroker returns a Broker that keeps the last replaySize events, or
+// DefaultReplaySize when replaySize is zero.
+func NewBroker(replaySize int) *Broker {
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	return &Broker{size: replaySize, subs: make(map[*Subscription]struct{})}
+}
+
+// Publish assigns ev the next ID and delivers it to every matching
+// subscriber. It never blocks: a subscriber whose buffer is full is dropped
+// and its channel closed, so it can reconnect and resume from the last ID
+// it saw.
+func (b *Broker) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastID++
+	ev.ID = b.lastID
+	if len(b.recent) >= b.size {
+		b.recent = append(b.recent[:0], b.recent[1:]...)
+	}
+	b.recent = append(b.recent, ev)
+
+	for sub := range b.subs {
+		if !sub.filter.matches(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			b.dropLocked(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the events matching f. It returns
+// the subscription and, atomically with it, the kept events f asks to
+// replay, oldest first; later events arrive on the subscription's channel.
+// On a nil Broker the subscription never receives anything.
+func (b *Broker) Subscribe(f Filter) (*Subscription, []Event) {
+	sub := &Subscription{broker: b, filter: f, ch: make(chan Event, subscriberBuffer)}
+	if b == nil {
+		return sub, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[sub] = struct{}{}
+
+	var replay []Event
+	for _, ev := range b.recent {
+		if !f.matches(ev) {
+			continue
+		}
+		if f.After != 0 {
+			if ev.ID > f.After {
+				replay = append(replay, ev)
+			}
+			continue
+		}
+		replay = append(replay, ev)
+	}
+	if f.After == 0 {
+		replay = replay[max(0, len(replay)-f.Replay):]
+	}
+	return sub, replay
+}
+
+// Subscribers returns how many subscriptions are open.
+func (b *Broker) Subscribers() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+func (b *Broker) dropLocked(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscription is a subscriber's view of a Broker.
+type Subscription struct {
+	broker *Broker
+	filter Filter
+	ch     chan Event
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscriber falls too far behind or Close is called.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	if s.broker == nil {
+		return
+	}
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.dropLocked(s)
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"testing"
+)
+
+func ids(evs []Event) []uint64 {
+	out := make([]uint64, len(evs))
+	for i, ev := range evs {
+		out[i] = ev.ID
+	}
+	return out
+}
+
+func TestPublishDeliversMatchingEvents(t *testing.T) {
+	b := NewBroker(0)
+	all, _ := b.Subscribe(Filter{})
+	katx, _ := b.Subscribe(Filter{Stations: []string{"KATX"}})
+
+	b.Publish(Event{Type: Change, Station: "KATX"})
+	b.Publish(Event{Type: PollError, Station: "KRAX"})
+
+	if got := (<-all.Events()).ID; got != 1 {
+		t.Errorf("first event ID = %d, want 1", got)
+	}
+	if got := (<-all.Events()).Station; got != "KRAX" {
+		t.Errorf("second event station = %s, want KRAX", got)
+	}
+	if got := <-katx.Events(); got.Station != "KATX" {
+		t.Errorf("filtered event = %+v, want KATX", got)
+	}
+	select {
+	case ev := <-katx.Events():
+		t.Errorf("KATX subscriber got %+v", ev)
+	default:
+	}
+}
+
+func TestSubscribeReplays(t *testing.T) {
+	b := NewBroker(3)
+	for _, st := range []string{"KATX", "KRAX", "KATX", "KATX"} {
+		b.Publish(Event{Type: Change, Station: st})
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []uint64
+	}{
+		{"none", Filter{}, []uint64{}},
+		{"last two", Filter{Replay: 2}, []uint64{3, 4}},
+		{"more than kept", Filter{Replay: 10}, []uint64{2, 3, 4}},
+		{"filtered", Filter{Stations: []string{"KRAX"}, Replay: 10}, []uint64{2}},
+		{"after", Filter{After: 2, Replay: 1}, []uint64{3, 4}},
+		{"after latest", Filter{After: 4}, []uint64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay := b.Subscribe(tt.filter)
+			defer sub.Close()
+			got := ids(replay)
+			if len(got) != len(tt.want) {
+				t.Fatalf("replay = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("replay = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	b := NewBroker(0)
+	sub, _ := b.Subscribe(Filter{})
+	for range subscriberBuffer + 1 {
+		b.Publish(Event{Type: Change, Station: "KATX"})
+	}
+	if n := b.Subscribers(); n != 0 {
+		t.Fatalf("Subscribers() = %d, want the slow one dropped", n)
+	}
+	n := 0
+	for range sub.Events() {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("drained %d events before close, want %d", n, subscriberBuffer)
+	}
+	sub.Close() // already dropped; must not panic
+}
+
+func TestNilBroker(t *testing.T) {
+	var b *Broker
+	b.Publish(Event{Type: Change})
+	sub, replay := b.Subscribe(Filter{Replay: 5})
+	if replay != nil || b.Subscribers() != 0 {
+		t.Errorf("nil broker replayed %v", replay)
+	}
+	sub.Close()
+}
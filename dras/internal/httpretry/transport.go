@@ -5,8 +5,9 @@
 // cold-starting renderer, transient 5xx from NWS ridge, EOF from a renderer
 // worker that hit OOM mid-request, etc.
 //
-// All requests dras makes to upstream services are GETs; retrying GETs is
-// always safe.
+// Request bodies are buffered and replayed, so the notification backends'
+// POSTs are retried too. A retried POST can deliver a notification twice;
+// for alerts, a duplicate beats a dropped change.
 package httpretry
 
 import (
@@ -17,10 +18,27 @@ import (
 	"log/slog"
 	"math/rand/v2"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync/atomic"
 	"time"
 )
 
+// retryObserver is called before each retry; see SetRetryObserver.
+var retryObserver atomic.Pointer[func(status int)]
+
+// SetRetryObserver registers fn to be called before every retry made by any
+// Transport, with the status code of the failed attempt or 0 when it failed
+// with a network error. It is how retry counts reach the metrics endpoint.
+// A nil fn removes the observer.
+func SetRetryObserver(fn func(status int)) {
+	if fn == nil {
+		retryObserver.Store(nil)
+		return
+	}
+	retryObserver.Store(&fn)
+}
+
 // Transport wraps a base http.RoundTripper with bounded retry-with-backoff
 // for transient failures. Retries are GET-safe (network error, 5xx, 408,
 // 429). Non-transient failures (4xx other than 408/429) and successes are
@@ -162,7 +180,7 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			"attempt", strconv.Itoa(attempt),
 			"max_attempts", strconv.Itoa(maxAttempts),
 			"wait_ms", strconv.FormatInt(wait.Milliseconds(), 10),
-			"url", req.URL.String(),
+			"url", redactURL(req.URL),
 		}
 		if lastErr != nil {
 			attrs = append(attrs, "err", lastErr.Error())
@@ -171,6 +189,13 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			attrs = append(attrs, "status", strconv.Itoa(resp.StatusCode))
 		}
 		slog.Debug("retrying transient HTTP failure", attrs...)
+		if observe := retryObserver.Load(); observe != nil {
+			status := 0
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			(*observe)(status)
+		}
 
 		// Drain and close any previous response body so the connection
 		// can be reused, then release the per-attempt context.
@@ -193,6 +218,16 @@ func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, lastErr
 }
 
+// redactURL returns the URL's scheme and host only. Webhook URLs (Slack,
+// Discord) carry their secret in the path, so a full URL must never reach
+// the logs.
+func redactURL(u *url.URL) string {
+	if u.Path == "" && u.RawQuery == "" {
+		return u.Scheme + "://" + u.Host
+	}
+	return u.Scheme + "://" + u.Host + "/…"
+}
+
 // drainAndClose discards and closes a response body, ignoring errors. The
 // drain lets the underlying connection return to the pool for reuse.
 func drainAndClose(resp *http.Response) {
@@ -1,9 +1,11 @@
 package httpretry
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -378,3 +380,63 @@ func TestRoundTrip_EndToEndRecoversAlternatingFailures(t *testing.T) {
 		t.Errorf("got %d server calls, want 3 (1 EOF + 1 500 + 1 success)", got)
 	}
 }
+
+func TestRoundTrip_ReportsRetriesToObserver(t *testing.T) {
+	var statuses []int
+	SetRetryObserver(func(status int) { statuses = append(statuses, status) })
+	t.Cleanup(func() { SetRetryObserver(nil) })
+
+	stub := &fakeTransport{scripted: []roundTripResult{
+		{statusCode: 503},
+		{err: errors.New("connection reset")},
+		{statusCode: 200, body: "ok"},
+	}}
+	tr := &Transport{
+		Base:           stub,
+		MaxAttempts:    4,
+		InitialBackoff: 1 * time.Millisecond,
+		MaxBackoff:     1 * time.Millisecond,
+	}
+	resp, err := tr.RoundTrip(newRequest(t, context.Background()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if len(statuses) != 2 || statuses[0] != 503 || statuses[1] != 0 {
+		t.Errorf("observed retries %v, want [503 0]", statuses)
+	}
+}
+
+func TestRoundTrip_RetryLogRedactsURL(t *testing.T) {
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	stub := &fakeTransport{scripted: []roundTripResult{
+		{statusCode: 503},
+		{statusCode: 200, body: "ok"},
+	}}
+	tr := &Transport{
+		Base:           stub,
+		MaxAttempts:    2,
+		InitialBackoff: 1 * time.Millisecond,
+		MaxBackoff:     1 * time.Millisecond,
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://hooks.slack.com/services/T000/B000/s3cr3t?x=1", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	got := logs.String()
+	if !strings.Contains(got, "retrying transient HTTP failure") || !strings.Contains(got, "https://hooks.slack.com/…") {
+		t.Errorf("retry log = %q, want the scheme and host", got)
+	}
+	if strings.Contains(got, "s3cr3t") || strings.Contains(got, "/services/") {
+		t.Errorf("retry log = %q, leaks the webhook URL's path", got)
+	}
+}
@@ -0,0 +1,92 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // decode the NWS ridge GIFs
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"time"
+)
+
+// contactCellWidth is the width each image is scaled to on a contact
+// sheet; the default 600x550 ridge image is shown at half size.
+const contactCellWidth = 300
+
+// contactBackground fills the cells of images that could not be decoded
+// and the margin below shorter ones.
+var contactBackground = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+
+// ContactSheet arranges images on a PNG in a grid of about as many columns
+// as rows, left to right and top to bottom, each scaled to the same width.
+// An image that cannot be decoded leaves its cell blank, so the others keep
+// their places. It fails when no image can be decoded.
+func ContactSheet(images []*Image, now time.Time) (*Image, error) {
+	decoded := make([]stdimage.Image, len(images))
+	cellHeight := 0
+	var errs []error
+	for i, img := range images {
+		if img == nil {
+			continue
+		}
+		d, _, err := stdimage.Decode(bytes.NewReader(img.Data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("decode %s image: %w", img.StationID, err))
+			continue
+		}
+		decoded[i] = d
+		b := d.Bounds()
+		if b.Dx() > 0 {
+			cellHeight = max(cellHeight, b.Dy()*contactCellWidth/b.Dx())
+		}
+	}
+	if cellHeight == 0 {
+		errs = append(errs, errors.New("no image to put on a contact sheet"))
+		return nil, errors.Join(errs...)
+	}
+
+	cols := int(math.Ceil(math.Sqrt(float64(len(images)))))
+	rows := (len(images) + cols - 1) / cols
+	sheet := stdimage.NewRGBA(stdimage.Rect(0, 0, cols*contactCellWidth, rows*cellHeight))
+	draw.Draw(sheet, sheet.Bounds(), &stdimage.Uniform{C: contactBackground}, stdimage.Point{}, draw.Src)
+	for i, d := range decoded {
+		if d == nil {
+			continue
+		}
+		origin := stdimage.Pt(i%cols*contactCellWidth, i/cols*cellHeight)
+		scaleInto(sheet, origin, d)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, sheet); err != nil {
+		return nil, fmt.Errorf("encode contact sheet: %w", err)
+	}
+	return &Image{
+		Data:        buf.Bytes(),
+		ContentType: "image/png",
+		Filename:    fmt.Sprintf("digest_%s.png", now.UTC().Format("20060102T150405Z")),
+		FetchedAt:   now,
+	}, nil
+}
+
+// scaleInto draws src onto dst at origin, scaled with nearest-neighbour
+// sampling to contactCellWidth wide, keeping its aspect ratio.
+func scaleInto(dst *stdimage.RGBA, origin stdimage.Point, src stdimage.Image) {
+	b := src.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return
+	}
+	height := b.Dy() * contactCellWidth / b.Dx()
+	for y := range height {
+		sy := b.Min.Y + y*b.Dy()/height
+		for x := range contactCellWidth {
+			sx := b.Min.X + x*b.Dx()/contactCellWidth
+			dst.Set(origin.X+x, origin.Y+y, src.At(sx, sy))
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package image
+
+import (
+	"bytes"
+	stdimage "image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// solidGIF returns a GIF of the given size filled with c.
+func solidGIF(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := stdimage.NewPaletted(stdimage.Rect(0, 0, w, h), palette.Plan9)
+	idx := uint8(img.Palette.Index(c))
+	for i := range img.Pix {
+		img.Pix[i] = idx
+	}
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestContactSheet(t *testing.T) {
+	red, blue := color.RGBA{R: 0xff, A: 0xff}, color.RGBA{B: 0xff, A: 0xff}
+	images := []*Image{
+		{StationID: "KATX", Data: solidGIF(t, 600, 550, red)},
+		{StationID: "KRAX", Data: []byte("not an image")},
+		nil,
+		{StationID: "KLOT", Data: solidGIF(t, 600, 300, blue)},
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	sheet, err := ContactSheet(images, now)
+	if err != nil {
+		t.Fatalf("ContactSheet() error: %v", err)
+	}
+	if sheet.ContentType != "image/png" || sheet.Filename != "digest_20261016T120000Z.png" {
+		t.Errorf("sheet = %s %s", sheet.ContentType, sheet.Filename)
+	}
+	got, err := png.Decode(bytes.NewReader(sheet.Data))
+	if err != nil {
+		t.Fatalf("decode sheet: %v", err)
+	}
+	// Two columns of 300px; rows as tall as the tallest scaled image.
+	if b := got.Bounds(); b.Dx() != 600 || b.Dy() != 550 {
+		t.Fatalf("sheet is %dx%d, want 600x550", b.Dx(), b.Dy())
+	}
+	for _, tt := range []struct {
+		x, y int
+		want color.Color
+	}{
+		{10, 10, red},
+		{310, 10, contactBackground},  // undecodable
+		{10, 285, contactBackground},  // nil
+		{310, 285, blue},              // KLOT, 150px tall
+		{310, 500, contactBackground}, // below KLOT
+	} {
+		r, g, b, a := got.At(tt.x, tt.y).RGBA()
+		wr, wg, wb, wa := tt.want.RGBA()
+		if r != wr || g != wg || b != wb || a != wa {
+			t.Errorf("pixel (%d,%d) = %v, want %v", tt.x, tt.y, got.At(tt.x, tt.y), tt.want)
+		}
+	}
+
+	if _, err := ContactSheet([]*Image{{StationID: "KATX", Data: []byte("x")}}, now); err == nil {
+		t.Error("ContactSheet() error = nil with nothing to decode")
+	}
+}
@@ -0,0 +1,392 @@
+// Package message renders notification titles and bodies from Go
+// text/template templates, so users can word notifications their own way.
+package message
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// Kind selects which template a notification is rendered with.
+type Kind string
+
+const (
+	// Startup is the first notification for a station.
+	Startup Kind = "startup"
+	// Change is a notification for detected changes.
+	Change Kind = "change"
+	// Recovery is a change notification whose changes bring the radar back
+	// to normal (see radar.Recovered).
+	Recovery Kind = "recovery"
+	// Outage is a notification that a station stopped reporting (see
+	// radar.Outage).
+	Outage Kind = "outage"
+	// Resumed is a notification that a station reports again after an
+	// outage.
+	Resumed Kind = "resumed"
+	// Digest is the scheduled summary of every monitored station.
+	Digest Kind = "digest"
+	// Batch is several notifications sent as one: those of a poll round
+	// that reached the batching threshold, or those held back by the rate
+	// limit.
+	Batch Kind = "batch"
+)
+
+// Template is the source of one notification's title and body templates.
+type Template struct {
+	Title string
+	Body  string
+}
+
+// Config holds the template sources for each kind of notification. An empty
+// field uses its default; an empty Recovery field falls back to the
+// corresponding Change field, so customising the change templates covers
+// recoveries too.
+type Config struct {
+	Startup  Template
+	Change   Template
+	Recovery Template
+	Outage   Template
+	Resumed  Template
+	Digest   Template
+	Batch    Template
+}
+
+// Defaults are the built-in templates.
+var Defaults = Config{
+	Startup: Template{
+		Title: "DRAS Startup",
+		Body:  "{{.StationID}} {{.StationName}} - {{.New.Mode}} Mode",
+	},
+	Change: Template{
+		Title: "{{.StationID}} Update",
+		Body:  "{{.Summary}}",
+	},
+	Outage: Template{
+		Title: "{{.StationID}} Not Reporting",
+		Body:  "{{.Summary}}",
+	},
+	Resumed: Template{
+		Title: "{{.StationID}} Reporting Again",
+		Body:  "{{.Summary}}",
+	},
+	Digest: Template{
+		Title: "DRAS Digest",
+		Body:  "{{.Summary}}",
+	},
+	Batch: Template{
+		Title: "DRAS: {{len .Notifications}} Notifications",
+		Body:  "{{.Summary}}",
+	},
+}
+
+// Data is what templates are executed with. A digest has no station of its
+// own: only Stations, Since, Summary, Severity and Time are set; a batch
+// only Notifications, Since, Summary, Severity and Time.
+type Data struct {
+	StationID   string
+	StationName string
+	// Old is the previous radar data (nil for startup) and New the current
+	// (for an outage, the last data fetched, which may be nil).
+	Old *radar.Data
+	New *radar.Data
+	// VCPInfo describes New's VCP and OldVCPInfo Old's. A VCP that is not in
+	// the catalog gets radar.GetVCPInfo's "Unknown" fallback.
+	VCPInfo    radar.VCPInfo
+	OldVCPInfo radar.VCPInfo
+	// Changes lists each detected change and Summary is their Text, one per
+	// line (change and recovery only). For an outage or its end, Summary is
+	// the Outage's Text, and for a digest each station's Text, separated by
+	// blank lines.
+	Changes []radar.Change
+	Summary string
+	// Outage is the outage reported (outage and resumed only).
+	Outage *radar.Outage
+	// Incident is the station's incident while one is open, or the one the
+	// changes end (change and recovery only; see NewIncidentData).
+	Incident *radar.Incident
+	// QueuedSince is when the first of Changes was held back for quiet
+	// hours, for changes sent once the quiet hours are over; zero
+	// otherwise.
+	QueuedSince time.Time
+	// Stations are the monitored stations, in configuration order, and
+	// Since the start of the period their changes were reported over
+	// (digest only). For a batch of notifications held back by the rate
+	// limit, Since is when the first was held; it is zero for a poll
+	// round's batch.
+	Stations []radar.StationSummary
+	Since    time.Time
+	// Notifications are the notifications a batch stands for, in the
+	// order they were due (batch only).
+	Notifications []Notification
+	// Severity is the highest severity among Changes (for a digest, among
+	// all the stations' changes); critical for an outage and info once it
+	// is over.
+	Severity radar.Severity
+	// Time is when the data was fetched.
+	Time time.Time
+}
+
+// NewData builds the template data for a station's old and new radar data.
+// oldData and changes are nil for a startup notification.
+func NewData(stationID string, oldData, newData *radar.Data, changes []radar.Change, now time.Time) Data {
+	d := Data{
+		StationID: stationID,
+		Old:       oldData,
+		New:       newData,
+		Changes:   changes,
+		Summary:   radar.JoinText(changes),
+		Severity:  radar.MaxSeverity(changes),
+		Time:      now,
+	}
+	if newData != nil {
+		d.StationName = newData.Name
+		d.VCPInfo, _ = radar.GetVCPInfo(newData.VCP)
+	}
+	if oldData != nil {
+		d.OldVCPInfo, _ = radar.GetVCPInfo(oldData.VCP)
+	}
+	return d
+}
+
+// NewOutageData builds the template data for a station's outage, or its
+// end once outage.Ended is set. last is the last data fetched, if any.
+func NewOutageData(stationID string, last *radar.Data, outage radar.Outage, now time.Time) Data {
+	d := NewData(stationID, nil, last, nil, now)
+	d.Outage = &outage
+	d.Summary = outage.Text()
+	d.Severity = radar.SeverityCritical
+	if !outage.Ended.IsZero() {
+		d.Severity = radar.SeverityInfo
+	}
+	return d
+}
+
+// NewQueuedData is NewIncidentData for changes held back during quiet hours
+// since the given time and sent now. Summary starts with a line saying so.
+// incident is the one the changes ended, if any.
+func NewQueuedData(stationID string, oldData, newData *radar.Data, changes []radar.Change, incident *radar.Incident, since, now time.Time) Data {
+	d := NewIncidentData(stationID, oldData, newData, changes, incident, now)
+	d.QueuedSince = since
+	d.Summary = fmt.Sprintf("Held during quiet hours since %s:\n%s", since.UTC().Format("2006-01-02 15:04 MST"), d.Summary)
+	return d
+}
+
+// NewIncidentData is NewData for changes reported while the station has an
+// incident open, or that end it. Once the incident has ended its Text — the
+// outage duration and the states the radar went through — is appended to
+// Summary.
+func NewIncidentData(stationID string, oldData, newData *radar.Data, changes []radar.Change, incident *radar.Incident, now time.Time) Data {
+	d := NewData(stationID, oldData, newData, changes, now)
+	d.Incident = incident
+	if incident != nil && !incident.Open() {
+		d.Summary += "\n" + incident.Text()
+	}
+	return d
+}
+
+// NewDigestData builds the template data for a digest of stations' changes
+// since the given time.
+func NewDigestData(stations []radar.StationSummary, since, now time.Time) Data {
+	texts := make([]string, len(stations))
+	var changes []radar.Change
+	for i, s := range stations {
+		texts[i] = s.Text()
+		changes = append(changes, s.Changes...)
+	}
+	return Data{
+		Stations: stations,
+		Since:    since,
+		Summary:  strings.Join(texts, "\n\n"),
+		Severity: radar.MaxSeverity(changes),
+		Time:     now,
+	}
+}
+
+// Notification is one of the notifications in a batch, as it would have
+// been sent on its own.
+type Notification struct {
+	StationID string
+	Title     string
+	Body      string
+	Severity  radar.Severity
+}
+
+// NewBatchData builds the template data for a batch of notifications.
+// since is when the first of them was held back by the rate limit, or zero
+// for a poll round's batch. Summary has a line per notification, "Title:
+// Body", after one saying they were held back, if they were.
+func NewBatchData(notifications []Notification, since, now time.Time) Data {
+	lines := make([]string, 0, len(notifications)+1)
+	if !since.IsZero() {
+		lines = append(lines, fmt.Sprintf("Held back by the rate limit since %s:", since.UTC().Format("2006-01-02 15:04 MST")))
+	}
+	sev := radar.SeverityInfo
+	for _, n := range notifications {
+		lines = append(lines, n.Title+": "+strings.ReplaceAll(n.Body, "\n", "\n  "))
+		sev = sev.Higher(n.Severity)
+	}
+	return Data{
+		Notifications: notifications,
+		Since:         since,
+		Summary:       strings.Join(lines, "\n"),
+		Severity:      sev,
+		Time:          now,
+	}
+}
+
+// Error reports a template that failed to parse or to render.
+type Error struct {
+	Kind Kind
+	// Part is "title" or "body".
+	Part string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s template: %v", e.Kind, e.Part, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Templates are parsed, ready-to-render templates.
+type Templates struct {
+	byKind map[Kind]*pair
+}
+
+type pair struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// Parse parses the templates in cfg, filling empty fields with their
+// defaults, and renders each against sample data so that a template
+// referring to a field that doesn't exist, or one that isn't set for its
+// kind (such as .Old in a startup template), fails here rather than when a
+// notification is due. The error is an *Error naming the first bad template.
+func Parse(cfg Config) (*Templates, error) {
+	withDefaults := func(t, def Template) Template {
+		if t.Title == "" {
+			t.Title = def.Title
+		}
+		if t.Body == "" {
+			t.Body = def.Body
+		}
+		return t
+	}
+	change := withDefaults(cfg.Change, Defaults.Change)
+	sources := map[Kind]Template{
+		Startup:  withDefaults(cfg.Startup, Defaults.Startup),
+		Change:   change,
+		Recovery: withDefaults(cfg.Recovery, change),
+		Outage:   withDefaults(cfg.Outage, Defaults.Outage),
+		Resumed:  withDefaults(cfg.Resumed, Defaults.Resumed),
+		Digest:   withDefaults(cfg.Digest, Defaults.Digest),
+		Batch:    withDefaults(cfg.Batch, Defaults.Batch),
+	}
+
+	t := &Templates{byKind: make(map[Kind]*pair, len(sources))}
+	for _, kind := range []Kind{Startup, Change, Recovery, Outage, Resumed, Digest, Batch} {
+		src := sources[kind]
+		p := &pair{}
+		var err error
+		if p.title, err = template.New(string(kind) + " title").Parse(src.Title); err != nil {
+			return nil, &Error{Kind: kind, Part: "title", Err: err}
+		}
+		if p.body, err = template.New(string(kind) + " body").Parse(src.Body); err != nil {
+			return nil, &Error{Kind: kind, Part: "body", Err: err}
+		}
+		t.byKind[kind] = p
+		if _, _, err := t.Render(kind, sampleData(kind)); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for the built-in
+// defaults, which always parse.
+func MustParse(cfg Config) *Templates {
+	t, err := Parse(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render executes the title and body templates for kind with d. Surrounding
+// whitespace is trimmed, so templates can be written over several lines.
+func (t *Templates) Render(kind Kind, d Data) (title, body string, err error) {
+	p, ok := t.byKind[kind]
+	if !ok {
+		return "", "", fmt.Errorf("unknown message kind %q", kind)
+	}
+	if title, err = execute(p.title, d); err != nil {
+		return "", "", &Error{Kind: kind, Part: "title", Err: err}
+	}
+	if body, err = execute(p.body, d); err != nil {
+		return "", "", &Error{Kind: kind, Part: "body", Err: err}
+	}
+	return title, body, nil
+}
+
+func execute(tmpl *template.Template, d Data) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, d); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// sampleData is representative data for kind, used by Parse to catch
+// templates that only fail when executed.
+func sampleData(kind Kind) Data {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	current := &radar.Data{
+		Name:              "Seattle/Tacoma",
+		VCP:               "R212",
+		Mode:              "Precipitation",
+		Status:            "Operate",
+		OperabilityStatus: "RDA - On-line",
+		PowerSource:       "Commercial Utility",
+		GenState:          "Off",
+	}
+	switch kind {
+	case Startup:
+		return NewData("KATX", nil, current, nil, now)
+	case Outage, Resumed:
+		outage := radar.Outage{Reason: radar.OutageUnreachable, Since: now.Add(-time.Hour), Failures: 3, LastError: "NWS API returned status 503"}
+		if kind == Resumed {
+			outage.Ended = now
+		}
+		return NewOutageData("KATX", current, outage, now)
+	case Digest:
+		changes := radar.Diff(&radar.Data{VCP: "R31", Mode: "Clear Air"}, current, radar.AlertConfig{VCP: true})
+		return NewDigestData([]radar.StationSummary{{StationID: "KATX", Data: current, Changes: changes}}, now.Add(-24*time.Hour), now)
+	case Batch:
+		return NewBatchData([]Notification{
+			{StationID: "KATX", Title: "KATX Update", Body: "Precipitation Mode Active", Severity: radar.SeverityInfo},
+			{StationID: "KRAX", Title: "KRAX Not Reporting", Body: "Radar data could not be fetched 3 times in a row", Severity: radar.SeverityCritical},
+		}, now.Add(-time.Hour), now)
+	}
+	previous := *current
+	if kind == Recovery {
+		previous.Status = "Standby"
+		incident := &radar.Incident{
+			Started: now.Add(-time.Hour),
+			Ended:   now,
+			States:  []radar.IncidentState{{Time: now.Add(-time.Hour), Status: "Standby", OperabilityStatus: "RDA - On-line", Severity: radar.SeverityCritical}},
+		}
+		changes := radar.Diff(&previous, current, radar.AlertConfig{Status: true})
+		return NewIncidentData("KATX", &previous, current, changes, incident, now)
+	}
+	previous.VCP, previous.Mode = "R31", "Clear Air"
+	changes := radar.Diff(&previous, current, radar.AlertConfig{VCP: true})
+	return NewData("KATX", &previous, current, changes, now)
+}
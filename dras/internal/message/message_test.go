@@ -0,0 +1,206 @@
+package message
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+var (
+	oldKATX = &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate", PowerSource: "Commercial Utility"}
+	newKATX = &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation", Status: "Operate", PowerSource: "Commercial Utility"}
+)
+
+func TestDefaultsMatchBuiltInMessages(t *testing.T) {
+	tmpl := MustParse(Config{})
+	now := time.Now()
+
+	title, body, err := tmpl.Render(Startup, NewData("KATX", nil, newKATX, nil, now))
+	if err != nil {
+		t.Fatalf("Render(startup) error: %v", err)
+	}
+	if title != "DRAS Startup" || body != "KATX Seattle - Precipitation Mode" {
+		t.Errorf("startup = %q / %q", title, body)
+	}
+
+	changes := radar.Diff(oldKATX, newKATX, radar.AlertConfig{VCP: true, Status: true})
+	title, body, err = tmpl.Render(Change, NewData("KATX", oldKATX, newKATX, changes, now))
+	if err != nil {
+		t.Fatalf("Render(change) error: %v", err)
+	}
+	if title != "KATX Update" || body != radar.JoinText(changes) {
+		t.Errorf("change = %q / %q", title, body)
+	}
+}
+
+func TestOutageTemplates(t *testing.T) {
+	since := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	outage := radar.Outage{Reason: radar.OutageUnreachable, Since: since, Failures: 3, LastError: "timeout"}
+
+	title, body, err := MustParse(Config{}).Render(Outage, NewOutageData("KATX", nil, outage, since.Add(time.Hour)))
+	if err != nil {
+		t.Fatalf("Render(outage) error: %v", err)
+	}
+	if title != "KATX Not Reporting" || body != outage.Text() {
+		t.Errorf("outage = %q / %q", title, body)
+	}
+
+	tmpl, err := Parse(Config{Resumed: Template{Body: "{{.StationName}} back ({{.Outage.Reason}}, {{.Severity}})"}})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	outage.Ended = since.Add(time.Hour)
+	title, body, err = tmpl.Render(Resumed, NewOutageData("KATX", newKATX, outage, outage.Ended))
+	if err != nil {
+		t.Fatalf("Render(resumed) error: %v", err)
+	}
+	if title != "KATX Reporting Again" || body != "Seattle back (unreachable, info)" {
+		t.Errorf("resumed = %q / %q", title, body)
+	}
+}
+
+func TestDigestTemplates(t *testing.T) {
+	at := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	changes := radar.Diff(oldKATX, newKATX, radar.AlertConfig{VCP: true})
+	stations := []radar.StationSummary{
+		{StationID: "KATX", Data: newKATX, Changes: changes},
+		{StationID: "KRAX"},
+	}
+	d := NewDigestData(stations, at.Add(-24*time.Hour), at)
+
+	title, body, err := MustParse(Config{}).Render(Digest, d)
+	if err != nil {
+		t.Fatalf("Render(digest) error: %v", err)
+	}
+	if want := stations[0].Text() + "\n\n" + stations[1].Text(); title != "DRAS Digest" || body != want {
+		t.Errorf("digest = %q / %q, want body %q", title, body, want)
+	}
+
+	tmpl, err := Parse(Config{Digest: Template{
+		Title: "Radars since {{.Since.Format \"Jan 2 15:04\"}}",
+		Body:  "{{range .Stations}}{{.StationID}}: {{len .Changes}} {{end}}",
+	}})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if title, body, _ = tmpl.Render(Digest, d); title != "Radars since Apr 30 07:00" || body != "KATX: 1 KRAX: 0" {
+		t.Errorf("custom digest = %q / %q", title, body)
+	}
+}
+
+func TestBatchTemplates(t *testing.T) {
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	notifications := []Notification{
+		{StationID: "KATX", Title: "KATX Update", Body: "Precipitation Mode Active\nRadar status changed from Operate to Standby", Severity: radar.SeverityCritical},
+		{StationID: "KRAX", Title: "KRAX Update", Body: "Precipitation Mode Active", Severity: radar.SeverityInfo},
+	}
+
+	d := NewBatchData(notifications, time.Time{}, at)
+	title, body, err := MustParse(Config{}).Render(Batch, d)
+	if err != nil {
+		t.Fatalf("Render(batch) error: %v", err)
+	}
+	want := "KATX Update: Precipitation Mode Active\n  Radar status changed from Operate to Standby\nKRAX Update: Precipitation Mode Active"
+	if title != "DRAS: 2 Notifications" || body != want || d.Severity != radar.SeverityCritical {
+		t.Errorf("batch = %q / %q (%s), want body %q", title, body, d.Severity, want)
+	}
+
+	held := NewBatchData(notifications[1:], at.Add(-time.Hour), at)
+	if want := "Held back by the rate limit since 2024-05-01 08:00 UTC:\nKRAX Update: Precipitation Mode Active"; held.Summary != want {
+		t.Errorf("held summary = %q, want %q", held.Summary, want)
+	}
+}
+
+func TestCustomTemplates(t *testing.T) {
+	tmpl, err := Parse(Config{
+		Change: Template{
+			Title: "{{.StationID}}: {{.OldVCPInfo.Mode}} -> {{.VCPInfo.Mode}}",
+			Body: `
+{{range .Changes}}[{{.Severity}}] {{.Field}}: {{.Old}} -> {{.New}}
+{{end}}at {{.Time.Format "15:04"}}`,
+		},
+		Recovery: Template{Title: "{{.StationID}} recovered"},
+	})
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	changes := radar.Diff(oldKATX, newKATX, radar.AlertConfig{VCP: true})
+	title, body, err := tmpl.Render(Change, NewData("KATX", oldKATX, newKATX, changes, at))
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if title != "KATX: Clear Air -> Precipitation" {
+		t.Errorf("title = %q", title)
+	}
+	if want := "[info] vcp: R31 -> R12\nat 09:30"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	// Recovery overrides the title only; its body falls back to the custom
+	// change body rather than the default.
+	title, body, err = tmpl.Render(Recovery, NewData("KATX", oldKATX, newKATX, changes, at))
+	if err != nil {
+		t.Fatalf("Render(recovery) error: %v", err)
+	}
+	if title != "KATX recovered" || !strings.HasPrefix(body, "[info] vcp") {
+		t.Errorf("recovery = %q / %q", title, body)
+	}
+}
+
+func TestParseRejectsBadTemplates(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantKind Kind
+		wantPart string
+	}{
+		{
+			name:     "syntax error",
+			cfg:      Config{Change: Template{Body: "{{.Summary"}},
+			wantKind: Change,
+			wantPart: "body",
+		},
+		{
+			name:     "unknown field",
+			cfg:      Config{Startup: Template{Title: "{{.Station}}"}},
+			wantKind: Startup,
+			wantPart: "title",
+		},
+		{
+			name:     "no previous data at startup",
+			cfg:      Config{Startup: Template{Body: "{{.Old.VCP}}"}},
+			wantKind: Startup,
+			wantPart: "body",
+		},
+		{
+			name:     "no station in a digest",
+			cfg:      Config{Digest: Template{Title: "{{.New.VCP}}"}},
+			wantKind: Digest,
+			wantPart: "title",
+		},
+		{
+			name:     "bad recovery template",
+			cfg:      Config{Recovery: Template{Body: "{{range}}"}},
+			wantKind: Recovery,
+			wantPart: "body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.cfg)
+			var te *Error
+			if !errors.As(err, &te) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if te.Kind != tt.wantKind || te.Part != tt.wantPart {
+				t.Errorf("error for %s %s, want %s %s: %v", te.Kind, te.Part, tt.wantKind, tt.wantPart, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package metrics exposes the orchestrator's Prometheus metrics: station
+// polls, NWS fetch latency and cache use, detected changes, notification
+// deliveries and Pushover emergency receipts, radar image fetches, HTTP
+// retries and the current radar state per station.
+//
+// Every method is safe to call on a nil *Metrics, so collaborators can be
+// instrumented unconditionally and metrics switched off by passing nil.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dras"
+
+// Poll outcomes, the "outcome" label of dras_polls_total.
+const (
+	PollStartup     = "startup"
+	PollUnchanged   = "unchanged"
+	PollChanged     = "changed"
+	PollFetchError  = "fetch_error"
+	PollNotifyError = "notify_error"
+	PollError       = "error"
+)
+
+// Metrics holds the orchestrator's collectors on a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	polls         *prometheus.CounterVec
+	fetchDuration *prometheus.HistogramVec
+	nwsCache      *prometheus.CounterVec
+	changes       *prometheus.CounterVec
+	notifications *prometheus.CounterVec
+	imageFetches  *prometheus.CounterVec
+	imageBytes    prometheus.Histogram
+	retries       *prometheus.CounterVec
+	receipts      *prometheus.CounterVec
+	radarInfo     *prometheus.GaugeVec
+
+	// radarLabels is the label set each station's radarInfo series was
+	// last set with, so a change replaces the series instead of adding one.
+	mu          sync.Mutex
+	radarLabels map[string]prometheus.Labels
+}
+
+// New creates the collectors and registers them, together with the Go
+// runtime and process collectors, on a new registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		polls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "polls_total",
+			Help:      "Station polls by outcome.",
+		}, []string{"station", "outcome"}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Time taken to fetch a station's radar data from the NWS API.",
+			Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"station"}),
+		nwsCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "nws_cache_total",
+			Help:      "NWS station data fetches by cache result: hit (no request), revalidated (304 Not Modified) or miss (full download).",
+		}, []string{"result"}),
+		changes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "changes_total",
+			Help:      "Reported radar changes by field.",
+		}, []string{"station", "field"}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_total",
+			Help:      "Notification deliveries by backend and outcome (sent or failed).",
+		}, []string{"backend", "outcome"}),
+		imageFetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "image_fetches_total",
+			Help:      "Radar image fetches by outcome (success or error).",
+		}, []string{"outcome"}),
+		imageBytes: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "image_size_bytes",
+			Help:      "Size of fetched radar images.",
+			Buckets:   prometheus.ExponentialBuckets(16<<10, 4, 6), // 16KiB .. 16MiB
+		}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_retries_total",
+			Help:      "Outbound HTTP requests retried, by the status of the failed attempt (\"error\" for network errors).",
+		}, []string{"status"}),
+		receipts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pushover_receipts_total",
+			Help:      "Pushover emergency-priority messages by receipt outcome: sent, acknowledged or expired.",
+		}, []string{"outcome"}),
+		radarInfo: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "radar_info",
+			Help:      "Current radar state per station; always 1, the state is in the labels.",
+		}, []string{"station", "vcp", "mode", "status"}),
+		radarLabels: make(map[string]prometheus.Labels),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.polls, m.fetchDuration, m.nwsCache, m.changes, m.notifications,
+		m.imageFetches, m.imageBytes, m.retries, m.receipts, m.radarInfo,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObservePoll counts a station poll with one of the Poll* outcomes.
+func (m *Metrics) ObservePoll(station, outcome string) {
+	if m == nil {
+		return
+	}
+	m.polls.WithLabelValues(station, outcome).Inc()
+}
+
+// ObserveFetch records how long a FetchData call for the station took.
+func (m *Metrics) ObserveFetch(station string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.fetchDuration.WithLabelValues(station).Observe(d.Seconds())
+}
+
+// ObserveNWSCache counts a station data fetch by how it used the cache. Its
+// signature matches radar.Config.OnCache.
+func (m *Metrics) ObserveNWSCache(result radar.CacheResult) {
+	if m == nil {
+		return
+	}
+	m.nwsCache.WithLabelValues(string(result)).Inc()
+}
+
+// ObserveChanges counts each reported change by field.
+func (m *Metrics) ObserveChanges(station string, changes []radar.Change) {
+	if m == nil {
+		return
+	}
+	for _, c := range changes {
+		m.changes.WithLabelValues(station, string(c.Field)).Inc()
+	}
+}
+
+// ObserveNotification counts a delivery attempt to backend.
+func (m *Metrics) ObserveNotification(backend string, err error) {
+	if m == nil {
+		return
+	}
+	outcome := "sent"
+	if err != nil {
+		outcome = "failed"
+	}
+	m.notifications.WithLabelValues(backend, outcome).Inc()
+}
+
+// ObserveImage counts a radar image fetch and, on success, records its size.
+func (m *Metrics) ObserveImage(size int, err error) {
+	if m == nil {
+		return
+	}
+	if err != nil {
+		m.imageFetches.WithLabelValues("error").Inc()
+		return
+	}
+	m.imageFetches.WithLabelValues("success").Inc()
+	m.imageBytes.Observe(float64(size))
+}
+
+// ObserveRetry counts a retried HTTP request. status is the failed
+// attempt's status code, or 0 for a network error. Its signature matches
+// httpretry.SetRetryObserver.
+func (m *Metrics) ObserveRetry(status int) {
+	if m == nil {
+		return
+	}
+	label := "error"
+	if status != 0 {
+		label = strconv.Itoa(status)
+	}
+	m.retries.WithLabelValues(label).Inc()
+}
+
+// ObserveReceipt counts a Pushover emergency receipt outcome. Its
+// signature matches notify.SetReceiptObserver.
+func (m *Metrics) ObserveReceipt(outcome string) {
+	if m == nil {
+		return
+	}
+	m.receipts.WithLabelValues(outcome).Inc()
+}
+
+// SetRadarState sets the station's radar_info series to data's state,
+// replacing the one for its previous state.
+func (m *Metrics) SetRadarState(station string, data *radar.Data) {
+	if m == nil || data == nil {
+		return
+	}
+	labels := prometheus.Labels{
+		"station": station,
+		"vcp":     data.VCP,
+		"mode":    data.Mode,
+		"status":  data.Status,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if prev, ok := m.radarLabels[station]; ok {
+		m.radarInfo.Delete(prev)
+	}
+	m.radarInfo.With(labels).Set(1)
+	m.radarLabels[station] = labels
+}
+
+// ForgetStation drops the station's radar_info series, e.g. once it is no
+// longer monitored.
+func (m *Metrics) ForgetStation(station string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if prev, ok := m.radarLabels[station]; ok {
+		m.radarInfo.Delete(prev)
+		delete(m.radarLabels, station)
+	}
+}
+
+// Notifier wraps n so that each delivery is counted under backend. It
+// returns n itself when m is nil.
+func (m *Metrics) Notifier(backend string, n notify.Notifier) notify.Notifier {
+	if m == nil {
+		return n
+	}
+	return &countingNotifier{Notifier: n, backend: backend, metrics: m}
+}
+
+// countingNotifier counts the deliveries of the notifier it wraps. Events
+// are passed on through notify.Send so the wrapped notifier still receives
+// them structured when it can.
+type countingNotifier struct {
+	notify.Notifier
+	backend string
+	metrics *Metrics
+}
+
+func (c *countingNotifier) SendNotification(ctx context.Context, title, message string) error {
+	err := c.Notifier.SendNotification(ctx, title, message)
+	c.metrics.ObserveNotification(c.backend, err)
+	return err
+}
+
+func (c *countingNotifier) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *notify.Attachment) error {
+	err := c.Notifier.SendNotificationWithAttachment(ctx, title, message, attachment)
+	c.metrics.ObserveNotification(c.backend, err)
+	return err
+}
+
+func (c *countingNotifier) SendEvent(ctx context.Context, ev notify.Event) error {
+	err := notify.Send(ctx, c.Notifier, ev)
+	c.metrics.ObserveNotification(c.backend, err)
+	return err
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNilMetricsIsANoOp(t *testing.T) {
+	var m *Metrics
+	m.ObservePoll("KATX", PollChanged)
+	m.ObserveFetch("KATX", time.Second)
+	m.ObserveChanges("KATX", []radar.Change{{Field: radar.FieldVCP}})
+	m.ObserveNotification("pushover", nil)
+	m.ObserveImage(1024, nil)
+	m.ObserveRetry(503)
+	m.ObserveReceipt(notify.ReceiptSent)
+	m.ObserveNWSCache(radar.CacheHit)
+	m.SetRadarState("KATX", &radar.Data{VCP: "R35"})
+	m.ForgetStation("KATX")
+
+	n := notify.NewMockNotifier()
+	if got := m.Notifier("pushover", n); got != notify.Notifier(n) {
+		t.Errorf("Notifier() = %T, want the notifier unwrapped", got)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	m := New()
+	m.ObservePoll("KATX", PollChanged)
+	m.ObservePoll("KATX", PollChanged)
+	m.ObservePoll("KATX", PollFetchError)
+	m.ObserveChanges("KATX", []radar.Change{{Field: radar.FieldVCP}, {Field: radar.FieldStatus}})
+	m.ObserveImage(40_000, nil)
+	m.ObserveImage(0, errors.New("timeout"))
+	m.ObserveRetry(503)
+	m.ObserveRetry(0)
+	m.ObserveReceipt(notify.ReceiptSent)
+	m.ObserveReceipt(notify.ReceiptAcknowledged)
+	m.ObserveNWSCache(radar.CacheRevalidated)
+	m.ObserveNWSCache(radar.CacheRevalidated)
+	m.ObserveNWSCache(radar.CacheMiss)
+
+	for _, tt := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"changed polls", testutil.ToFloat64(m.polls.WithLabelValues("KATX", PollChanged)), 2},
+		{"failed polls", testutil.ToFloat64(m.polls.WithLabelValues("KATX", PollFetchError)), 1},
+		{"vcp changes", testutil.ToFloat64(m.changes.WithLabelValues("KATX", "vcp")), 1},
+		{"image successes", testutil.ToFloat64(m.imageFetches.WithLabelValues("success")), 1},
+		{"image errors", testutil.ToFloat64(m.imageFetches.WithLabelValues("error")), 1},
+		{"503 retries", testutil.ToFloat64(m.retries.WithLabelValues("503")), 1},
+		{"network retries", testutil.ToFloat64(m.retries.WithLabelValues("error")), 1},
+		{"emergency receipts", testutil.ToFloat64(m.receipts.WithLabelValues("sent")), 1},
+		{"acknowledged receipts", testutil.ToFloat64(m.receipts.WithLabelValues("acknowledged")), 1},
+		{"revalidated fetches", testutil.ToFloat64(m.nwsCache.WithLabelValues("revalidated")), 2},
+		{"cache misses", testutil.ToFloat64(m.nwsCache.WithLabelValues("miss")), 1},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSetRadarStateReplacesPreviousSeries(t *testing.T) {
+	m := New()
+	m.SetRadarState("KATX", &radar.Data{VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+	m.SetRadarState("KATX", &radar.Data{VCP: "R215", Mode: "Precipitation", Status: "Operate"})
+	m.SetRadarState("KRAX", &radar.Data{VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+
+	want := `
+# HELP dras_radar_info Current radar state per station; always 1, the state is in the labels.
+# TYPE dras_radar_info gauge
+dras_radar_info{mode="Clear Air",station="KRAX",status="Operate",vcp="R35"} 1
+dras_radar_info{mode="Precipitation",station="KATX",status="Operate",vcp="R215"} 1
+`
+	if err := testutil.CollectAndCompare(m.radarInfo, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	m.ForgetStation("KATX")
+	if n := testutil.CollectAndCount(m.radarInfo); n != 1 {
+		t.Errorf("%d radar_info series after ForgetStation, want 1", n)
+	}
+}
+
+func TestNotifierCountsDeliveries(t *testing.T) {
+	m := New()
+	mock := notify.NewMockNotifier()
+	n := m.Notifier("ntfy", mock)
+	ctx := context.Background()
+
+	if err := n.SendNotification(ctx, "title", "body"); err != nil {
+		t.Fatal(err)
+	}
+	if err := notify.Send(ctx, n, notify.Event{Kind: notify.EventChange, Title: "title", Message: "body"}); err != nil {
+		t.Fatal(err)
+	}
+	mock.SetShouldError(true)
+	if err := n.SendNotification(ctx, "title", "body"); err == nil {
+		t.Fatal("SendNotification() succeeded, want the mock's error")
+	}
+
+	if got := testutil.ToFloat64(m.notifications.WithLabelValues("ntfy", "sent")); got != 2 {
+		t.Errorf("sent = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.notifications.WithLabelValues("ntfy", "failed")); got != 1 {
+		t.Errorf("failed = %v, want 1", got)
+	}
+	if got := mock.GetCallCount(); got != 3 {
+		t.Errorf("mock received %d notifications, want 3", got)
+	}
+}
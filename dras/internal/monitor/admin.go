@@ -0,0 +1,142 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/radar"
+	"github.com/jacaudi/dras/internal/state"
+)
+
+// Errors returned by the station management methods.
+var (
+	ErrInvalidStation   = errors.New("invalid station ID")
+	ErrNotMonitored     = errors.New("station is not monitored")
+	ErrAlreadyMonitored = errors.New("station is already monitored")
+)
+
+// AddStation starts monitoring a station. It is polled, and announced,
+// straight away.
+func (m *Monitor) AddStation(stationID string) error {
+	id := strings.ToUpper(strings.TrimSpace(stationID))
+	if !radar.ValidateStationID(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidStation, stationID)
+	}
+	notMonitored := func(cfg *config.Config) error {
+		if slices.Contains(cfg.StationIDs(), id) {
+			return fmt.Errorf("%w: %s", ErrAlreadyMonitored, id)
+		}
+		return nil
+	}
+	return m.updateRuntime("Station added", id, notMonitored, func(rt *config.Runtime) {
+		if i := slices.Index(rt.Removed, id); i >= 0 {
+			rt.Removed = slices.Delete(rt.Removed, i, i+1)
+		} else {
+			rt.Added = append(rt.Added, id)
+		}
+	})
+}
+
+// RemoveStation stops monitoring a station and drops its state, including
+// any pause or alert toggles set at runtime.
+func (m *Monitor) RemoveStation(stationID string) error {
+	return m.updateRuntime("Station removed", stationID, monitored(stationID), func(rt *config.Runtime) {
+		if i := slices.Index(rt.Added, stationID); i >= 0 {
+			rt.Added = slices.Delete(rt.Added, i, i+1)
+		} else {
+			rt.Removed = append(rt.Removed, stationID)
+		}
+		rt.Paused = slices.DeleteFunc(rt.Paused, func(id string) bool { return id == stationID })
+		delete(rt.Alerts, stationID)
+	})
+}
+
+// PauseStation stops polling a station until ResumeStation. It stays
+// monitored: its state and history remain available. Pausing a paused
+// station does nothing.
+func (m *Monitor) PauseStation(stationID string) error {
+	return m.updateRuntime("Station paused", stationID, monitored(stationID), func(rt *config.Runtime) {
+		if !slices.Contains(rt.Paused, stationID) {
+			rt.Paused = append(rt.Paused, stationID)
+		}
+	})
+}
+
+// ResumeStation resumes polling a paused station, starting straight away.
+// Resuming a station that isn't paused does nothing.
+func (m *Monitor) ResumeStation(stationID string) error {
+	return m.updateRuntime("Station resumed", stationID, monitored(stationID), func(rt *config.Runtime) {
+		rt.Paused = slices.DeleteFunc(rt.Paused, func(id string) bool { return id == stationID })
+	})
+}
+
+// SetStationAlerts changes a station's alert toggles. Toggles left nil in
+// alerts keep their current value.
+func (m *Monitor) SetStationAlerts(stationID string, alerts config.AlertOverride) error {
+	return m.updateRuntime("Station alerts changed", stationID, monitored(stationID), func(rt *config.Runtime) {
+		if rt.Alerts == nil {
+			rt.Alerts = make(map[string]config.AlertOverride)
+		}
+		rt.Alerts[stationID] = rt.Alerts[stationID].Merge(alerts)
+	})
+}
+
+// monitored is an updateRuntime check that the station is monitored.
+func monitored(stationID string) func(*config.Config) error {
+	return func(cfg *config.Config) error {
+		if !slices.Contains(cfg.StationIDs(), stationID) {
+			return fmt.Errorf("%w: %s", ErrNotMonitored, stationID)
+		}
+		return nil
+	}
+}
+
+// updateRuntime runs check against the configuration in effect, applies
+// change to a copy of the runtime station management, persists it when the
+// state store supports it, and then swaps in the resulting configuration.
+// Nothing changes if check fails or the store can't save the result.
+func (m *Monitor) updateRuntime(msg, stationID string, check func(cfg *config.Config) error, change func(rt *config.Runtime)) error {
+	m.runtimeMu.Lock()
+	defer m.runtimeMu.Unlock()
+
+	if err := check(m.cfg()); err != nil {
+		return err
+	}
+	rt := m.runtime.Clone()
+	change(&rt)
+	if rs, ok := m.stateStore.(state.RuntimeStore); ok {
+		if err := rs.SaveRuntime(rt); err != nil {
+			return fmt.Errorf("persist station changes: %w", err)
+		}
+	}
+	m.runtime = rt
+	m.apply(m.base.WithRuntime(rt))
+	slog.Info(msg, "station", stationID)
+	return nil
+}
+
+// restoreRuntime returns the runtime station management persisted in the
+// state store, if any. A store failure is logged and treated as none.
+func (m *Monitor) restoreRuntime() config.Runtime {
+	rs, ok := m.stateStore.(state.RuntimeStore)
+	if !ok {
+		return config.Runtime{}
+	}
+	rt, ok, err := rs.LoadRuntime()
+	if err != nil {
+		slog.Warn(fmt.Sprintf("Failed to load persisted station changes: %v", err))
+		return config.Runtime{}
+	}
+	if ok && !rt.IsZero() {
+		slog.Info("Restored station changes made at runtime",
+			"added", strings.Join(rt.Added, ","),
+			"removed", strings.Join(rt.Removed, ","),
+			"paused", strings.Join(rt.Paused, ","),
+		)
+	}
+	return rt
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+	"github.com/jacaudi/dras/internal/state"
+)
+
+func newAdminMonitor(t *testing.T, opts ...Option) *Monitor {
+	t.Helper()
+	return New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX,KRAX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	}, opts...)
+}
+
+func stationIDs(m *Monitor) []string {
+	var ids []string
+	for _, st := range m.Stations() {
+		ids = append(ids, st.ID)
+	}
+	return ids
+}
+
+func TestStationManagement(t *testing.T) {
+	m := newAdminMonitor(t)
+
+	if err := m.AddStation("ktlx"); err != nil {
+		t.Fatalf("AddStation() error: %v", err)
+	}
+	if err := m.RemoveStation("KRAX"); err != nil {
+		t.Fatalf("RemoveStation() error: %v", err)
+	}
+	if got, want := stationIDs(m), []string{"KATX", "KTLX"}; !slices.Equal(got, want) {
+		t.Errorf("stations = %v, want %v", got, want)
+	}
+
+	off, on := false, true
+	if err := m.SetStationAlerts("KTLX", config.AlertOverride{VCP: &off, Status: &on}); err != nil {
+		t.Fatalf("SetStationAlerts() error: %v", err)
+	}
+	if st, _ := m.Station("KTLX"); st.Alerts.VCP || !st.Alerts.Status {
+		t.Errorf("KTLX alerts = %+v, want VCP off and status on", st.Alerts)
+	}
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"invalid ID", m.AddStation("nope!"), ErrInvalidStation},
+		{"add twice", m.AddStation("KATX"), ErrAlreadyMonitored},
+		{"remove unknown", m.RemoveStation("KRAX"), ErrNotMonitored},
+		{"pause unknown", m.PauseStation("KFWS"), ErrNotMonitored},
+		{"alerts unknown", m.SetStationAlerts("KFWS", config.AlertOverride{}), ErrNotMonitored},
+	} {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, tt.err, tt.want)
+		}
+	}
+
+	// Re-adding a removed station undoes the removal.
+	if err := m.AddStation("KRAX"); err != nil {
+		t.Fatalf("AddStation(KRAX) error: %v", err)
+	}
+	if rt := m.cfg().Runtime(); len(rt.Removed) != 0 || !slices.Equal(rt.Added, []string{"KTLX"}) {
+		t.Errorf("runtime = %+v, want only KTLX added", rt)
+	}
+}
+
+func TestPausedStationsAreNotPolled(t *testing.T) {
+	m := newAdminMonitor(t)
+	now := time.Now()
+	m.dueStations([]string{"KATX", "KRAX"}, now, time.Minute)
+
+	if err := m.PauseStation("KATX"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := m.Station("KATX"); !st.Paused {
+		t.Error("Station(KATX).Paused = false after PauseStation")
+	}
+	if due := m.dueStations([]string{"KATX", "KRAX"}, now.Add(time.Hour), time.Minute); !slices.Equal(due, []string{"KRAX"}) {
+		t.Errorf("due = %v, want the paused station skipped", due)
+	}
+
+	if err := m.ResumeStation("KATX"); err != nil {
+		t.Fatal(err)
+	}
+	if pending := m.unscheduled([]string{"KATX", "KRAX"}); !slices.Equal(pending, []string{"KATX"}) {
+		t.Errorf("unscheduled = %v, want the resumed station polled straight away", pending)
+	}
+}
+
+func TestStationChangesSurviveReloadAndRestart(t *testing.T) {
+	store, err := state.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newAdminMonitor(t, WithStateStore(store))
+	if err := m.AddStation("KTLX"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.PauseStation("KATX"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Reload(&config.Config{StationInput: "KATX,KFWS", CheckInterval: time.Minute})
+	if got, want := stationIDs(m), []string{"KATX", "KFWS", "KTLX"}; !slices.Equal(got, want) {
+		t.Errorf("stations after reload = %v, want %v", got, want)
+	}
+	if st, _ := m.Station("KATX"); !st.Paused {
+		t.Error("KATX resumed by a reload")
+	}
+
+	restarted := newAdminMonitor(t, WithStateStore(store))
+	if got, want := stationIDs(restarted), []string{"KATX", "KRAX", "KTLX"}; !slices.Equal(got, want) {
+		t.Errorf("stations after restart = %v, want %v", got, want)
+	}
+	if st, _ := restarted.Station("KATX"); !st.Paused {
+		t.Error("KATX resumed by a restart")
+	}
+}
+
+func TestAddedStationIsPolledStraightAway(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitFor("the initial fetch", func() bool { return m.Health().InitialFetchDone })
+
+	if err := m.AddStation("KTLX"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("KTLX to be polled", func() bool {
+		st, _ := m.Station("KTLX")
+		return !st.LastPoll.IsZero()
+	})
+}
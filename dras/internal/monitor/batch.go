@@ -0,0 +1,156 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+// passBatch gathers the notifications of one fetchAndReportRadarData pass
+// so that a notifier due at least the threshold of them gets one batch
+// instead. A station's send blocks until its round is flushed, which is
+// when every worker still polling is waiting on a send, so no other
+// notification can join, or when the window since the round's first
+// notification has passed. Later notifications start a new round.
+type passBatch struct {
+	m         *Monitor
+	ctx       context.Context
+	threshold int
+	window    time.Duration
+	workers   int
+
+	mu sync.Mutex
+	// running is how many of the pass's stations are still being polled.
+	running int
+	pending []*batchedSend
+	timer   *time.Timer
+	// round counts flushes, so a window timer that fires as its round is
+	// flushed anyway leaves the next one alone.
+	round int
+}
+
+// batchedSend is a notification waiting for its round to be flushed.
+type batchedSend struct {
+	n      notify.Notifier
+	ev     notify.Event
+	logger *slog.Logger
+	// err receives the notification's delivery error.
+	err chan error
+}
+
+type batchKey struct{}
+
+// withBatch returns ctx carrying b, for m.send.
+func withBatch(ctx context.Context, b *passBatch) context.Context {
+	return context.WithValue(ctx, batchKey{}, b)
+}
+
+// batchFrom returns the pass's batch carried by ctx, or nil.
+func batchFrom(ctx context.Context) *passBatch {
+	b, _ := ctx.Value(batchKey{}).(*passBatch)
+	return b
+}
+
+// newPassBatch returns a batch for a pass polling stations stations with
+// workers workers.
+func (m *Monitor) newPassBatch(ctx context.Context, threshold int, window time.Duration, stations, workers int) *passBatch {
+	return &passBatch{m: m, ctx: ctx, threshold: threshold, window: window, workers: workers, running: stations}
+}
+
+// send queues ev for n and waits for its round to be flushed.
+func (b *passBatch) send(n notify.Notifier, ev notify.Event, logger *slog.Logger) error {
+	s := &batchedSend{n: n, ev: ev, logger: logger, err: make(chan error, 1)}
+	b.mu.Lock()
+	b.pending = append(b.pending, s)
+	if b.timer == nil {
+		round := b.round
+		b.timer = time.AfterFunc(b.window, func() { b.flush(round) })
+	}
+	full, round := b.fullLocked(), b.round
+	b.mu.Unlock()
+	if full {
+		b.flush(round)
+	}
+	return <-s.err
+}
+
+// done records that one of the pass's stations has been polled.
+func (b *passBatch) done() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.running--
+	full, round := b.fullLocked(), b.round
+	b.mu.Unlock()
+	if full {
+		b.flush(round)
+	}
+}
+
+// fullLocked reports whether every worker still polling is waiting on a
+// send. b.mu must be held.
+func (b *passBatch) fullLocked() bool {
+	return len(b.pending) > 0 && len(b.pending) >= min(b.workers, b.running)
+}
+
+// flush delivers the notifications of the given round, unless it has
+// been flushed already: those of a notifier with at least the threshold of
+// them as one batch, the rest on their own.
+func (b *passBatch) flush(round int) {
+	b.mu.Lock()
+	if round != b.round || len(b.pending) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	pending := b.pending
+	b.pending = nil
+	b.round++
+	b.timer.Stop()
+	b.timer = nil
+	b.mu.Unlock()
+
+	var order []notify.Notifier
+	byNotifier := make(map[notify.Notifier][]*batchedSend)
+	for _, s := range pending {
+		if _, ok := byNotifier[s.n]; !ok {
+			order = append(order, s.n)
+		}
+		byNotifier[s.n] = append(byNotifier[s.n], s)
+	}
+	for _, n := range order {
+		sends := byNotifier[n]
+		if len(sends) < b.threshold {
+			for _, s := range sends {
+				s.err <- b.m.deliver(b.ctx, n, s.ev, s.logger)
+			}
+			continue
+		}
+		events := make([]notify.Event, len(sends))
+		for i, s := range sends {
+			events[i] = s.ev
+		}
+		logger := slog.With("notifications", len(events))
+		err := b.m.deliver(b.ctx, n, b.m.batchEvent(events, time.Time{}, time.Now(), logger), logger)
+		if err == nil {
+			logger.Info("Batched notifications sent")
+		}
+		for _, s := range sends {
+			s.err <- err
+		}
+	}
+}
+
+// send sends ev for the station through its notifier, batched with the
+// rest of the pass when ctx carries a batch, and subject to the rate
+// limit. It returns the delivery error, as deliveryError does.
+func (m *Monitor) send(ctx context.Context, stationID string, ev notify.Event, logger *slog.Logger) error {
+	n := m.notifierFor(stationID)
+	if b := batchFrom(ctx); b != nil && n != nil {
+		return b.send(n, ev, logger)
+	}
+	return m.deliver(ctx, n, ev, logger)
+}
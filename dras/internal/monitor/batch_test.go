@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestBatchesNotificationsOfOnePass(t *testing.T) {
+	ctx := context.Background()
+	stations := []string{"KATX", "KRAX", "KBGM"}
+	fetcher := bulkPoolFetcher{&poolFetcher{}}
+	setVCP := func(vcps map[string]string) {
+		fetcher.mu.Lock()
+		defer fetcher.mu.Unlock()
+		fetcher.bulk = make(map[string]*radar.Data)
+		for _, id := range stations {
+			fetcher.bulk[id] = &radar.Data{Name: id, VCP: vcps[id], Mode: "Clear Air"}
+		}
+	}
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(fetcher, rec, nil, &config.Config{
+		StationInput:   strings.Join(stations, ","),
+		CheckInterval:  time.Minute,
+		AlertConfig:    radar.AlertConfig{VCP: true},
+		NWSBulk:        true,
+		BatchThreshold: 2,
+		BatchWindow:    time.Minute,
+	})
+	pass := func(vcps map[string]string) []notify.Event {
+		t.Helper()
+		setVCP(vcps)
+		rec.events = nil
+		if err := m.fetchAndReportRadarData(ctx, stations); err != nil {
+			t.Fatalf("fetchAndReportRadarData() error: %v", err)
+		}
+		return rec.events
+	}
+
+	got := pass(map[string]string{"KATX": "R35", "KRAX": "R35", "KBGM": "R35"})
+	if len(got) != 1 || got[0].Kind != notify.EventBatch || len(got[0].Events) != 3 {
+		t.Fatalf("startup events = %+v, want one batch of three", got)
+	}
+
+	got = pass(map[string]string{"KATX": "R212", "KRAX": "R212", "KBGM": "R212"})
+	if len(got) != 1 || got[0].Kind != notify.EventBatch || got[0].Title != "DRAS: 3 Notifications" {
+		t.Fatalf("change events = %+v, want one batch of three", got)
+	}
+	for _, ev := range got[0].Events {
+		if ev.Kind != notify.EventChange || !strings.Contains(got[0].Message, ev.Title+": ") {
+			t.Errorf("batch is missing %+v:\n%s", ev, got[0].Message)
+		}
+	}
+	for _, id := range stations {
+		if st, _ := m.Station(id); st.Data.VCP != "R212" {
+			t.Errorf("%s VCP = %q, want R212 once the batch is sent", id, st.Data.VCP)
+		}
+	}
+
+	got = pass(map[string]string{"KATX": "R35", "KRAX": "R212", "KBGM": "R212"})
+	if len(got) != 1 || got[0].Kind != notify.EventChange || got[0].StationID != "KATX" {
+		t.Errorf("events = %+v, want KATX's change on its own, below the threshold", got)
+	}
+}
+
+func TestPassBatchFlushesAfterWindow(t *testing.T) {
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radar.NewMockDataFetcher(), rec, nil, &config.Config{})
+	// Two stations are polling, but the other never sends.
+	b := m.newPassBatch(context.Background(), 2, 10*time.Millisecond, 2, 2)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- b.send(rec, notify.Event{Kind: notify.EventChange, StationID: "KATX"}, slog.Default())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("send() error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send() still waiting after the window")
+	}
+	if len(rec.events) != 1 || rec.events[0].StationID != "KATX" {
+		t.Errorf("events = %+v, want KATX's on its own", rec.events)
+	}
+}
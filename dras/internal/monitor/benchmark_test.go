@@ -27,14 +27,14 @@ func BenchmarkMonitorMemoryUsage(b *testing.B) {
 	b.Run("MonitorCreation", func(b *testing.B) {
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			radarService := radar.New()
+			radarService := radar.New(radar.Config{})
 			var notifyService *notify.Service // nil for dry run
 			_ = New(radarService, notifyService, nil, cfg)
 		}
 	})
 
 	b.Run("DataMapGrowth", func(b *testing.B) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -72,7 +72,7 @@ func BenchmarkConcurrentProcessing(b *testing.B) {
 	}
 
 	b.Run("SingleStation", func(b *testing.B) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -95,7 +95,7 @@ func BenchmarkConcurrentProcessing(b *testing.B) {
 	})
 
 	b.Run("MultipleStations", func(b *testing.B) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -21,7 +22,7 @@ func TestConcurrentStationProcessing(t *testing.T) {
 	}
 
 	t.Run("concurrent data access", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -62,7 +63,7 @@ func TestConcurrentStationProcessing(t *testing.T) {
 	})
 
 	t.Run("concurrent read/write operations", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -158,7 +159,7 @@ func TestGoroutineErrorHandling(t *testing.T) {
 	t.Run("goroutine panic recovery", func(t *testing.T) {
 		// This test simulates what would happen if a goroutine panicked
 		// In our current implementation, goroutines return errors instead of panicking
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -207,7 +208,7 @@ func TestMonitorStartStop(t *testing.T) {
 	}
 
 	t.Run("start and cancel", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -232,3 +233,107 @@ func TestMonitorStartStop(t *testing.T) {
 		}
 	})
 }
+
+// poolFetcher is a radar fetcher that records how many FetchData calls run
+// at once, and optionally answers bulk fetches.
+type poolFetcher struct {
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	fetched  []string
+	bulk     map[string]*radar.Data
+	bulkErr  error
+	bulkArgs [][]string
+}
+
+func (f *poolFetcher) FetchData(ctx context.Context, stationID string) (*radar.Data, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.maxSeen = max(f.maxSeen, f.inFlight)
+	f.fetched = append(f.fetched, stationID)
+	f.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	f.mu.Lock()
+	f.inFlight--
+	f.mu.Unlock()
+	return &radar.Data{Name: stationID, VCP: "R31", Mode: "Clear Air", GenState: "Off"}, nil
+}
+
+// bulkPoolFetcher adds FetchAll to poolFetcher.
+type bulkPoolFetcher struct{ *poolFetcher }
+
+func (f bulkPoolFetcher) FetchAll(ctx context.Context, stationIDs []string) (map[string]*radar.Data, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.bulkArgs = append(f.bulkArgs, stationIDs)
+	return f.bulk, f.bulkErr
+}
+
+func TestFetchAndReportRadarDataBoundsConcurrency(t *testing.T) {
+	fetcher := &poolFetcher{}
+	cfg := &config.Config{DryRun: true, PollConcurrency: 2}
+	m := New(fetcher, nil, nil, cfg)
+
+	stations := []string{"KATX", "KRAX", "KBGM", "KTLX", "KFFC", "KLOT"}
+	if err := m.fetchAndReportRadarData(context.Background(), stations); err != nil {
+		t.Fatalf("fetchAndReportRadarData() error: %v", err)
+	}
+	if len(fetcher.fetched) != len(stations) {
+		t.Errorf("fetched %v, want every station once", fetcher.fetched)
+	}
+	if fetcher.maxSeen > 2 {
+		t.Errorf("%d fetches ran at once, want at most 2", fetcher.maxSeen)
+	}
+}
+
+func TestFetchAndReportRadarDataBulk(t *testing.T) {
+	stations := []string{"KATX", "KRAX", "KBGM"}
+
+	t.Run("fans out the bulk result", func(t *testing.T) {
+		fetcher := bulkPoolFetcher{&poolFetcher{bulk: map[string]*radar.Data{
+			"KATX": {Name: "Seattle", VCP: "R35", Mode: "Clear Air"},
+			"KRAX": {Name: "Raleigh", VCP: "R212", Mode: "Precipitation"},
+		}}}
+		m := New(fetcher, nil, nil, &config.Config{DryRun: true, NWSBulk: true})
+
+		if err := m.fetchAndReportRadarData(context.Background(), stations); err != nil {
+			t.Fatalf("fetchAndReportRadarData() error: %v", err)
+		}
+		if len(fetcher.bulkArgs) != 1 || len(fetcher.bulkArgs[0]) != 3 {
+			t.Errorf("bulk fetches = %v, want one for all stations", fetcher.bulkArgs)
+		}
+		// KBGM was missing from the bulk result, so it is fetched alone.
+		if len(fetcher.fetched) != 1 || fetcher.fetched[0] != "KBGM" {
+			t.Errorf("single fetches = %v, want only KBGM", fetcher.fetched)
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if got := m.radarDataMap["KRAX"]["last"].(*radar.Data); got.VCP != "R212" {
+			t.Errorf("KRAX data = %+v, want the bulk result", *got)
+		}
+	})
+
+	t.Run("falls back when the bulk fetch fails", func(t *testing.T) {
+		fetcher := bulkPoolFetcher{&poolFetcher{bulkErr: errors.New("boom")}}
+		m := New(fetcher, nil, nil, &config.Config{DryRun: true, NWSBulk: true})
+
+		if err := m.fetchAndReportRadarData(context.Background(), stations); err != nil {
+			t.Fatalf("fetchAndReportRadarData() error: %v", err)
+		}
+		if len(fetcher.fetched) != 3 {
+			t.Errorf("single fetches = %v, want every station", fetcher.fetched)
+		}
+	})
+
+	t.Run("off unless enabled", func(t *testing.T) {
+		fetcher := bulkPoolFetcher{&poolFetcher{}}
+		m := New(fetcher, nil, nil, &config.Config{DryRun: true})
+
+		if err := m.fetchAndReportRadarData(context.Background(), stations); err != nil {
+			t.Fatalf("fetchAndReportRadarData() error: %v", err)
+		}
+		if len(fetcher.bulkArgs) != 0 || len(fetcher.fetched) != 3 {
+			t.Errorf("bulk fetches = %v, single fetches = %v; want only single ones", fetcher.bulkArgs, fetcher.fetched)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// pendingChange is a field change that has not been seen for long enough to
+// be reported yet.
+type pendingChange struct {
+	value string
+	since time.Time
+	polls int
+}
+
+// debounceState is a station's debounce bookkeeping, kept in radarDataMap
+// under "debounce" alongside its last reported data.
+type debounceState struct {
+	pending map[radar.Field]*pendingChange
+	// flaps counts the changes to each field that were dropped because the
+	// field moved again before they were confirmed, since the field's last
+	// reported change.
+	flaps map[radar.Field]int
+}
+
+func newDebounceState() *debounceState {
+	return &debounceState{
+		pending: make(map[radar.Field]*pendingChange),
+		flaps:   make(map[radar.Field]int),
+	}
+}
+
+// filter returns the changes that have now been observed for at least polls
+// consecutive polls and for at least window, and the fields of the rest,
+// which stay pending. A pending change whose field reverts, or moves on to
+// yet another value, counts as a flap; the next change reported for that
+// field carries the count. With polls <= 1 and no window every change is
+// confirmed straight away.
+//
+// A confirmed change stays pending, with its flap count, until it is
+// committed: if its notification fails, the next poll confirms it again
+// rather than debouncing it anew.
+func (s *debounceState) filter(changes []radar.Change, now time.Time, polls int, window time.Duration) (confirmed []radar.Change, held []radar.Field) {
+	seen := make(map[radar.Field]bool, len(changes))
+	for _, c := range changes {
+		seen[c.Field] = true
+	}
+	for field := range s.pending {
+		if !seen[field] {
+			// Back to the last reported value before it was confirmed.
+			s.flaps[field]++
+			delete(s.pending, field)
+		}
+	}
+
+	for _, c := range changes {
+		p := s.pending[c.Field]
+		if p != nil && p.value != c.New {
+			s.flaps[c.Field]++
+			p = nil
+		}
+		if p == nil {
+			p = &pendingChange{value: c.New, since: now}
+			s.pending[c.Field] = p
+		}
+		p.polls++
+
+		if p.polls < polls || now.Sub(p.since) < window {
+			held = append(held, c.Field)
+			continue
+		}
+		if n := s.flaps[c.Field]; n > 0 {
+			c.Flaps = n
+			c.Text = fmt.Sprintf("%s (%d brief %s suppressed)", c.Text, n, plural(n, "change", "changes"))
+		}
+		confirmed = append(confirmed, c)
+	}
+	return confirmed, held
+}
+
+// commit clears the pending entries and flap counts of changes confirmed
+// by filter, once they have been reported.
+func (s *debounceState) commit(confirmed []radar.Change) {
+	for _, c := range confirmed {
+		delete(s.pending, c.Field)
+		delete(s.flaps, c.Field)
+	}
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func vcpChange(from, to string) radar.Change {
+	return radar.Change{Field: radar.FieldVCP, Old: from, New: to, Severity: radar.SeverityInfo, Text: to + " active"}
+}
+
+func TestDebounceFilter(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	poll := func(i int) time.Time { return start.Add(time.Duration(i) * 5 * time.Minute) }
+
+	t.Run("disabled reports at once", func(t *testing.T) {
+		s := newDebounceState()
+		confirmed, held := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(0), 1, 0)
+		if len(confirmed) != 1 || len(held) != 0 {
+			t.Errorf("confirmed = %v, held = %v; want the change reported", confirmed, held)
+		}
+	})
+
+	t.Run("polls", func(t *testing.T) {
+		s := newDebounceState()
+		for i := 0; i < 2; i++ {
+			confirmed, held := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(i), 3, 0)
+			if len(confirmed) != 0 || len(held) != 1 {
+				t.Fatalf("poll %d: confirmed = %v, held = %v; want held", i, confirmed, held)
+			}
+		}
+		confirmed, _ := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(2), 3, 0)
+		if len(confirmed) != 1 || confirmed[0].Flaps != 0 {
+			t.Errorf("third poll confirmed = %+v, want the change with no flaps", confirmed)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		s := newDebounceState()
+		if confirmed, _ := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(0), 1, 10*time.Minute); len(confirmed) != 0 {
+			t.Fatal("change confirmed before the window elapsed")
+		}
+		if confirmed, _ := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(1), 1, 10*time.Minute); len(confirmed) != 0 {
+			t.Fatal("change confirmed before the window elapsed")
+		}
+		if confirmed, _ := s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(2), 1, 10*time.Minute); len(confirmed) != 1 {
+			t.Error("change not confirmed once the window elapsed")
+		}
+	})
+
+	t.Run("flaps are suppressed and summarized", func(t *testing.T) {
+		s := newDebounceState()
+		s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(0), 2, 0)
+		s.filter(nil, poll(1), 2, 0)                                      // back to R35
+		s.filter([]radar.Change{vcpChange("R35", "R215")}, poll(2), 2, 0) // and again
+		s.filter([]radar.Change{vcpChange("R35", "R12")}, poll(3), 2, 0)  // on to something else
+		confirmed, held := s.filter([]radar.Change{vcpChange("R35", "R12")}, poll(4), 2, 0)
+		if len(confirmed) != 1 || len(held) != 0 {
+			t.Fatalf("confirmed = %v, held = %v; want R12 reported", confirmed, held)
+		}
+		if c := confirmed[0]; c.New != "R12" || c.Flaps != 2 || !strings.HasSuffix(c.Text, "(2 brief changes suppressed)") {
+			t.Errorf("confirmed change = %+v, want R12 with 2 flaps summarized", c)
+		}
+
+		// Until the report is committed, the change stays confirmed.
+		again, _ := s.filter([]radar.Change{vcpChange("R35", "R12")}, poll(5), 2, 0)
+		if len(again) != 1 || again[0].Flaps != 2 {
+			t.Fatalf("uncommitted change confirmed again = %+v, want it with its flaps", again)
+		}
+
+		// The count starts over after a report.
+		s.commit(confirmed)
+		s.filter([]radar.Change{vcpChange("R12", "R31")}, poll(5), 2, 0)
+		confirmed, _ = s.filter([]radar.Change{vcpChange("R12", "R31")}, poll(6), 2, 0)
+		if len(confirmed) != 1 || confirmed[0].Flaps != 0 {
+			t.Errorf("confirmed = %+v, want no flaps after a report", confirmed)
+		}
+	})
+}
+
+func TestProcessStationDebouncesFlappingFields(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, PowerSource: true},
+		DebouncePolls: 2,
+	})
+	ctx := context.Background()
+
+	steady := radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air", PowerSource: "Commercial Utility"}
+	flapped := steady
+	flapped.VCP, flapped.Mode = "R215", "Precipitation"
+	generator := flapped
+	generator.PowerSource = "Auxiliary Power"
+
+	for i, data := range []radar.Data{steady, flapped, steady, flapped, generator} {
+		d := data
+		radarMock.SetResponse("KATX", &d)
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("poll %d: processStation() error: %v", i, err)
+		}
+	}
+
+	// Startup, then the VCP confirmed on the fifth poll while the power
+	// source change is still held back.
+	if got := notifier.GetCallCount(); got != 2 {
+		t.Fatalf("sent %d notifications, want startup + one change", got)
+	}
+	last := notifier.GetLastNotification()
+	if !strings.Contains(last.Message, "Precipitation Mode (Vertical Scanning Emphasis) Active (1 brief change suppressed)") ||
+		strings.Contains(last.Message, "Power source") {
+		t.Errorf("change message = %q", last.Message)
+	}
+
+	m.mu.Lock()
+	remembered := m.radarDataMap["KATX"]["last"].(*radar.Data)
+	m.mu.Unlock()
+	if remembered.VCP != "R215" || remembered.PowerSource != "Commercial Utility" {
+		t.Errorf("remembered data = %+v, want the new VCP and the old power source", remembered)
+	}
+}
+
+func TestDebouncedChangeRetriedAfterFailedNotification(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+		DebouncePolls: 3,
+	})
+	ctx := context.Background()
+
+	steady := radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air"}
+	flapped := steady
+	flapped.VCP, flapped.Mode = "R215", "Precipitation"
+	for _, data := range []radar.Data{steady, flapped, steady, flapped, flapped} {
+		d := data
+		radarMock.SetResponse("KATX", &d)
+		m.processStation(ctx, "KATX")
+	}
+
+	// Confirmed on this poll, but the notification fails.
+	notifier.SetShouldError(true)
+	if err := m.processStation(ctx, "KATX"); err == nil {
+		t.Fatal("processStation() error = nil with the notifier failing")
+	}
+	notifier.SetShouldError(false)
+	notifier.ClearNotifications()
+
+	// The next poll sends it straight away, flap count and all.
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("processStation() error: %v", err)
+	}
+	if got := notifier.GetNotifications(); len(got) != 1 || !strings.Contains(got[0].Message, "(1 brief change suppressed)") {
+		t.Errorf("notifications = %+v, want the change retried with its flap", got)
+	}
+}
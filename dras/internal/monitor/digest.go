@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// digestSchedule is Start's timer for the next digest (see config.Digest).
+type digestSchedule struct {
+	timer *time.Timer
+	// next is when the timer fires; zero when no digest is scheduled.
+	next time.Time
+}
+
+// C returns the channel the timer fires on, or nil, which never fires, when
+// no digest is scheduled.
+func (s *digestSchedule) C() <-chan time.Time {
+	if s.timer == nil {
+		return nil
+	}
+	return s.timer.C
+}
+
+// schedule arms the timer for d's first time after now, or disarms it when
+// d is nil.
+func (s *digestSchedule) schedule(d *config.Digest, now time.Time) {
+	s.stop()
+	if d == nil {
+		s.next = time.Time{}
+		return
+	}
+	s.next = d.Next(now)
+	s.timer = time.NewTimer(s.next.Sub(now))
+	slog.Debug("Next digest scheduled", "at", s.next.Format(time.RFC3339))
+}
+
+// due reports whether the digest's time has come at now, although it may
+// not have been sent yet: a reload then leaves the timer alone rather than
+// skip that digest.
+func (s *digestSchedule) due(now time.Time) bool {
+	return !s.next.IsZero() && !now.Before(s.next)
+}
+
+func (s *digestSchedule) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// sendDigest sends each notifier's recipients a digest of their stations:
+// every station's current radar state and the changes reported since the
+// previous digest, or since monitoring started. With images enabled for
+// the digest, a contact sheet of the stations' latest radar images is
+// attached. Failures are logged; the next digest starts from this one
+// regardless, as the changes are in the history API too.
+func (m *Monitor) sendDigest(ctx context.Context, now time.Time) {
+	cfg := m.cfg()
+	if cfg.Digest == nil {
+		return
+	}
+	m.mu.Lock()
+	since := m.lastDigest
+	if since.IsZero() {
+		since = m.startedAt
+	}
+	m.lastDigest = now
+	m.mu.Unlock()
+
+	stationIDs := cfg.StationIDs()
+	if cfg.DryRun {
+		_, body := m.render(message.Digest, message.NewDigestData(m.stationSummaries(stationIDs, since), since, now), slog.Default())
+		slog.Info(fmt.Sprintf("Would send digest:\n%s", body))
+		return
+	}
+
+	order, byNotifier := m.groupByNotifier(stationIDs)
+	for _, n := range order {
+		ids := byNotifier[n]
+		logger := slog.With("stations", strings.Join(ids, ","))
+		summaries := m.stationSummaries(ids, since)
+		title, body := m.render(message.Digest, message.NewDigestData(summaries, since, now), logger)
+		ev := notify.Event{
+			Kind:     notify.EventDigest,
+			Stations: summaries,
+			Title:    title,
+			Message:  body,
+			Time:     now,
+		}
+		if cfg.Digest.Image {
+			ev.Attachment = m.contactSheet(ids, now, logger)
+		}
+		if err := deliveryError(notify.Send(ctx, n, ev), logger); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to send digest: %v", err))
+			continue
+		}
+		logger.Info("Digest sent", "since", since.Format(time.RFC3339))
+	}
+}
+
+// stationSummaries summarizes each station for a digest: its current
+// radar data and outage, and the changes reported since the given time,
+// oldest first. Only what is still in the station's history is included.
+func (m *Monitor) stationSummaries(stationIDs []string, since time.Time) []radar.StationSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]radar.StationSummary, 0, len(stationIDs))
+	for _, id := range stationIDs {
+		st := m.stationStateLocked(id)
+		s := radar.StationSummary{StationID: id, Data: st.Data, Outage: st.Outage}
+		if rec, ok := m.stations[id]; ok {
+			for _, e := range rec.history {
+				if e.Time.After(since) && (e.Kind == message.Change || e.Kind == message.Recovery) {
+					s.Changes = append(s.Changes, e.Changes...)
+				}
+			}
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// contactSheet returns the stations' latest radar images on one contact
+// sheet, in the order of stationIDs, or nil when none of them has one.
+func (m *Monitor) contactSheet(stationIDs []string, now time.Time, logger *slog.Logger) *notify.Attachment {
+	images := make([]*image.Image, len(stationIDs))
+	found := false
+	for i, id := range stationIDs {
+		if img, ok := m.LatestImage(id); ok {
+			images[i] = img
+			found = true
+		}
+	}
+	if !found {
+		logger.Debug("No radar images for the digest's contact sheet")
+		return nil
+	}
+	sheet, err := image.ContactSheet(images, now)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to build the digest's contact sheet: %v", err))
+		return nil
+	}
+	return &notify.Attachment{
+		Data:        sheet.Data,
+		ContentType: sheet.ContentType,
+		Filename:    sheet.Filename,
+	}
+}
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	stdimage "image"
+	"image/color/palette"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// gifSource serves a small GIF for every station and keeps no cache.
+type gifSource struct{ data []byte }
+
+func newGIFSource(t *testing.T) gifSource {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, stdimage.NewPaletted(stdimage.Rect(0, 0, 60, 55), palette.Plan9), nil); err != nil {
+		t.Fatal(err)
+	}
+	return gifSource{data: buf.Bytes()}
+}
+
+func (s gifSource) Fetch(_ context.Context, stationID string) (*image.Image, error) {
+	return &image.Image{StationID: stationID, Data: s.data, ContentType: "image/gif"}, nil
+}
+
+func (gifSource) Latest(string) (*image.Image, bool) { return nil, false }
+
+func TestDigestSummarizesStationsPerNotifier(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate"})
+	radarMock.SetResponse("KRAX", &radar.Data{Name: "Raleigh", VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+	radarMock.SetResponse("KLOT", &radar.Data{Name: "Chicago", VCP: "R212", Mode: "Precipitation", Status: "Operate"})
+	shared := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	krax := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radarMock, shared, newGIFSource(t), &config.Config{
+		StationInput:  "KATX,KRAX,KLOT",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+		Digest:        &config.Digest{At: 7 * time.Hour, Location: time.UTC, Image: true},
+	}, WithStationNotifiers(map[string]notify.Notifier{"KRAX": krax}))
+
+	for _, id := range []string{"KATX", "KRAX", "KLOT"} {
+		if err := m.processStation(ctx, id); err != nil {
+			t.Fatalf("processStation(%s) error: %v", id, err)
+		}
+	}
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation", Status: "Operate"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("processStation() error: %v", err)
+	}
+	shared.events, krax.events = nil, nil
+
+	first := time.Now()
+	m.sendDigest(ctx, first)
+	if len(shared.events) != 1 || len(krax.events) != 1 {
+		t.Fatalf("got %d and %d events, want one digest per notifier", len(shared.events), len(krax.events))
+	}
+	ev := shared.events[0]
+	if ev.Kind != notify.EventDigest || ev.Title != "DRAS Digest" || len(ev.Stations) != 2 || ev.Stations[0].StationID != "KATX" || ev.Stations[1].StationID != "KLOT" {
+		t.Fatalf("digest = %+v, want KATX and KLOT", ev)
+	}
+	if len(ev.Stations[0].Changes) != 1 || !strings.HasPrefix(ev.Message, "KATX Seattle: R12, Precipitation, Operate\n  - ") || !strings.Contains(ev.Message, "KLOT Chicago: R212, Precipitation, Operate\n  No changes") {
+		t.Errorf("digest message = %q", ev.Message)
+	}
+	if ev.Attachment == nil || ev.Attachment.ContentType != "image/png" {
+		t.Errorf("attachment = %+v, want a contact sheet", ev.Attachment)
+	}
+	if got := krax.events[0]; len(got.Stations) != 1 || got.Stations[0].StationID != "KRAX" {
+		t.Errorf("KRAX digest = %+v, want KRAX only", got)
+	}
+
+	// The next digest only covers what happened since this one.
+	m.sendDigest(ctx, first.Add(24*time.Hour))
+	if ev := shared.events[1]; len(ev.Stations[0].Changes) != 0 {
+		t.Errorf("second digest changes = %+v, want none", ev.Stations[0].Changes)
+	}
+}
+
+func TestDigestSchedule(t *testing.T) {
+	var s digestSchedule
+	s.schedule(nil, time.Now())
+	if s.C() != nil || s.due(time.Now()) {
+		t.Fatal("a digest is scheduled without one configured")
+	}
+
+	now := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	s.schedule(&config.Digest{At: 7 * time.Hour, Location: time.UTC}, now)
+	defer s.stop()
+	if s.C() == nil || !s.next.Equal(now.Add(time.Hour)) {
+		t.Fatalf("next digest at %v, want 07:00", s.next)
+	}
+	if s.due(now) || !s.due(now.Add(time.Hour)) {
+		t.Error("due() does not follow the scheduled time")
+	}
+}
@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// StationStatus is the polling state of one station.
+type StationStatus struct {
+	ID string `json:"id"`
+	// LastPoll is when the station was last fetched, successfully or not.
+	LastPoll time.Time `json:"last_poll,omitzero"`
+	// LastSuccess is when radar data was last fetched and processed without
+	// error.
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	// LastError is the error of the last poll, empty when it succeeded.
+	LastError string `json:"last_error,omitempty"`
+	// ConsecutiveFailures counts the polls that have failed since the last
+	// success.
+	ConsecutiveFailures int `json:"consecutive_failures"`
+	// Outage is set while the station is not reporting (see
+	// UnreachableAfter and StaleAfter in config.Config).
+	Outage *radar.Outage `json:"outage,omitempty"`
+}
+
+// Health is a snapshot of the monitor's polling state, for liveness and
+// readiness probes.
+type Health struct {
+	// Started reports whether Start has been called.
+	Started bool
+	// LastTick is when the poll loop last began a round of polls (or
+	// started waiting for one).
+	LastTick time.Time
+	// PollInterval is the poll loop's tick.
+	PollInterval time.Duration
+	// InitialFetchDone reports whether the first round of polls after Start
+	// has finished.
+	InitialFetchDone bool
+	// DryRun mirrors the configuration: no notifications are sent.
+	DryRun bool
+	// NotifierConfigured reports whether a notifier is set up.
+	NotifierConfigured bool
+	// Stations holds the status of every monitored station, in
+	// configuration order. A station that has not been polled yet has only
+	// its ID set.
+	Stations []StationStatus
+}
+
+// Alive reports whether the poll loop is still ticking: it has begun a
+// round of polls within twice the poll interval of now. A loop stuck in a
+// round, or one that stopped, stops being alive.
+func (h Health) Alive(now time.Time) bool {
+	if !h.Started {
+		return false
+	}
+	return now.Sub(h.LastTick) <= 2*h.PollInterval
+}
+
+// Health returns the monitor's current polling state.
+func (m *Monitor) Health() Health {
+	cfg := m.cfg()
+	ids := cfg.StationIDs()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := Health{
+		Started:            !m.startedAt.IsZero(),
+		LastTick:           m.lastTick,
+		PollInterval:       cfg.PollInterval(),
+		InitialFetchDone:   m.initialFetchDone,
+		DryRun:             cfg.DryRun,
+		NotifierConfigured: m.notifyService != nil,
+		Stations:           make([]StationStatus, 0, len(ids)),
+	}
+	for _, id := range ids {
+		st := StationStatus{ID: id}
+		if rec, ok := m.stations[id]; ok {
+			st = rec.status
+		}
+		h.Stations = append(h.Stations, st)
+	}
+	return h
+}
+
+// tick records that the poll loop is beginning a round of polls.
+func (m *Monitor) tick(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.startedAt.IsZero() {
+		m.startedAt = now
+	}
+	m.lastTick = now
+}
+
+// recordPoll updates the station's status after a poll that ended with err.
+func (m *Monitor) recordPoll(stationID string, at time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := &m.stationLocked(stationID).status
+	st.LastPoll = at
+	if err != nil {
+		st.LastError = err.Error()
+		st.ConsecutiveFailures++
+		return
+	}
+	st.LastSuccess = at
+	st.LastError = ""
+	st.ConsecutiveFailures = 0
+}
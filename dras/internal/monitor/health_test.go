@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestHealthTracksPolls(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	m := New(radarMock, notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX,KRAX",
+		CheckInterval: 5 * time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	})
+	ctx := context.Background()
+
+	h := m.Health()
+	if h.Started || h.InitialFetchDone || !h.NotifierConfigured {
+		t.Errorf("Health() before Start = %+v, want not started with a notifier", h)
+	}
+	if len(h.Stations) != 2 || h.Stations[0].ID != "KATX" || !h.Stations[0].LastPoll.IsZero() {
+		t.Errorf("stations before polling = %+v, want both, unpolled", h.Stations)
+	}
+
+	radarMock.SetError("KRAX", errors.New("NWS unavailable"))
+	for i := 0; i < 2; i++ {
+		_ = m.processStation(ctx, "KATX")
+		_ = m.processStation(ctx, "KRAX")
+	}
+
+	h = m.Health()
+	katx, krax := h.Stations[0], h.Stations[1]
+	if katx.LastSuccess.IsZero() || katx.LastError != "" || katx.ConsecutiveFailures != 0 {
+		t.Errorf("KATX = %+v, want a success", katx)
+	}
+	if !krax.LastSuccess.IsZero() || krax.LastError == "" || krax.ConsecutiveFailures != 2 {
+		t.Errorf("KRAX = %+v, want two failures", krax)
+	}
+
+	radarMock.ClearError("KRAX")
+	_ = m.processStation(ctx, "KRAX")
+	if krax = m.Health().Stations[1]; krax.LastError != "" || krax.ConsecutiveFailures != 0 {
+		t.Errorf("KRAX after recovering = %+v, want the failures cleared", krax)
+	}
+}
+
+func TestHealthAlive(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h := Health{Started: true, LastTick: now.Add(-9 * time.Minute), PollInterval: 5 * time.Minute}
+	if !h.Alive(now) {
+		t.Error("Alive() = false within twice the interval")
+	}
+	if h.Alive(now.Add(2 * time.Minute)) {
+		t.Error("Alive() = true more than twice the interval after the last tick")
+	}
+	if (Health{PollInterval: time.Minute}).Alive(now) {
+		t.Error("Alive() = true before Start")
+	}
+}
+
+func TestStartMarksInitialFetchDone(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Hour,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !m.Health().InitialFetchDone {
+		if time.Now().After(deadline) {
+			t.Fatal("initial fetch not marked done")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if h := m.Health(); !h.Started || !h.Alive(time.Now()) || h.Stations[0].LastSuccess.IsZero() {
+		t.Errorf("Health() after the initial fetch = %+v", h)
+	}
+	cancel()
+	<-done
+}
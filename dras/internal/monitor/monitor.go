@@ -1,16 +1,24 @@
 package monitor
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/events"
 	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/metrics"
 	"github.com/jacaudi/dras/internal/notify"
 	"github.com/jacaudi/dras/internal/radar"
+	"github.com/jacaudi/dras/internal/state"
 )
 
 // Monitor handles the monitoring logic for radar stations.
@@ -18,77 +26,496 @@ type Monitor struct {
 	radarService  radar.DataFetcher
 	notifyService notify.Notifier
 	imageService  image.Source
-	config        *config.Config
-	radarDataMap  map[string]map[string]interface{}
-	mu            sync.Mutex
+	// config is swapped atomically by Reload; read it through cfg().
+	config atomic.Pointer[config.Config]
+	// templates are the parsed notification templates from config.
+	templates  atomic.Pointer[message.Templates]
+	stateStore state.Store
+	// stationNotifiers routes individual stations to their own notifier
+	// (e.g. a per-station Pushover recipient). Stations without an entry
+	// use notifyService.
+	stationNotifiers map[string]notify.Notifier
+	// metrics records polls, fetches, changes and image fetches; nil
+	// disables them.
+	metrics *metrics.Metrics
+	// events receives startup snapshots, reported changes and poll errors
+	// for live subscribers; nil disables them.
+	events       *events.Broker
+	radarDataMap map[string]map[string]interface{}
+	// lastPolled records when each station was last scheduled, so stations
+	// with a longer check interval than the poll tick are skipped until due.
+	lastPolled map[string]time.Time
+	// stations holds each station's poll status, change history and last
+	// image, for Health and the station API.
+	stations map[string]*stationRecord
+	// startedAt, lastTick and initialFetchDone back Health.
+	startedAt        time.Time
+	lastTick         time.Time
+	initialFetchDone bool
+	// lastDigest is when the last digest was sent; zero before the first.
+	lastDigest time.Time
+	// limiter enforces the notification rate limit and holds what it
+	// holds back.
+	limiter rateLimiter
+	mu      sync.Mutex
+	// reloaded wakes Start after Reload so it can pick up the new station
+	// list and poll interval.
+	reloaded chan struct{}
+
+	// base is the configuration last passed to New or Reload, and runtime
+	// the admin API's changes applied over it; config holds the result.
+	// runtimeMu serializes changes to either.
+	runtimeMu sync.Mutex
+	base      *config.Config
+	runtime   config.Runtime
+}
+
+// Option configures optional Monitor collaborators.
+type Option func(*Monitor)
+
+// WithStateStore persists each station's last-known radar data to store so
+// that a restart resumes from it: no startup notification is sent for a
+// station with persisted state, and anything that changed while dras was
+// down is diffed and alerted against the persisted data.
+func WithStateStore(store state.Store) Option {
+	return func(m *Monitor) {
+		m.stateStore = store
+	}
+}
+
+// WithNotifier replaces the default notifier passed to New. It is mainly
+// useful with Reload when the notification backends change.
+func WithNotifier(n notify.Notifier) Option {
+	return func(m *Monitor) {
+		m.notifyService = n
+	}
+}
+
+// WithStationNotifiers sends notifications for the given stations through
+// their own notifier instead of the default one passed to New.
+func WithStationNotifiers(notifiers map[string]notify.Notifier) Option {
+	return func(m *Monitor) {
+		m.stationNotifiers = notifiers
+	}
+}
+
+// WithMetrics records polls, fetch latency, reported changes, image fetches
+// and each station's radar state in mx.
+func WithMetrics(mx *metrics.Metrics) Option {
+	return func(m *Monitor) {
+		m.metrics = mx
+	}
+}
+
+// WithEvents publishes each station's startup snapshot, reported changes
+// and poll errors to b.
+func WithEvents(b *events.Broker) Option {
+	return func(m *Monitor) {
+		m.events = b
+	}
 }
 
 // New creates a new monitor instance. imageService may be nil to disable
 // fetching and attaching radar images. notifyService may also be nil when
 // running in dry-run mode.
-func New(radarService radar.DataFetcher, notifyService notify.Notifier, imageService image.Source, cfg *config.Config) *Monitor {
-	return &Monitor{
+func New(radarService radar.DataFetcher, notifyService notify.Notifier, imageService image.Source, cfg *config.Config, opts ...Option) *Monitor {
+	m := &Monitor{
 		radarService:  radarService,
 		notifyService: notifyService,
 		imageService:  imageService,
-		config:        cfg,
 		radarDataMap:  make(map[string]map[string]interface{}),
+		lastPolled:    make(map[string]time.Time),
+		stations:      make(map[string]*stationRecord),
+		reloaded:      make(chan struct{}, 1),
+		base:          cfg,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.runtime = m.restoreRuntime()
+	m.config.Store(cfg.WithRuntime(m.runtime))
+	m.templates.Store(parseTemplates(cfg))
+	m.capFreshness(m.cfg())
+	return m
+}
+
+// capFreshness keeps a caching radar service from serving a response for
+// longer than half the poll interval. Half, because a poll can start a
+// little early or late: the next poll must never get the previous one's
+// response from the cache.
+func (m *Monitor) capFreshness(cfg *config.Config) {
+	if c, ok := m.radarService.(radar.FreshnessCapper); ok {
+		c.SetMaxFreshness(cfg.PollInterval() / 2)
+	}
+}
+
+// cfg returns the configuration currently in effect.
+func (m *Monitor) cfg() *config.Config {
+	return m.config.Load()
+}
+
+// Reload swaps in a new configuration while the monitor is running. Stations
+// that are no longer configured are dropped along with their in-memory
+// state; stations that remain keep theirs, so a reload never re-announces
+// them. Newly added stations are polled (and announced) straight away.
+// Alert toggles and per-station settings apply from the next poll.
+//
+// opts replace the collaborators they configure, e.g. WithStationNotifiers
+// for a changed set of per-station recipients. The caller is responsible
+// for validating cfg; Reload applies whatever it is given. Stations added,
+// removed, paused or re-configured through the admin API stay that way.
+func (m *Monitor) Reload(cfg *config.Config, opts ...Option) {
+	m.runtimeMu.Lock()
+	defer m.runtimeMu.Unlock()
+	m.base = cfg
+	m.apply(cfg.WithRuntime(m.runtime), opts...)
+}
+
+// apply swaps in cfg, which already has the runtime changes applied, drops
+// the state of stations it no longer monitors and wakes Start.
+func (m *Monitor) apply(cfg *config.Config, opts ...Option) {
+	keep := make(map[string]bool)
+	for _, id := range cfg.StationIDs() {
+		keep[id] = true
+	}
+
+	prev := m.cfg()
+	m.mu.Lock()
+	for _, opt := range opts {
+		opt(m)
+	}
+	// A resumed station is polled straight away, not when its interval
+	// runs out (see unscheduled).
+	for _, id := range cfg.StationIDs() {
+		if prev.Station(id).Paused && !cfg.Station(id).Paused {
+			delete(m.lastPolled, id)
+		}
+	}
+	for id := range m.radarDataMap {
+		if !keep[id] {
+			delete(m.radarDataMap, id)
+			m.metrics.ForgetStation(id)
+		}
+	}
+	for id := range m.lastPolled {
+		if !keep[id] {
+			delete(m.lastPolled, id)
+		}
+	}
+	for id := range m.stations {
+		if !keep[id] {
+			delete(m.stations, id)
+		}
+	}
+	m.config.Store(cfg)
+	m.templates.Store(parseTemplates(cfg))
+	m.mu.Unlock()
+	m.capFreshness(cfg)
+
+	select {
+	case m.reloaded <- struct{}{}:
+	default:
+		// A reload is already pending; Start reads the latest config anyway.
+	}
+}
+
+// defaultTemplates render the built-in notification wording.
+var defaultTemplates = message.MustParse(message.Config{})
+
+// parseTemplates parses cfg's notification templates. Validate has already
+// rejected bad ones, so an error here means cfg skipped validation; it is
+// logged and the built-in templates are used.
+func parseTemplates(cfg *config.Config) *message.Templates {
+	t, err := message.Parse(cfg.Templates)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Invalid notification template, using the defaults: %v", err))
+		return defaultTemplates
 	}
+	return t
 }
 
 // Start begins the monitoring process with the specified context.
+//
+// Cancelling ctx stops scheduling new polls, but a poll already in progress
+// — including its notifications — runs to completion for up to the
+// configured shutdown grace period before it is cancelled too. Start
+// returns ctx.Err() once that work has drained.
 func (m *Monitor) Start(ctx context.Context) error {
-	var stationIDs []string
+	work, cancelWork := m.drainContext(ctx)
+	defer cancelWork()
 
 	slog.Info("Starting monitoring service")
-	if m.config.DryRun {
-		stationIDs = []string{"KATX", "KRAX"} // Test with Seattle, WA & Raleigh, NC Radar Sites
+	stationIDs := m.cfg().StationIDs()
+	if m.cfg().DryRun {
 		slog.Info(fmt.Sprintf("Running in dry-run mode with test stations: %v", stationIDs))
 	} else {
-		stationIDs = radar.SanitizeStationIDs(m.config.StationInput)
 		slog.Info(fmt.Sprintf("Monitoring %d stations: %v", len(stationIDs), stationIDs))
 	}
 
+	// The ticker runs at the shortest per-station interval; stations with
+	// a longer interval are skipped by dueStations until their turn.
+	interval := m.cfg().PollInterval()
+
 	// Initial fetch
 	slog.Info("Performing initial radar data fetch")
-	m.fetchAndReportRadarData(ctx, stationIDs)
+	m.tick(time.Now())
+	m.fetchAndReportRadarData(work, m.dueStations(stationIDs, time.Now(), interval))
+	m.mu.Lock()
+	m.initialFetchDone = true
+	m.mu.Unlock()
 
 	// Set up ticker for periodic updates
-	ticker := time.NewTicker(m.config.CheckInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	slog.Info(fmt.Sprintf("Monitoring started, checking every %v", m.config.CheckInterval))
+	// The digest has a timer of its own, re-armed after each digest and
+	// on reload.
+	var digest digestSchedule
+	digest.schedule(m.cfg().Digest, time.Now())
+	defer digest.stop()
+
+	slog.Info(fmt.Sprintf("Monitoring started, checking every %v", interval))
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info(fmt.Sprintf("Monitoring stopped: %v", ctx.Err()))
+			m.sendShutdownNotification(work, stationIDs)
 			return ctx.Err()
-		case <-ticker.C:
+		case now := <-ticker.C:
+			if ctx.Err() != nil {
+				// Shutting down; don't start a new poll.
+				continue
+			}
 			slog.Debug("Performing periodic radar data update")
-			m.fetchAndReportRadarData(ctx, stationIDs)
+			m.tick(now)
+			m.fetchAndReportRadarData(work, m.dueStations(stationIDs, now, interval))
+		case now := <-digest.C():
+			if ctx.Err() != nil {
+				continue
+			}
+			m.sendDigest(work, now)
+			digest.schedule(m.cfg().Digest, now)
+		case <-m.reloaded:
+			cfg := m.cfg()
+			newIDs := cfg.StationIDs()
+			added, removed := diffStations(stationIDs, newIDs)
+			stationIDs = newIDs
+			if newInterval := cfg.PollInterval(); newInterval != interval {
+				interval = newInterval
+				ticker.Reset(interval)
+			}
+			slog.Info("Configuration reloaded",
+				"stations", strings.Join(stationIDs, ","),
+				"added", strings.Join(added, ","),
+				"removed", strings.Join(removed, ","),
+				"interval", interval.String(),
+			)
+			if now := time.Now(); !digest.due(now) {
+				digest.schedule(cfg.Digest, now)
+			}
+			// Added and resumed stations have not been scheduled yet;
+			// poll them straight away.
+			if pending := m.unscheduled(stationIDs); len(pending) > 0 {
+				m.fetchAndReportRadarData(work, m.dueStations(pending, time.Now(), interval))
+			}
+		}
+	}
+}
+
+// drainContext returns a context for poll work that is not cancelled with
+// ctx but only once the shutdown grace period has elapsed after it, so that
+// in-flight fetches and notifications can finish. The returned cancel func
+// must be called when the work is done.
+func (m *Monitor) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	work, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		grace := m.cfg().ShutdownGracePeriod
+		if grace <= 0 {
+			cancel()
+			return
+		}
+		slog.Info(fmt.Sprintf("Shutting down, waiting up to %v for in-flight work", grace))
+		time.AfterFunc(grace, cancel)
+	})
+	return work, func() {
+		stop()
+		cancel()
+	}
+}
+
+// sendShutdownNotification tells each notifier's recipients that monitoring
+// of their stations has stopped, when enabled in the config. Failures are
+// logged; there is nothing left to retry them.
+func (m *Monitor) sendShutdownNotification(ctx context.Context, stationIDs []string) {
+	cfg := m.cfg()
+	if !cfg.ShutdownNotify || cfg.DryRun || len(stationIDs) == 0 {
+		return
+	}
+
+	order, byNotifier := m.groupByNotifier(stationIDs)
+	for _, n := range order {
+		ids := byNotifier[n]
+		message := fmt.Sprintf("DRAS shutting down - no longer monitoring %s", strings.Join(ids, ", "))
+		ev := notify.Event{
+			Kind:    notify.EventShutdown,
+			Title:   "DRAS Shutdown",
+			Message: message,
+			Time:    time.Now(),
+		}
+		if err := notify.Send(ctx, n, ev); err != nil {
+			slog.Warn(fmt.Sprintf("Failed to send shutdown notification: %v", err), "stations", strings.Join(ids, ","))
+			continue
 		}
+		slog.Info("Shutdown notification sent", "stations", strings.Join(ids, ","))
 	}
 }
 
+// groupByNotifier groups stations by the notifier that handles them, so
+// that a per-station recipient only hears about its own stations. order
+// lists the notifiers in the order their first station appears; stations
+// without a notifier are left out.
+func (m *Monitor) groupByNotifier(stationIDs []string) (order []notify.Notifier, byNotifier map[notify.Notifier][]string) {
+	byNotifier = make(map[notify.Notifier][]string)
+	for _, id := range stationIDs {
+		n := m.notifierFor(id)
+		if n == nil {
+			continue
+		}
+		if _, ok := byNotifier[n]; !ok {
+			order = append(order, n)
+		}
+		byNotifier[n] = append(byNotifier[n], id)
+	}
+	return order, byNotifier
+}
+
+// diffStations returns the IDs present in next but not prev, and in prev but
+// not next.
+func diffStations(prev, next []string) (added, removed []string) {
+	inPrev := make(map[string]bool, len(prev))
+	for _, id := range prev {
+		inPrev[id] = true
+	}
+	inNext := make(map[string]bool, len(next))
+	for _, id := range next {
+		inNext[id] = true
+		if !inPrev[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range prev {
+		if !inNext[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+// dueStations returns the stations whose check interval has elapsed since
+// they were last scheduled, and marks them as scheduled at now. Half a tick
+// of slack keeps a station whose interval is a multiple of the tick from
+// slipping a whole tick because of timer jitter. Paused stations are never
+// due.
+func (m *Monitor) dueStations(stationIDs []string, now time.Time, tick time.Duration) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg := m.cfg()
+	due := make([]string, 0, len(stationIDs))
+	for _, id := range stationIDs {
+		if cfg.Station(id).Paused {
+			continue
+		}
+		last, polled := m.lastPolled[id]
+		if polled && now.Sub(last)+tick/2 < cfg.Station(id).CheckInterval {
+			continue
+		}
+		m.lastPolled[id] = now
+		due = append(due, id)
+	}
+	return due
+}
+
+// unscheduled returns the stations that have never been scheduled, or were
+// resumed since.
+func (m *Monitor) unscheduled(stationIDs []string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, id := range stationIDs {
+		if _, ok := m.lastPolled[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
 // fetchAndReportRadarData fetches radar data for a list of station IDs and reports any changes in the data.
 // The fetched data is compared with the last stored data for each station ID, and if there are changes a
 // push notification is sent using the notification service.
 // The radar data is stored in the radarDataMap in memory.
-// Goroutines are used to perform the api call and data processing per station ID
-func (m *Monitor) fetchAndReportRadarData(ctx context.Context, stationIDs []string) {
-	var wg sync.WaitGroup
+// A pool of at most PollConcurrency goroutines performs the api call and data processing per station ID.
+// With NWSBulk set, the data of all the stations is first fetched in one request; stations it
+// did not return are fetched on their own.
+// With BatchThreshold set, the pass's notifications are batched per notifier (see passBatch),
+// and notifications held back by the rate limit are summarized once the pass is done.
+// The stations that failed are logged, and their errors returned joined.
+func (m *Monitor) fetchAndReportRadarData(ctx context.Context, stationIDs []string) error {
+	cfg := m.cfg()
+	prefetched := m.fetchAll(ctx, stationIDs, cfg)
 
-	for _, stationID := range stationIDs {
+	workers := cmp.Or(cfg.PollConcurrency, config.DefaultPollConcurrency)
+	workers = min(workers, len(stationIDs))
+	var batch *passBatch
+	pollCtx := ctx
+	if cfg.BatchThreshold > 0 && len(stationIDs) >= cfg.BatchThreshold {
+		batch = m.newPassBatch(ctx, cfg.BatchThreshold, cfg.BatchWindow, len(stationIDs), workers)
+		pollCtx = withBatch(ctx, batch)
+	}
+	indexes := make(chan int)
+	errs := make([]error, len(stationIDs))
+	var wg sync.WaitGroup
+	for range workers {
 		wg.Add(1)
-		go func(stationID string) {
+		go func() {
 			defer wg.Done()
-			if err := m.processStation(ctx, stationID); err != nil {
-				slog.Error(fmt.Sprintf("Failed to process station: %v", err), "station", stationID)
+			for i := range indexes {
+				stationID := stationIDs[i]
+				if err := m.pollStation(pollCtx, stationID, prefetched[stationID]); err != nil {
+					slog.Error(fmt.Sprintf("Failed to process station: %v", err), "station", stationID)
+					errs[i] = err
+				}
+				batch.done()
 			}
-		}(stationID)
+		}()
+	}
+	for i := range stationIDs {
+		indexes <- i
 	}
+	close(indexes)
 
 	wg.Wait()
+	m.flushOverflow(ctx)
+	return errors.Join(errs...)
+}
+
+// fetchAll fetches the data of stationIDs in one bulk request when NWSBulk
+// is set, more than one station is due and the radar service supports it.
+// It returns nil otherwise, or when the request fails, so that every
+// station is fetched on its own.
+func (m *Monitor) fetchAll(ctx context.Context, stationIDs []string, cfg *config.Config) map[string]*radar.Data {
+	bulk, ok := m.radarService.(radar.BulkFetcher)
+	if !cfg.NWSBulk || !ok || len(stationIDs) < 2 {
+		return nil
+	}
+	start := time.Now()
+	data, err := bulk.FetchAll(ctx, stationIDs)
+	if err != nil {
+		slog.Warn("Bulk fetch of radar data failed, fetching each station instead", "error", err)
+		return nil
+	}
+	slog.Debug("Fetched radar data in bulk", "stations", len(data), "requested", len(stationIDs), "duration", time.Since(start))
+	return data
 }
 
 // processStation handles the processing of a single radar station.
@@ -100,14 +527,56 @@ func (m *Monitor) fetchAndReportRadarData(ctx context.Context, stationIDs []stri
 // station with the 5 min default) just to discard most of them. Only
 // poll the renderer when the result will reach a user.
 func (m *Monitor) processStation(ctx context.Context, stationID string) error {
+	return m.pollStation(ctx, stationID, nil)
+}
+
+// pollStation is processStation with the station's data already fetched,
+// by a bulk request, when prefetched is non-nil.
+func (m *Monitor) pollStation(ctx context.Context, stationID string, prefetched *radar.Data) (err error) {
+	// outcome is updated as the poll progresses and recorded on return.
+	outcome := metrics.PollError
+	polledAt := time.Now()
+	defer func() {
+		m.metrics.ObservePoll(stationID, outcome)
+		m.recordPoll(stationID, polledAt, err)
+		if err != nil {
+			m.events.Publish(events.Event{Type: events.PollError, Station: stationID, Time: polledAt, Error: err.Error()})
+		}
+	}()
+
+	// Resolve the configuration once so a concurrent Reload can't change it
+	// halfway through this station's poll.
+	cfg := m.cfg()
+
 	stationLogger := slog.Default().With("station", stationID)
-	stationLogger.Debug("Fetching radar data")
-	newRadarData, err := m.radarService.FetchData(stationID)
-	if err != nil {
-		return fmt.Errorf("error fetching radar data for station %s: %w", stationID, err)
+	newRadarData := prefetched
+	if newRadarData == nil {
+		stationLogger.Debug("Fetching radar data")
+		fetchStart := time.Now()
+		newRadarData, err = m.radarService.FetchData(ctx, stationID)
+		m.metrics.ObserveFetch(stationID, time.Since(fetchStart))
+		if err != nil {
+			outcome = metrics.PollFetchError
+			outageErr := m.fetchFailed(ctx, stationID, polledAt, err, cfg, stationLogger)
+			return errors.Join(fmt.Errorf("error fetching radar data for station %s: %w", stationID, err), outageErr)
+		}
+	}
+	m.metrics.SetRadarState(stationID, newRadarData)
+	// A failed outage notification is retried on the next poll, as the
+	// outage is only recorded once it is sent. It must not hold up the
+	// station's change alerts meanwhile, so the poll goes on and reports it
+	// on return.
+	if outageErr := m.fetchSucceeded(ctx, stationID, newRadarData, polledAt, cfg, stationLogger); outageErr != nil {
+		stationLogger.Warn(fmt.Sprintf("Outage notification failed, retrying next poll: %v", outageErr))
+		defer func() {
+			outcome = metrics.PollNotifyError
+			err = errors.Join(err, outageErr)
+		}()
 	}
 
-	// Check if we need to initialize or if this is first run
+	// Check if we need to initialize or if this is first run. A station
+	// with nothing in memory may still have persisted state from before a
+	// restart; if so it is compared against that instead of announced.
 	m.mu.Lock()
 	if _, exists := m.radarDataMap[stationID]; !exists {
 		m.radarDataMap[stationID] = make(map[string]interface{})
@@ -115,23 +584,51 @@ func (m *Monitor) processStation(ctx context.Context, stationID string) error {
 	lastRadarData, exists := m.radarDataMap[stationID]["last"]
 	isFirstRun := !exists || lastRadarData == nil
 	if isFirstRun {
-		m.radarDataMap[stationID]["last"] = newRadarData
+		if restored := m.restoreState(stationID, stationLogger); restored != nil {
+			lastRadarData = restored
+			isFirstRun = false
+			m.radarDataMap[stationID]["last"] = restored
+		} else {
+			m.radarDataMap[stationID]["last"] = newRadarData
+		}
 	}
 	m.mu.Unlock()
 
 	// Handle first run outside of mutex
 	if isFirstRun {
+		outcome = metrics.PollStartup
+		now := time.Now()
+		// A radar that is already away from normal opens an incident, so
+		// its return is reported as a recovery.
+		m.mu.Lock()
+		m.stationLocked(stationID).incident = radar.NextIncident(nil, newRadarData, now)
+		m.mu.Unlock()
+		m.persistState(stationID, newRadarData, stationLogger)
+		m.recordHistory(stationID, HistoryEntry{Time: now, Kind: message.Startup, New: newRadarData})
 		initialMessage := fmt.Sprintf("%s %s - %s Mode", stationID, newRadarData.Name, newRadarData.Mode)
 		stationLogger.Info(fmt.Sprintf("Initial radar data stored - %s", initialMessage))
-		if m.config.DryRun {
+		// First run always carries the freshly-rendered image so the user
+		// has visual context the moment the monitor comes online. It is
+		// fetched in dry-run mode too, for the dashboard.
+		radarImage := m.fetchRadarImage(ctx, stationID, stationLogger)
+		if cfg.DryRun {
 			stationLogger.Debug(fmt.Sprintf("Would send startup notification: %s", initialMessage))
 		} else {
-			// First run always carries the freshly-rendered image so
-			// the user has visual context the moment the monitor
-			// comes online.
-			radarImage := m.fetchRadarImage(ctx, stationID, stationLogger)
 			attachment := m.attachmentForStation(stationID, radarImage)
-			if err := m.notifyService.SendNotificationWithAttachment(ctx, "DRAS Startup", initialMessage, attachment); err != nil {
+			title, body := m.render(message.Startup, message.NewData(stationID, nil, newRadarData, nil, now), stationLogger)
+			err := m.send(ctx, stationID, notify.Event{
+				Kind:        notify.EventStartup,
+				StationID:   stationID,
+				StationName: newRadarData.Name,
+				New:         newRadarData,
+				Title:       title,
+				Message:     body,
+				Attachment:  attachment,
+				ImageURL:    m.imageURL(stationID),
+				Time:        now,
+			}, stationLogger)
+			if err != nil {
+				outcome = metrics.PollNotifyError
 				return fmt.Errorf("failed to send startup notification for station %s: %w", stationID, err)
 			}
 			stationLogger.Info("Startup notification sent successfully")
@@ -145,60 +642,316 @@ func (m *Monitor) processStation(ctx context.Context, stationID string) error {
 		return fmt.Errorf("invalid radar data type in cache for station %s", stationID)
 	}
 
-	// Use alert configuration directly (no conversion needed since config uses radar.AlertConfig)
-	alertConfig := m.config.AlertConfig
+	// Per-station alert toggles from the config file win over the global ones.
+	sc := cfg.Station(stationID)
+
+	// Nothing to compare when the station reports what was last reported,
+	// e.g. a cached or 304 Not Modified response, unless a change is still
+	// being debounced or held for quiet hours.
+	if radar.Same(lastData, newRadarData) && !m.hasPending(stationID) {
+		outcome = metrics.PollUnchanged
+		stationLogger.Debug("Radar data unchanged since the last report")
+		return nil
+	}
 
-	changed, changeMessage := radar.CompareData(lastData, newRadarData, alertConfig)
-	if !changed {
+	now := time.Now()
+	if err := m.flushQuiet(ctx, stationID, lastData, now, cfg, stationLogger); err != nil {
+		outcome = metrics.PollNotifyError
+		return err
+	}
+	changes, held := m.debounce(stationID, radar.Diff(lastData, newRadarData, sc.AlertConfig), now, cfg)
+	if len(held) > 0 {
+		stationLogger.Info("Holding radar changes until they are confirmed", "fields", joinFields(held))
+	}
+	if len(changes) == 0 {
+		outcome = metrics.PollUnchanged
 		stationLogger.Debug("No changes detected in radar data")
 		return nil
 	}
+	// Fields still being debounced keep their last reported value, so they
+	// are diffed against it again next poll.
+	reportedData := radar.KeepFields(newRadarData, lastData, held)
 
+	changeMessage := radar.JoinText(changes)
 	slog.Info("Radar data changed",
 		"station", stationID,
 		"station_name", newRadarData.Name,
 		"change", changeMessage,
+		"severity", string(radar.MaxSeverity(changes)),
 	)
 
-	vcpChanged := lastData.VCP != newRadarData.VCP
+	m.mu.Lock()
+	incident := radar.NextIncident(m.openIncidentLocked(stationID, reportedData), reportedData, now)
+	m.mu.Unlock()
 
-	if m.config.DryRun {
-		stationLogger.Debug(fmt.Sprintf("Would send change notification: %s", changeMessage))
-	} else {
-		// Only invoke the image source when the notification will
-		// actually carry an attachment (currently: VCP changes only).
-		// Other changes — power source, mode without VCP shift, etc.
-		// — reach the user as text-only and don't justify a render.
-		var radarImage *image.Image
-		if vcpChanged {
-			radarImage = m.fetchRadarImage(ctx, stationID, stationLogger)
-		}
-		title := fmt.Sprintf("%s Update", stationID)
+	// Quiet hours may hold back or drop some of the changes; the rest are
+	// sent, at low priority when all of them fall in a downgrading window.
+	split := splitQuiet(sc, changes, now)
+	if len(split.suppressed) > 0 {
+		stationLogger.Info("Suppressing changes during quiet hours", "change", radar.JoinText(split.suppressed))
+	}
+	if len(split.queued) > 0 {
+		stationLogger.Info("Holding changes until quiet hours end", "change", radar.JoinText(split.queued))
+	}
+
+	// An ended incident goes with the changes that ended it: sent now as a
+	// recovery, held with them for quiet hours, or dropped with them.
+	ended := incident != nil && !incident.Open()
+	sentIncident := incident
+	if ended && !endsIncident(split.send) {
+		sentIncident = nil
+	}
+	vcpChanged := radar.HasField(split.send, radar.FieldVCP)
+	kind := message.Change
+	if radar.Recovered(split.send) || ended && sentIncident != nil {
+		kind = message.Recovery
+	}
+
+	// Only invoke the image source when the notification will actually
+	// carry an attachment (currently: VCP changes only). Other changes —
+	// power source, mode without VCP shift, etc. — reach the user as
+	// text-only and don't justify a render. As at startup, dry-run mode
+	// still fetches it for the dashboard.
+	var radarImage *image.Image
+	if vcpChanged {
+		radarImage = m.fetchRadarImage(ctx, stationID, stationLogger)
+	}
+	switch {
+	case len(split.send) == 0:
+		// Every change was held back or suppressed for quiet hours.
+	case cfg.DryRun:
+		stationLogger.Debug(fmt.Sprintf("Would send change notification: %s", radar.JoinText(split.send)))
+	default:
+		title, body := m.render(kind, message.NewIncidentData(stationID, lastData, reportedData, split.send, sentIncident, now), stationLogger)
 		attachment := m.attachmentForChange(stationID, vcpChanged, radarImage, stationLogger)
-		if err := m.notifyService.SendNotificationWithAttachment(ctx, title, changeMessage, attachment); err != nil {
+		err := m.send(ctx, stationID, notify.Event{
+			Kind:        notify.EventChange,
+			StationID:   stationID,
+			StationName: newRadarData.Name,
+			Old:         lastData,
+			New:         reportedData,
+			Changes:     split.send,
+			Incident:    sentIncident,
+			Quiet:       split.quiet,
+			Title:       title,
+			Message:     body,
+			Attachment:  attachment,
+			ImageURL:    m.imageURL(stationID),
+			Time:        now,
+		}, stationLogger)
+		if err != nil {
+			outcome = metrics.PollNotifyError
 			return fmt.Errorf("failed to send change notification for station %s: %w", stationID, err)
 		}
 		stationLogger.Info("Change notification sent successfully")
 	}
+	outcome = metrics.PollChanged
+	// Only what was notified is counted and recorded: suppressed changes
+	// are logged above, and queued ones are recorded when they are sent.
+	if len(split.send) > 0 {
+		m.metrics.ObserveChanges(stationID, split.send)
+		m.recordHistory(stationID, HistoryEntry{Time: now, Kind: kind, Old: lastData, New: reportedData, Changes: split.send, Incident: sentIncident})
+	}
+	var recovery *radar.Incident
+	if ended {
+		stationLogger.Info("Incident ended", "duration", incident.Ended.Sub(incident.Started).String(), "states", len(incident.States))
+		if sentIncident == nil && endsIncident(split.queued) {
+			recovery = incident
+		}
+		incident = nil
+	}
 	m.mu.Lock()
-	m.radarDataMap[stationID]["last"] = newRadarData
+	m.radarDataMap[stationID]["last"] = reportedData
+	if ds, ok := m.radarDataMap[stationID]["debounce"].(*debounceState); ok {
+		ds.commit(changes)
+	}
+	rec := m.stationLocked(stationID)
+	rec.incident = incident
+	if len(split.queued) > 0 {
+		m.queueQuietLocked(stationID, lastData, split.queued, now)
+	}
+	if rec.quiet != nil && (recovery != nil || incident != nil) {
+		// A held recovery is replaced, or continued by a reopened incident.
+		rec.quiet.recovery = recovery
+	}
 	m.mu.Unlock()
+	m.persistState(stationID, reportedData, stationLogger)
 
 	return nil
 }
 
+// debounce passes the station's changes through its debounce state (see
+// debounceState.filter) and returns the ones to report now and the fields
+// still pending.
+func (m *Monitor) debounce(stationID string, changes []radar.Change, now time.Time, cfg *config.Config) ([]radar.Change, []radar.Field) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ds, ok := m.radarDataMap[stationID]["debounce"].(*debounceState)
+	if !ok {
+		ds = newDebounceState()
+		m.radarDataMap[stationID]["debounce"] = ds
+	}
+	return ds.filter(changes, now, cfg.DebouncePolls, cfg.DebounceDuration)
+}
+
+// openIncidentLocked returns the station's open incident as of d. One
+// whose recovery is held for quiet hours is reopened when d is abnormal
+// again, so the incident continues rather than a new one opening. m.mu
+// must be held.
+func (m *Monitor) openIncidentLocked(stationID string, d *radar.Data) *radar.Incident {
+	rec := m.stationLocked(stationID)
+	if rec.incident == nil && rec.quiet != nil && rec.quiet.recovery != nil && !radar.Normal(d) {
+		reopened := rec.quiet.recovery.Clone()
+		reopened.Ended = time.Time{}
+		return reopened
+	}
+	return rec.incident
+}
+
+// endsIncident reports whether changes include a change to the fields that
+// open and end incidents.
+func endsIncident(changes []radar.Change) bool {
+	return radar.HasField(changes, radar.FieldStatus) || radar.HasField(changes, radar.FieldOperability)
+}
+
+// hasPending reports whether the station has a change being debounced or
+// held for quiet hours.
+func (m *Monitor) hasPending(stationID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ds, ok := m.radarDataMap[stationID]["debounce"].(*debounceState); ok && len(ds.pending) > 0 {
+		return true
+	}
+	return m.stationLocked(stationID).quiet != nil
+}
+
+func joinFields(fields []radar.Field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ",")
+}
+
+// restoreState returns the persisted radar data for the station, or nil when
+// no state store is configured, nothing was persisted, or the store failed,
+// and restores the station's open incident. m.mu must be held. A store
+// failure is logged and treated as "no state" so the station falls
+// back to a normal startup announcement rather than going unmonitored.
+func (m *Monitor) restoreState(stationID string, stationLogger *slog.Logger) *radar.Data {
+	if m.stateStore == nil {
+		return nil
+	}
+	rec, ok, err := m.stateStore.Load(stationID)
+	if err != nil {
+		stationLogger.Warn(fmt.Sprintf("Failed to load persisted state: %v", err))
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	stationLogger.Info("Restored persisted radar state",
+		"vcp", rec.Data.VCP,
+		"status", rec.Data.Status,
+		"updated_at", rec.UpdatedAt.Format(time.RFC3339),
+	)
+	m.stationLocked(stationID).incident = rec.Incident
+	return rec.Data
+}
+
+// persistState writes the station's last-known radar data and open
+// incident to the state store, if one is configured. Failures are logged, not returned: losing a
+// write only costs a re-announcement after the next restart.
+func (m *Monitor) persistState(stationID string, data *radar.Data, stationLogger *slog.Logger) {
+	if m.stateStore == nil {
+		return
+	}
+	m.mu.Lock()
+	incident := m.stationLocked(stationID).incident.Clone()
+	m.mu.Unlock()
+	if err := m.stateStore.Save(stationID, state.Record{Data: data, Incident: incident, UpdatedAt: time.Now().UTC()}); err != nil {
+		stationLogger.Warn(fmt.Sprintf("Failed to persist radar state: %v", err))
+	}
+}
+
+// render renders the notification title and body for kind. A template that
+// fails on this data is logged and the built-in wording is used instead, so
+// a template mistake never costs a notification.
+func (m *Monitor) render(kind message.Kind, data message.Data, stationLogger *slog.Logger) (title, body string) {
+	title, body, err := m.templates.Load().Render(kind, data)
+	if err == nil {
+		return title, body
+	}
+	stationLogger.Warn(fmt.Sprintf("Failed to render notification template, using the default: %v", err))
+	title, body, err = defaultTemplates.Render(kind, data)
+	if err != nil {
+		// The defaults only use fields that are always set; fall back to
+		// the raw change text all the same.
+		return data.StationID, data.Summary
+	}
+	return title, body
+}
+
+// deliveryError returns err unless it is a fan-out that reached at least
+// one backend. A partial delivery is logged and counts as sent: treating it
+// as a failure would re-send the change to every backend that already has
+// it on the next poll.
+func deliveryError(err error, stationLogger *slog.Logger) error {
+	var partial *notify.DeliveryError
+	if errors.As(err, &partial) && len(partial.Delivered) > 0 {
+		stationLogger.Warn(fmt.Sprintf("Notification not delivered to every backend: %v", err),
+			"delivered", strings.Join(partial.Delivered, ","))
+		return nil
+	}
+	return err
+}
+
+// notifierFor returns the notifier that handles the station's notifications.
+func (m *Monitor) notifierFor(stationID string) notify.Notifier {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n, ok := m.stationNotifiers[stationID]; ok {
+		return n
+	}
+	return m.notifyService
+}
+
+// imagesEnabled reports whether an image source is configured and the
+// station has not opted out of image attachments.
+func (m *Monitor) imagesEnabled(stationID string) bool {
+	return m.imageService != nil && m.cfg().Station(stationID).ImageEnabled
+}
+
+// imageURL returns a public URL of the station's radar image when the image
+// source has one (the basic-mode ridge GIF does; the renderer does not).
+func (m *Monitor) imageURL(stationID string) string {
+	if !m.imagesEnabled(stationID) {
+		return ""
+	}
+	if src, ok := m.imageService.(interface{ URLFor(string) string }); ok {
+		return src.URLFor(stationID)
+	}
+	return ""
+}
+
 // fetchRadarImage downloads and caches the latest radar image for the given
 // station. Returns nil if image fetching is disabled or the download fails.
 func (m *Monitor) fetchRadarImage(ctx context.Context, stationID string, stationLogger *slog.Logger) *image.Image {
-	if m.imageService == nil {
+	if !m.imagesEnabled(stationID) {
 		return nil
 	}
 
 	img, err := m.imageService.Fetch(ctx, stationID)
+	size := 0
+	if img != nil {
+		size = len(img.Data)
+	}
+	m.metrics.ObserveImage(size, err)
 	if err != nil {
 		stationLogger.Warn(fmt.Sprintf("Failed to fetch radar image: %v", err))
 		return nil
 	}
+	m.recordImage(stationID, img)
 	return img
 }
 
@@ -221,7 +974,7 @@ func (m *Monitor) attachmentForChange(stationID string, vcpChanged bool, justFet
 // cache if the just-fetched image is nil, and returns nil when no image is
 // available or image polling is disabled.
 func (m *Monitor) attachmentForStation(stationID string, justFetched *image.Image) *notify.Attachment {
-	if m.imageService == nil {
+	if !m.imagesEnabled(stationID) {
 		return nil
 	}
 
@@ -4,18 +4,25 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/jacaudi/dras/internal/config"
 	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/metrics"
 	"github.com/jacaudi/dras/internal/notify"
 	"github.com/jacaudi/dras/internal/radar"
 	"github.com/jacaudi/dras/internal/renderer"
+	"github.com/jacaudi/dras/internal/state"
 )
 
 func TestFetchRadarImageNilService(t *testing.T) {
@@ -472,3 +479,607 @@ func TestRendererModeDeliversAttachment(t *testing.T) {
 		t.Errorf("expected 2 renderer requests, got %d", got)
 	}
 }
+
+// TestPersistedStateSuppressesStartupAndDiffsDowntimeChanges simulates a
+// restart: a first monitor writes state to disk, a second monitor built on
+// the same file must not re-announce the station, and must alert on a VCP
+// change that happened between the two processes.
+func TestPersistedStateSuppressesStartupAndDiffsDowntimeChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	}
+	seattle := func(vcp, mode string) *radar.Data {
+		return &radar.Data{
+			Name: "Seattle", VCP: vcp, Mode: mode,
+			Status: "Online", OperabilityStatus: "Normal",
+			PowerSource: "Utility", GenState: "Off",
+		}
+	}
+
+	// First process: startup announcement, state persisted.
+	store, err := state.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() error: %v", err)
+	}
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", seattle("R31", "Clear Air"))
+	firstNotify := notify.NewMockNotifier()
+	m := New(radarMock, firstNotify, nil, cfg, WithStateStore(store))
+	if err := m.processStation(t.Context(), "KATX"); err != nil {
+		t.Fatalf("first processStation() error: %v", err)
+	}
+	if !firstNotify.HasNotification("DRAS Startup") {
+		t.Fatalf("expected startup notification from first process, got %+v", firstNotify.GetNotifications())
+	}
+
+	// Second process, same data: silent.
+	store, err = state.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error: %v", err)
+	}
+	secondNotify := notify.NewMockNotifier()
+	m = New(radarMock, secondNotify, nil, cfg, WithStateStore(store))
+	if err := m.processStation(t.Context(), "KATX"); err != nil {
+		t.Fatalf("restart processStation() error: %v", err)
+	}
+	if got := secondNotify.GetNotifications(); len(got) != 0 {
+		t.Fatalf("expected no notifications after restart with unchanged data, got %+v", got)
+	}
+
+	// Third process: VCP changed while dras was down.
+	store, err = state.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() reopen error: %v", err)
+	}
+	radarMock.SetResponse("KATX", seattle("R12", "Precipitation"))
+	thirdNotify := notify.NewMockNotifier()
+	m = New(radarMock, thirdNotify, nil, cfg, WithStateStore(store))
+	if err := m.processStation(t.Context(), "KATX"); err != nil {
+		t.Fatalf("restart processStation() error: %v", err)
+	}
+	notifs := thirdNotify.GetNotifications()
+	if len(notifs) != 1 || notifs[0].Title != "KATX Update" {
+		t.Fatalf("expected one change notification after downtime change, got %+v", notifs)
+	}
+
+	rec, ok, err := store.Load("KATX")
+	if err != nil || !ok {
+		t.Fatalf("Load() = ok %v, err %v; want persisted record", ok, err)
+	}
+	if rec.Data.VCP != "R12" {
+		t.Errorf("persisted VCP = %q, want R12 after change", rec.Data.VCP)
+	}
+}
+
+func TestDueStationsHonorsPerStationInterval(t *testing.T) {
+	cfg := &config.Config{
+		CheckInterval: 10 * time.Minute,
+		Stations: []config.StationOverride{
+			{ID: "KATX", CheckInterval: 5 * time.Minute},
+		},
+	}
+	m := New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), nil, cfg)
+	stations := []string{"KATX", "KRAX"}
+	tick := 5 * time.Minute
+	start := time.Date(2026, 4, 26, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{0, "KATX,KRAX"},
+		{5*time.Minute + time.Second, "KATX"},
+		// Timer jitter: a tick arriving slightly early still counts.
+		{10*time.Minute - time.Second, "KATX,KRAX"},
+		{15 * time.Minute, "KATX"},
+	}
+	for _, step := range steps {
+		got := strings.Join(m.dueStations(stations, start.Add(step.offset), tick), ",")
+		if got != step.want {
+			t.Errorf("dueStations(+%v) = %q, want %q", step.offset, got, step.want)
+		}
+	}
+}
+
+// TestPerStationOverridesApplyInProcessStation verifies that a station's
+// config-file entry controls its alert toggles, image attachments and
+// notification recipient.
+func TestPerStationOverridesApplyInProcessStation(t *testing.T) {
+	var imageRequests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&imageRequests, 1)
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("img"))
+	}))
+	defer server.Close()
+	imgSvc := image.New(image.Config{URLTemplate: server.URL + "/{station}.gif"})
+
+	disabled, enabled := false, true
+	cfg := &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+		Stations: []config.StationOverride{
+			{ID: "KATX", ImageEnabled: &disabled, Alerts: config.AlertOverride{VCP: &disabled, Status: &enabled}},
+		},
+	}
+
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Online"})
+	defaultNotify := notify.NewMockNotifier()
+	katxNotify := notify.NewMockNotifier()
+	m := New(radarMock, defaultNotify, imgSvc, cfg,
+		WithStationNotifiers(map[string]notify.Notifier{"KATX": katxNotify}))
+	ctx := context.Background()
+
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("first processStation() error: %v", err)
+	}
+
+	// VCP change is muted for KATX; status change is not.
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation", Status: "Online"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("second processStation() error: %v", err)
+	}
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation", Status: "Offline"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("third processStation() error: %v", err)
+	}
+
+	if got := len(defaultNotify.GetNotifications()); got != 0 {
+		t.Errorf("default notifier got %d notifications, want 0 (KATX is routed)", got)
+	}
+	notifs := katxNotify.GetNotifications()
+	if len(notifs) != 2 {
+		t.Fatalf("KATX notifier got %d notifications, want startup + status change: %+v", len(notifs), notifs)
+	}
+	if !strings.Contains(notifs[1].Message, "status changed from Online to Offline") {
+		t.Errorf("change message = %q, want status change", notifs[1].Message)
+	}
+	for _, n := range notifs {
+		if n.Attachment != nil {
+			t.Errorf("notification %q has attachment, want none (images disabled for KATX)", n.Title)
+		}
+	}
+	if got := atomic.LoadInt64(&imageRequests); got != 0 {
+		t.Errorf("imageRequests = %d, want 0 for a station with images disabled", got)
+	}
+}
+
+// TestReloadKeepsStateForRemainingStations verifies that a reload drops
+// removed stations, keeps the last-known data of stations that remain (no
+// second startup notification), and applies the new alert toggles.
+func TestReloadKeepsStateForRemainingStations(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Online"})
+	radarMock.SetResponse("KRAX", &radar.Data{Name: "Raleigh", VCP: "R31", Mode: "Clear Air", Status: "Online"})
+	notifyMock := notify.NewMockNotifier()
+	m := New(radarMock, notifyMock, nil, &config.Config{
+		StationInput:  "KATX,KRAX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	})
+	ctx := context.Background()
+	m.fetchAndReportRadarData(ctx, []string{"KATX", "KRAX"})
+
+	m.Reload(&config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, Status: true},
+	})
+
+	m.mu.Lock()
+	_, kraxKept := m.radarDataMap["KRAX"]
+	_, katxKept := m.radarDataMap["KATX"]
+	m.mu.Unlock()
+	if kraxKept {
+		t.Error("KRAX state kept after it was removed from the config")
+	}
+	if !katxKept {
+		t.Fatal("KATX state dropped although it is still configured")
+	}
+
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Offline"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("processStation() after reload error: %v", err)
+	}
+
+	notifs := notifyMock.GetNotifications()
+	if len(notifs) != 3 {
+		t.Fatalf("got %d notifications, want 2 startups + 1 status change: %+v", len(notifs), notifs)
+	}
+	if notifs[2].Title != "KATX Update" || !strings.Contains(notifs[2].Message, "status changed from Online to Offline") {
+		t.Errorf("post-reload notification = %q / %q, want KATX status change", notifs[2].Title, notifs[2].Message)
+	}
+}
+
+// cappedFetcher records the freshness caps the monitor sets.
+type cappedFetcher struct {
+	*radar.MockDataFetcher
+	caps []time.Duration
+}
+
+func (f *cappedFetcher) SetMaxFreshness(d time.Duration) { f.caps = append(f.caps, d) }
+
+func TestCapsRadarCacheFreshnessAtPollInterval(t *testing.T) {
+	fetcher := &cappedFetcher{MockDataFetcher: radar.NewMockDataFetcher()}
+	m := New(fetcher, nil, nil, &config.Config{StationInput: "KATX", CheckInterval: 4 * time.Minute})
+	m.Reload(&config.Config{StationInput: "KATX", CheckInterval: time.Minute})
+
+	if len(fetcher.caps) != 2 || fetcher.caps[0] != 2*time.Minute || fetcher.caps[1] != 30*time.Second {
+		t.Errorf("freshness caps = %v, want half of each poll interval", fetcher.caps)
+	}
+}
+
+func TestDiffStations(t *testing.T) {
+	added, removed := diffStations([]string{"KATX", "KRAX", "KLOT"}, []string{"KRAX", "KMUX", "KATX"})
+	if strings.Join(added, ",") != "KMUX" {
+		t.Errorf("added = %v, want [KMUX]", added)
+	}
+	if strings.Join(removed, ",") != "KLOT" {
+		t.Errorf("removed = %v, want [KLOT]", removed)
+	}
+}
+
+// blockingNotifier holds every send until release is closed or the send's
+// context is cancelled, and records how each send ended.
+type blockingNotifier struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu      sync.Mutex
+	titles  []string
+	results []error
+}
+
+func newBlockingNotifier() *blockingNotifier {
+	return &blockingNotifier{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (b *blockingNotifier) SendNotification(ctx context.Context, title, message string) error {
+	return b.SendNotificationWithAttachment(ctx, title, message, nil)
+}
+
+func (b *blockingNotifier) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *notify.Attachment) error {
+	b.started <- struct{}{}
+	var err error
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	b.mu.Lock()
+	b.titles = append(b.titles, title)
+	b.results = append(b.results, err)
+	b.mu.Unlock()
+	return err
+}
+
+func (b *blockingNotifier) sent() ([]string, []error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.titles...), append([]error(nil), b.results...)
+}
+
+// TestStartDrainsInFlightNotificationsOnShutdown verifies that cancelling
+// Start's context lets a notification that is already being sent finish,
+// and then sends the optional shutdown notification.
+func TestStartDrainsInFlightNotificationsOnShutdown(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	notifier := newBlockingNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:        "KATX",
+		CheckInterval:       time.Minute,
+		AlertConfig:         radar.AlertConfig{VCP: true},
+		ShutdownGracePeriod: 5 * time.Second,
+		ShutdownNotify:      true,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	<-notifier.started // startup notification in flight
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(notifier.release)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Start() = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return after cancel")
+	}
+
+	titles, results := notifier.sent()
+	if strings.Join(titles, ",") != "DRAS Startup,DRAS Shutdown" {
+		t.Fatalf("sent titles = %v, want startup then shutdown", titles)
+	}
+	for i, err := range results {
+		if err != nil {
+			t.Errorf("%s send ended with %v, want it to complete", titles[i], err)
+		}
+	}
+}
+
+// TestStartCancelsInFlightWorkAfterGracePeriod verifies that work still
+// running when the grace period ends is cancelled rather than waited on.
+func TestStartCancelsInFlightWorkAfterGracePeriod(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	notifier := newBlockingNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:        "KATX",
+		CheckInterval:       time.Minute,
+		ShutdownGracePeriod: 50 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Start(ctx) }()
+
+	<-notifier.started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start() did not return after the grace period")
+	}
+
+	titles, results := notifier.sent()
+	if len(titles) != 1 || !errors.Is(results[0], context.Canceled) {
+		t.Errorf("sent = %v / %v, want the startup send cancelled and no shutdown notification", titles, results)
+	}
+}
+
+// TestPartialDeliveryCountsAsSent verifies that a change delivered to some
+// but not all backends is not re-sent on the next poll.
+func TestPartialDeliveryCountsAsSent(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	healthy, failing := notify.NewMockNotifier(), notify.NewMockNotifier()
+	failing.SetShouldError(true)
+	multi := notify.NewMulti(
+		notify.Target{Name: "ntfy", Notifier: healthy},
+		notify.Target{Name: "slack", Notifier: failing},
+	)
+	m := New(radarMock, multi, nil, &config.Config{CheckInterval: time.Minute, AlertConfig: radar.AlertConfig{VCP: true}})
+	ctx := context.Background()
+
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("startup processStation() error: %v", err)
+	}
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation"})
+	for i := 0; i < 2; i++ {
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("poll %d processStation() error: %v", i, err)
+		}
+	}
+
+	if got := len(healthy.GetNotifications()); got != 2 {
+		t.Errorf("healthy backend got %d notifications, want startup + one change", got)
+	}
+}
+
+// eventNotifier records the structured events the monitor sends.
+type eventNotifier struct {
+	*notify.MockNotifier
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (e *eventNotifier) SendEvent(_ context.Context, ev notify.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, ev)
+	return nil
+}
+
+func TestChangeEventCarriesStructuredData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("img"))
+	}))
+	defer server.Close()
+	imgSvc := image.New(image.Config{URLTemplate: server.URL + "/{station}.gif"})
+
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radarMock, rec, imgSvc, &config.Config{
+		CheckInterval:     time.Minute,
+		AlertConfig:       radar.AlertConfig{VCP: true},
+		RadarImageEnabled: true,
+	})
+	ctx := context.Background()
+
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("startup processStation() error: %v", err)
+	}
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("change processStation() error: %v", err)
+	}
+
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events, want startup + change", len(rec.events))
+	}
+	if rec.events[0].Kind != notify.EventStartup || rec.events[0].Old != nil {
+		t.Errorf("startup event = %+v", rec.events[0])
+	}
+	change := rec.events[1]
+	if change.Kind != notify.EventChange || change.StationID != "KATX" || change.StationName != "Seattle" {
+		t.Errorf("change event header = %+v", change)
+	}
+	if change.Old == nil || change.Old.VCP != "R31" || change.New == nil || change.New.VCP != "R12" {
+		t.Errorf("change old/new = %+v / %+v", change.Old, change.New)
+	}
+	if len(change.Changes) == 0 || change.Attachment == nil {
+		t.Errorf("change event missing changes or attachment: %+v", change)
+	}
+	if change.ImageURL != server.URL+"/KATX.gif" {
+		t.Errorf("ImageURL = %q, want the ridge URL", change.ImageURL)
+	}
+	if rec.GetCallCount() != 0 {
+		t.Error("event notifier also received text notifications")
+	}
+}
+
+func TestNotificationsUseConfiguredTemplates(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate"})
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radarMock, rec, nil, &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, Status: true},
+		Templates: message.Config{
+			Startup:  message.Template{Title: "{{.StationID}} online", Body: "{{.VCPInfo.Description}}"},
+			Change:   message.Template{Body: "{{.StationID}} [{{.Severity}}] {{.Summary}}"},
+			Recovery: message.Template{Title: "{{.StationID}} recovered"},
+		},
+	})
+	ctx := context.Background()
+
+	for _, data := range []*radar.Data{
+		{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate"},
+		{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Standby"},
+		{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate"},
+	} {
+		radarMock.SetResponse("KATX", data)
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+
+	want := []struct{ title, message string }{
+		{"KATX online", "Clear Air, long pulse (~10 min cycle, stratiform/biological targets)"},
+		{"KATX Update", "KATX [critical] Radar status changed from Operate to Standby"},
+		{"KATX recovered", "KATX [info] Radar status changed from Standby to Operate\nBack to normal after 0s: Standby"},
+	}
+	if len(rec.events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(rec.events), len(want))
+	}
+	for i, w := range want {
+		if ev := rec.events[i]; ev.Title != w.title || ev.Message != w.message {
+			t.Errorf("event %d = %q / %q, want %q / %q", i, ev.Title, ev.Message, w.title, w.message)
+		}
+	}
+}
+
+func TestIncidentEndsWithRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{Status: true, Operability: true},
+	}
+	seattle := func(status, operability string) *radar.Data {
+		return &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: status, OperabilityStatus: operability}
+	}
+	radarMock := radar.NewMockDataFetcher()
+	newMonitor := func(n notify.Notifier) *Monitor {
+		store, err := state.NewFileStore(path)
+		if err != nil {
+			t.Fatalf("NewFileStore() error: %v", err)
+		}
+		return New(radarMock, n, nil, cfg, WithStateStore(store))
+	}
+	poll := func(m *Monitor, data *radar.Data) {
+		t.Helper()
+		radarMock.SetResponse("KATX", data)
+		if err := m.processStation(t.Context(), "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+
+	first := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := newMonitor(first)
+	poll(m, seattle("Operate", "RDA - On-line"))
+	poll(m, seattle("Standby", "RDA - On-line"))
+	st, _ := m.Station("KATX")
+	if st.Incident == nil || len(st.Incident.States) != 1 || st.Incident.States[0].Status != "Standby" {
+		t.Fatalf("incident = %+v, want one opened by Standby", st.Incident)
+	}
+	if ev := first.events[1]; ev.Incident == nil || !ev.Incident.Open() {
+		t.Errorf("change event incident = %+v, want the open one", ev.Incident)
+	}
+
+	// The open incident survives a restart.
+	second := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m = newMonitor(second)
+	poll(m, seattle("Operate", "RDA - Maintenance Required"))
+	poll(m, seattle("Operate", "RDA - On-line"))
+
+	if len(second.events) != 2 {
+		t.Fatalf("got %d events after restart, want two changes", len(second.events))
+	}
+	recovery := second.events[1]
+	if recovery.Incident == nil || recovery.Incident.Open() || len(recovery.Incident.States) != 2 {
+		t.Fatalf("recovery incident = %+v, want the ended one with both states", recovery.Incident)
+	}
+	want := "Back to normal after 0s: Standby / RDA - On-line → Operate / RDA - Maintenance Required"
+	if recovery.Title != "KATX Update" || !strings.HasSuffix(recovery.Message, "\n"+want) {
+		t.Errorf("recovery = %q / %q, want the incident summary", recovery.Title, recovery.Message)
+	}
+	if st, _ := m.Station("KATX"); st.Incident != nil {
+		t.Errorf("incident = %+v after recovery", st.Incident)
+	}
+	history, _ := m.History("KATX")
+	if len(history) == 0 || history[0].Kind != message.Recovery || history[0].Incident == nil {
+		t.Errorf("history = %+v, want the recovery with its incident", history)
+	}
+	store, _ := state.NewFileStore(path)
+	if rec, _, _ := store.Load("KATX"); rec.Incident != nil {
+		t.Errorf("persisted incident = %+v after recovery", rec.Incident)
+	}
+}
+
+func TestProcessStationRecordsMetrics(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	mx := metrics.New()
+	m := New(radarMock, notify.NewMockNotifier(), nil, &config.Config{
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	}, WithMetrics(mx))
+	ctx := context.Background()
+
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+	for i := 0; i < 2; i++ {
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R215", Mode: "Precipitation", Status: "Operate"})
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("processStation() error: %v", err)
+	}
+	radarMock.SetError("KATX", errors.New("NWS unavailable"))
+	if err := m.processStation(ctx, "KATX"); err == nil {
+		t.Fatal("processStation() error = nil, want the fetch error")
+	}
+
+	rec := httptest.NewRecorder()
+	mx.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`dras_polls_total{outcome="startup",station="KATX"} 1`,
+		`dras_polls_total{outcome="unchanged",station="KATX"} 1`,
+		`dras_polls_total{outcome="changed",station="KATX"} 1`,
+		`dras_polls_total{outcome="fetch_error",station="KATX"} 1`,
+		`dras_fetch_duration_seconds_count{station="KATX"} 4`,
+		`dras_changes_total{field="vcp",station="KATX"} 1`,
+		`dras_radar_info{mode="Precipitation",station="KATX",status="Operate",vcp="R215"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	if strings.Contains(body, `vcp="R35"`) {
+		t.Error("metrics still report the previous radar state")
+	}
+}
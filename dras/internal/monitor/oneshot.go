@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+// RunOnce polls every monitored station that is not paused, once and
+// concurrently, exactly as a round of Start's loop would: changes are
+// notified and state is persisted. It returns the errors of the stations
+// that failed, joined. Unlike Start it sends no shutdown notification.
+// Per-station check intervals are ignored: every station is due.
+func (m *Monitor) RunOnce(ctx context.Context) error {
+	cfg := m.cfg()
+	var stationIDs []string
+	for _, id := range cfg.StationIDs() {
+		if !cfg.Station(id).Paused {
+			stationIDs = append(stationIDs, id)
+		}
+	}
+	return m.fetchAndReportRadarData(ctx, stationIDs)
+}
+
+// SendTestNotification sends a sample notification for the station through
+// the notifier that handles it, so delivery can be checked end to end. The
+// station's current radar data and image are included when they can be
+// fetched; the station's state and history are left untouched.
+func (m *Monitor) SendTestNotification(ctx context.Context, stationID string) error {
+	n := m.notifierFor(stationID)
+	if n == nil {
+		return errors.New("no notifier configured")
+	}
+
+	stationLogger := slog.Default().With("station", stationID)
+	ev := notify.Event{
+		Kind:      notify.EventTest,
+		StationID: stationID,
+		Title:     fmt.Sprintf("DRAS test notification (%s)", stationID),
+		Message:   fmt.Sprintf("This is a test notification from DRAS for %s.", stationID),
+		ImageURL:  m.imageURL(stationID),
+		Time:      time.Now(),
+	}
+	data, err := m.radarService.FetchData(ctx, stationID)
+	if err != nil {
+		stationLogger.Warn(fmt.Sprintf("Failed to fetch radar data, sending the test notification without it: %v", err))
+	} else {
+		ev.StationName = data.Name
+		ev.New = data
+		ev.Message = fmt.Sprintf("This is a test notification from DRAS for %s %s: %s Mode (VCP %s), status %s.",
+			stationID, data.Name, data.Mode, data.VCP, data.Status)
+	}
+	ev.Attachment = m.attachmentForStation(stationID, m.fetchRadarImage(ctx, stationID, stationLogger))
+
+	if err := notify.Send(ctx, n, ev); err != nil {
+		return fmt.Errorf("failed to send test notification for station %s: %w", stationID, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestRunOnce(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	radarMock.SetError("KRAX", errors.New("boom"))
+	radarMock.SetResponse("KLOT", &radar.Data{Name: "Chicago", VCP: "R31", Mode: "Clear Air"})
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:  "KATX,KRAX,KLOT",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	})
+	if err := m.PauseStation("KLOT"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.RunOnce(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "KRAX") {
+		t.Fatalf("RunOnce() error = %v, want the KRAX failure", err)
+	}
+	if strings.Contains(err.Error(), "KATX") {
+		t.Errorf("RunOnce() error = %v, mentions the station that succeeded", err)
+	}
+	if notifier.GetCallCount() != 1 || !strings.HasPrefix(notifier.GetLastNotification().Message, "KATX") {
+		t.Errorf("notifications = %+v, want only the KATX startup", notifier.GetNotifications())
+	}
+	if st, _ := m.Station("KLOT"); !st.LastPoll.IsZero() {
+		t.Error("paused station was polled")
+	}
+
+	radarMock.ClearError("KRAX")
+	radarMock.SetResponse("KRAX", &radar.Data{Name: "Raleigh", VCP: "R31", Mode: "Clear Air"})
+	if err := m.RunOnce(context.Background()); err != nil {
+		t.Errorf("second RunOnce() error = %v", err)
+	}
+	if notifier.GetCallCount() != 2 {
+		t.Errorf("got %d notifications, want the KRAX startup too", notifier.GetCallCount())
+	}
+}
+
+func TestSendTestNotification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("img"))
+	}))
+	defer server.Close()
+	imgSvc := image.New(image.Config{URLTemplate: server.URL + "/{station}.gif"})
+
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate"})
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radarMock, rec, imgSvc, &config.Config{
+		StationInput:      "KATX",
+		CheckInterval:     time.Minute,
+		RadarImageEnabled: true,
+	})
+
+	if err := m.SendTestNotification(context.Background(), "KATX"); err != nil {
+		t.Fatalf("SendTestNotification() error: %v", err)
+	}
+	if len(rec.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(rec.events))
+	}
+	ev := rec.events[0]
+	if ev.Kind != notify.EventTest || ev.StationID != "KATX" || ev.New == nil || ev.New.VCP != "R31" {
+		t.Errorf("event = %+v", ev)
+	}
+	if ev.Attachment == nil || string(ev.Attachment.Data) != "img" {
+		t.Errorf("event attachment = %+v, want the radar image", ev.Attachment)
+	}
+	if !strings.Contains(ev.Message, "Clear Air Mode (VCP R31)") {
+		t.Errorf("message = %q", ev.Message)
+	}
+	if history, _ := m.History("KATX"); len(history) != 0 {
+		t.Errorf("test notification recorded history: %+v", history)
+	}
+
+	// Without radar data the notification still goes out.
+	radarMock.SetError("KATX", errors.New("boom"))
+	if err := m.SendTestNotification(context.Background(), "KATX"); err != nil {
+		t.Fatalf("SendTestNotification() without data error: %v", err)
+	}
+	if len(rec.events) != 2 || rec.events[1].New != nil {
+		t.Errorf("events = %+v, want a second one without data", rec.events)
+	}
+}
+
+func TestSendTestNotificationWithoutNotifier(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), nil, nil, &config.Config{StationInput: "KATX", DryRun: true})
+	if err := m.SendTestNotification(context.Background(), "KATX"); err == nil {
+		t.Error("SendTestNotification() without a notifier succeeded")
+	}
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// fetchFailed records a failed fetch of the station's data. Once
+// UnreachableAfter fetches in a row have failed, it reports the station
+// unreachable.
+func (m *Monitor) fetchFailed(ctx context.Context, stationID string, now time.Time, fetchErr error, cfg *config.Config, stationLogger *slog.Logger) error {
+	m.mu.Lock()
+	rec := m.stationLocked(stationID)
+	rec.fetchFailures++
+	if rec.fetchFailures == 1 {
+		rec.firstFetchFailure = now
+	}
+	var outage *radar.Outage
+	if rec.status.Outage == nil && cfg.UnreachableAfter > 0 && rec.fetchFailures >= cfg.UnreachableAfter {
+		outage = &radar.Outage{
+			Reason:    radar.OutageUnreachable,
+			Since:     rec.firstFetchFailure,
+			Failures:  rec.fetchFailures,
+			LastError: fetchErr.Error(),
+		}
+	}
+	last, _ := m.radarDataMap[stationID]["last"].(*radar.Data)
+	m.mu.Unlock()
+
+	if outage == nil {
+		return nil
+	}
+	return m.reportOutage(ctx, stationID, *outage, last, now, cfg, stationLogger)
+}
+
+// fetchSucceeded records a successful fetch of the station's data. It
+// reports the station stale when the NWS has not updated the data for
+// StaleAfter, and reporting again when it was in an outage and the data is
+// current.
+func (m *Monitor) fetchSucceeded(ctx context.Context, stationID string, data *radar.Data, now time.Time, cfg *config.Config, stationLogger *slog.Logger) error {
+	stale := cfg.StaleAfter > 0 && !data.UpdatedAt.IsZero() && now.Sub(data.UpdatedAt) > cfg.StaleAfter
+
+	m.mu.Lock()
+	rec := m.stationLocked(stationID)
+	rec.fetchFailures = 0
+	current := rec.status.Outage
+	if current != nil && stale && current.Reason == radar.OutageUnreachable {
+		// The station answers again but has nothing new: the outage goes
+		// on, without another notification.
+		rec.status.Outage = &radar.Outage{Reason: radar.OutageStale, Since: current.Since}
+	}
+	m.mu.Unlock()
+
+	switch {
+	case current == nil && stale:
+		return m.reportOutage(ctx, stationID, radar.Outage{Reason: radar.OutageStale, Since: data.UpdatedAt}, data, now, cfg, stationLogger)
+	case current != nil && !stale:
+		ended := *current
+		ended.Ended = now
+		return m.reportOutage(ctx, stationID, ended, data, now, cfg, stationLogger)
+	}
+	return nil
+}
+
+// reportOutage notifies that the station stopped reporting or, once
+// outage.Ended is set, that it reports again, and records it in the
+// station's status and history. data is the station's latest data, if
+// any. The outage is only recorded once the notification is delivered, so
+// a failed one is retried on the next poll.
+func (m *Monitor) reportOutage(ctx context.Context, stationID string, outage radar.Outage, data *radar.Data, now time.Time, cfg *config.Config, stationLogger *slog.Logger) error {
+	kind, eventKind, label := message.Outage, notify.EventOutage, "Outage"
+	if outage.Ended.IsZero() {
+		stationLogger.Warn("Station is not reporting", "reason", string(outage.Reason), "since", outage.Since.Format(time.RFC3339))
+	} else {
+		kind, eventKind, label = message.Resumed, notify.EventResumed, "Resumed"
+		stationLogger.Info("Station is reporting again", "reason", string(outage.Reason), "since", outage.Since.Format(time.RFC3339))
+	}
+
+	if cfg.DryRun {
+		stationLogger.Debug(fmt.Sprintf("Would send %s notification: %s", kind, outage.Text()))
+	} else {
+		var stationName string
+		if data != nil {
+			stationName = data.Name
+		}
+		title, body := m.render(kind, message.NewOutageData(stationID, data, outage, now), stationLogger)
+		err := m.send(ctx, stationID, notify.Event{
+			Kind:        eventKind,
+			StationID:   stationID,
+			StationName: stationName,
+			New:         data,
+			Outage:      &outage,
+			Title:       title,
+			Message:     body,
+			Time:        now,
+		}, stationLogger)
+		if err != nil {
+			return fmt.Errorf("failed to send %s notification for station %s: %w", kind, stationID, err)
+		}
+		stationLogger.Info(label + " notification sent successfully")
+	}
+
+	m.mu.Lock()
+	st := &m.stationLocked(stationID).status
+	st.Outage = &outage
+	if !outage.Ended.IsZero() {
+		st.Outage = nil
+	}
+	m.mu.Unlock()
+	m.recordHistory(stationID, HistoryEntry{Time: now, Kind: kind, New: data, Outage: &outage})
+	return nil
+}
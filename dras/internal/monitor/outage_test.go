@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestUnreachableOutage(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:     "KATX",
+		CheckInterval:    time.Minute,
+		AlertConfig:      radar.AlertConfig{VCP: true},
+		UnreachableAfter: 2,
+	})
+
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("first poll error: %v", err)
+	}
+	notifier.ClearNotifications()
+
+	radarMock.SetError("KATX", errors.New("NWS API returned status 503"))
+	for i := range 3 {
+		if err := m.processStation(ctx, "KATX"); err == nil {
+			t.Fatalf("poll %d succeeded while the fetch fails", i)
+		}
+	}
+	// One notification after the second failure, none for the third.
+	if got := notifier.GetNotifications(); len(got) != 1 || got[0].Title != "KATX Not Reporting" || !strings.Contains(got[0].Message, "2 times in a row") {
+		t.Fatalf("notifications = %+v, want one outage", got)
+	}
+	st, _ := m.Station("KATX")
+	if st.Outage == nil || st.Outage.Reason != radar.OutageUnreachable {
+		t.Errorf("station outage = %+v, want unreachable", st.Outage)
+	}
+
+	radarMock.ClearError("KATX")
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("recovered poll error: %v", err)
+	}
+	last := notifier.GetLastNotification()
+	if len(notifier.GetNotifications()) != 2 || last.Title != "KATX Reporting Again" || !strings.Contains(last.Message, "unreachable for") {
+		t.Errorf("notifications = %+v, want a resumed one", notifier.GetNotifications())
+	}
+	if st, _ := m.Station("KATX"); st.Outage != nil {
+		t.Errorf("station outage = %+v after recovery", st.Outage)
+	}
+	history, _ := m.History("KATX")
+	if len(history) < 2 || history[0].Kind != message.Resumed || history[1].Kind != message.Outage {
+		t.Errorf("history = %+v, want outage then resumed", history)
+	}
+}
+
+func TestUnreachableOutageRetriedAfterFailedNotification(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetError("KATX", errors.New("boom"))
+	notifier := notify.NewMockNotifier()
+	notifier.SetError("KATX Not Reporting", errors.New("pushover down"))
+	m := New(radarMock, notifier, nil, &config.Config{StationInput: "KATX", CheckInterval: time.Minute, UnreachableAfter: 1})
+
+	if err := m.processStation(ctx, "KATX"); err == nil || !strings.Contains(err.Error(), "pushover down") {
+		t.Errorf("poll error = %v, want the fetch and notification errors", err)
+	}
+	if st, _ := m.Station("KATX"); st.Outage != nil {
+		t.Fatal("outage recorded although its notification failed")
+	}
+
+	notifier.SetError("KATX Not Reporting", nil)
+	m.processStation(ctx, "KATX")
+	if st, _ := m.Station("KATX"); st.Outage == nil {
+		t.Error("outage not recorded on the retry")
+	}
+}
+
+func TestFailedResumedNotificationDoesNotHoldUpChanges(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air"})
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:     "KATX",
+		CheckInterval:    time.Minute,
+		AlertConfig:      radar.AlertConfig{VCP: true},
+		UnreachableAfter: 1,
+	})
+	m.processStation(ctx, "KATX")
+	radarMock.SetError("KATX", errors.New("boom"))
+	m.processStation(ctx, "KATX")
+	notifier.ClearNotifications()
+
+	radarMock.ClearError("KATX")
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation"})
+	notifier.SetError("KATX Reporting Again", errors.New("pushover down"))
+	if err := m.processStation(ctx, "KATX"); err == nil || !strings.Contains(err.Error(), "pushover down") {
+		t.Errorf("poll error = %v, want the resumed notification's", err)
+	}
+	if got := notifier.GetNotifications(); len(got) != 1 || !strings.Contains(got[0].Message, "Precipitation Mode Active") {
+		t.Errorf("notifications = %+v, want the VCP change all the same", got)
+	}
+	if st, _ := m.Station("KATX"); st.Outage == nil || st.Data.VCP != "R12" {
+		t.Errorf("station = %+v, want the outage kept for a retry and the change reported", st)
+	}
+
+	notifier.SetError("KATX Reporting Again", nil)
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("retry poll error: %v", err)
+	}
+	if st, _ := m.Station("KATX"); st.Outage != nil {
+		t.Errorf("outage = %+v after the resumed notification was retried", st.Outage)
+	}
+}
+
+func TestStaleOutage(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	stale := &radar.Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", UpdatedAt: time.Now().Add(-3 * time.Hour)}
+	radarMock.SetResponse("KATX", stale)
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+		StaleAfter:    time.Hour,
+	})
+
+	for range 2 {
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("poll error: %v", err)
+		}
+	}
+	var outages int
+	for _, n := range notifier.GetNotifications() {
+		if n.Title == "KATX Not Reporting" {
+			outages++
+			if !strings.Contains(n.Message, "has not been updated since") {
+				t.Errorf("outage message = %q", n.Message)
+			}
+		}
+	}
+	if outages != 1 {
+		t.Errorf("notifications = %+v, want one outage", notifier.GetNotifications())
+	}
+
+	fresh := *stale
+	fresh.UpdatedAt = time.Now()
+	radarMock.SetResponse("KATX", &fresh)
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("poll error: %v", err)
+	}
+	if last := notifier.GetLastNotification(); last.Title != "KATX Reporting Again" || !strings.Contains(last.Message, "stale for") {
+		t.Errorf("last notification = %+v, want a resumed one", last)
+	}
+}
+
+func TestOutageChecksDisabled(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetError("KATX", errors.New("boom"))
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{StationInput: "KATX", CheckInterval: time.Minute})
+
+	for range 5 {
+		m.processStation(context.Background(), "KATX")
+	}
+	if notifier.GetCallCount() != 0 {
+		t.Errorf("notifications = %+v with outage checks off", notifier.GetNotifications())
+	}
+}
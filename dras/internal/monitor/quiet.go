@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// quietQueue is a station's changes held during quiet hours (see
+// config.QuietQueue).
+type quietQueue struct {
+	// from is the station's reported data before the first held change,
+	// at since.
+	from  *radar.Data
+	since time.Time
+	// fields are the fields whose changes were held.
+	fields []radar.Field
+	// recovery is the incident that the held changes ended, sent with them
+	// as a recovery. Until then it counts as the station's open incident
+	// (see openIncidentLocked).
+	recovery *radar.Incident
+}
+
+// quietSplit is a poll's changes sorted by the station's quiet hours.
+type quietSplit struct {
+	// send are the changes to notify now, and quiet reports whether all of
+	// them are to be sent at low priority.
+	send  []radar.Change
+	quiet bool
+	// queued are the changes to hold until their window ends, and
+	// suppressed the ones to drop.
+	queued     []radar.Change
+	suppressed []radar.Change
+}
+
+// splitQuiet sorts changes by the quiet-hour action that applies to each
+// of their fields at now.
+func splitQuiet(sc config.StationConfig, changes []radar.Change, now time.Time) quietSplit {
+	var s quietSplit
+	var loud, downgraded int
+	for _, c := range changes {
+		switch sc.QuietAction(c.Field, now) {
+		case config.QuietSuppress:
+			s.suppressed = append(s.suppressed, c)
+		case config.QuietQueue:
+			s.queued = append(s.queued, c)
+		case config.QuietDowngrade:
+			s.send = append(s.send, c)
+			downgraded++
+		default:
+			s.send = append(s.send, c)
+			loud++
+		}
+	}
+	s.quiet = downgraded > 0 && loud == 0
+	return s
+}
+
+// queueQuietLocked holds changes, reported when the station's data was
+// last, until their quiet hours end. m.mu must be held.
+func (m *Monitor) queueQuietLocked(stationID string, last *radar.Data, changes []radar.Change, now time.Time) {
+	rec := m.stationLocked(stationID)
+	if rec.quiet == nil {
+		rec.quiet = &quietQueue{from: last, since: now}
+	}
+	for _, c := range changes {
+		if !slices.Contains(rec.quiet.fields, c.Field) {
+			rec.quiet.fields = append(rec.quiet.fields, c.Field)
+		}
+	}
+}
+
+// flushQuiet sends the station's held changes in one notification once no
+// queueing window covers any of their fields. Each field is reported once,
+// from its value before the first held change to its current one; a field
+// that is back where it started is left out. Changes that ended an
+// incident are sent as a recovery. The queue is only cleared once the
+// notification is delivered, so a failed one is retried on the next poll.
+func (m *Monitor) flushQuiet(ctx context.Context, stationID string, last *radar.Data, now time.Time, cfg *config.Config, stationLogger *slog.Logger) error {
+	sc := cfg.Station(stationID)
+	m.mu.Lock()
+	q := m.stationLocked(stationID).quiet
+	m.mu.Unlock()
+	if q == nil {
+		return nil
+	}
+	for _, f := range q.fields {
+		if sc.QuietAction(f, now) == config.QuietQueue {
+			return nil
+		}
+	}
+
+	changes := radar.Diff(q.from, last, alertsFor(q.fields))
+	kind := message.Change
+	if q.recovery != nil {
+		kind = message.Recovery
+	}
+	switch {
+	case len(changes) == 0:
+		stationLogger.Info("Changes held for quiet hours are back where they started")
+	case cfg.DryRun:
+		stationLogger.Debug(fmt.Sprintf("Would send changes held for quiet hours: %s", radar.JoinText(changes)))
+	default:
+		title, body := m.render(kind, message.NewQueuedData(stationID, q.from, last, changes, q.recovery, q.since, now), stationLogger)
+		err := m.send(ctx, stationID, notify.Event{
+			Kind:        notify.EventChange,
+			StationID:   stationID,
+			StationName: last.Name,
+			Old:         q.from,
+			New:         last,
+			Changes:     changes,
+			Incident:    q.recovery,
+			Title:       title,
+			Message:     body,
+			ImageURL:    m.imageURL(stationID),
+			Time:        now,
+		}, stationLogger)
+		if err != nil {
+			return fmt.Errorf("failed to send changes held for quiet hours for station %s: %w", stationID, err)
+		}
+		stationLogger.Info("Changes held for quiet hours sent successfully", "since", q.since.Format(time.RFC3339))
+	}
+	if len(changes) > 0 {
+		m.metrics.ObserveChanges(stationID, changes)
+		m.recordHistory(stationID, HistoryEntry{Time: now, Kind: kind, Old: q.from, New: last, Changes: changes, Incident: q.recovery})
+	}
+
+	m.mu.Lock()
+	m.stationLocked(stationID).quiet = nil
+	m.mu.Unlock()
+	return nil
+}
+
+// alertsFor returns an AlertConfig with the alerts for fields turned on.
+func alertsFor(fields []radar.Field) radar.AlertConfig {
+	return radar.AlertConfig{
+		VCP:         slices.Contains(fields, radar.FieldVCP),
+		Status:      slices.Contains(fields, radar.FieldStatus),
+		Operability: slices.Contains(fields, radar.FieldOperability),
+		PowerSource: slices.Contains(fields, radar.FieldPowerSource),
+		GenState:    slices.Contains(fields, radar.FieldGenState),
+	}
+}
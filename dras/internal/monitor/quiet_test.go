@@ -0,0 +1,197 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// allDay is a quiet-hours window that is always active.
+func allDay(action config.QuietAction, fields ...radar.Field) config.QuietHours {
+	return config.QuietHours{Location: time.UTC, Fields: fields, Action: action}
+}
+
+func TestQuietHoursQueueChanges(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	seattle := func(vcp, status string) *radar.Data {
+		return &radar.Data{Name: "Seattle", VCP: vcp, Mode: "Clear Air", Status: status}
+	}
+	radarMock.SetResponse("KATX", seattle("R31", "Operate"))
+	notifier := notify.NewMockNotifier()
+	cfg := &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, Status: true},
+		QuietHours:    []config.QuietHours{allDay(config.QuietQueue, radar.FieldVCP)},
+	}
+	m := New(radarMock, notifier, nil, cfg)
+	poll := func(data *radar.Data) {
+		t.Helper()
+		radarMock.SetResponse("KATX", data)
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+
+	poll(seattle("R31", "Operate"))
+	notifier.ClearNotifications()
+	poll(seattle("R12", "Operate"))
+	poll(seattle("R35", "Standby"))
+	if got := notifier.GetNotifications(); len(got) != 1 || got[0].Message != "Radar status changed from Operate to Standby" {
+		t.Fatalf("notifications = %+v, want only the status change", got)
+	}
+	if st, _ := m.Station("KATX"); st.Data.VCP != "R35" {
+		t.Errorf("reported VCP = %q, want R35 although it was held", st.Data.VCP)
+	}
+
+	// Quiet hours over: the held VCP changes go out as one.
+	notifier.ClearNotifications()
+	m.Reload(&config.Config{StationInput: "KATX", CheckInterval: time.Minute, AlertConfig: cfg.AlertConfig})
+	poll(seattle("R35", "Standby"))
+	got := notifier.GetNotifications()
+	if len(got) != 1 || !strings.HasPrefix(got[0].Message, "Held during quiet hours since ") || !strings.HasSuffix(got[0].Message, "\nClear Air Mode Active") {
+		t.Fatalf("notifications = %+v, want one with the net VCP change, R31 to R35", got)
+	}
+	poll(seattle("R35", "Standby"))
+	if n := len(notifier.GetNotifications()); n != 1 {
+		t.Errorf("held changes were sent %d times", n)
+	}
+	var vcpEntries int
+	history, _ := m.History("KATX")
+	for _, e := range history {
+		if radar.HasField(e.Changes, radar.FieldVCP) {
+			vcpEntries++
+		}
+	}
+	if vcpEntries != 1 {
+		t.Errorf("history = %+v, want the held VCP changes recorded once, when sent", history)
+	}
+}
+
+func TestQuietHoursQueueRetriedAfterFailedNotification(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31"})
+	notifier := notify.NewMockNotifier()
+	m := New(radarMock, notifier, nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+		QuietHours:    []config.QuietHours{allDay(config.QuietQueue)},
+	})
+	m.processStation(ctx, "KATX")
+	notifier.ClearNotifications()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R12"})
+	m.processStation(ctx, "KATX")
+
+	m.Reload(&config.Config{StationInput: "KATX", CheckInterval: time.Minute, AlertConfig: radar.AlertConfig{VCP: true}})
+	notifier.SetShouldError(true)
+	if err := m.processStation(ctx, "KATX"); err == nil {
+		t.Fatal("processStation() error = nil with the notifier failing")
+	}
+	notifier.SetShouldError(false)
+	if err := m.processStation(ctx, "KATX"); err != nil {
+		t.Fatalf("processStation() error: %v", err)
+	}
+	if got := notifier.GetNotifications(); len(got) != 1 || !strings.Contains(got[0].Message, "Held during quiet hours") {
+		t.Errorf("notifications = %+v, want the held change on the retry", got)
+	}
+}
+
+func TestQuietHoursQueueRecovery(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	cfg := &config.Config{StationInput: "KATX", CheckInterval: time.Minute, AlertConfig: radar.AlertConfig{Status: true}}
+	m := New(radarMock, rec, nil, cfg)
+	poll := func(status string) {
+		t.Helper()
+		radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", Status: status})
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+
+	poll("Operate")
+	poll("Standby")
+	st, _ := m.Station("KATX")
+	if st.Incident == nil {
+		t.Fatal("no incident opened by Standby")
+	}
+	started := st.Incident.Started
+
+	// The recovery is held, and a relapse meanwhile continues the incident.
+	m.Reload(&config.Config{StationInput: "KATX", CheckInterval: time.Minute, AlertConfig: cfg.AlertConfig,
+		QuietHours: []config.QuietHours{allDay(config.QuietQueue, radar.FieldStatus)}})
+	poll("Operate")
+	poll("Standby")
+	if st, _ := m.Station("KATX"); st.Incident == nil || !st.Incident.Started.Equal(started) {
+		t.Fatalf("incident = %+v, want the one held for its recovery reopened", st.Incident)
+	}
+	poll("Operate")
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events during quiet hours, want none", len(rec.events)-2)
+	}
+
+	m.Reload(cfg)
+	poll("Operate")
+	if len(rec.events) != 3 {
+		t.Fatalf("got %d events, want the held recovery", len(rec.events))
+	}
+	ev := rec.events[2]
+	if ev.Incident == nil || ev.Incident.Open() || !ev.Incident.Started.Equal(started) || !strings.Contains(ev.Message, "\nBack to normal after ") {
+		t.Errorf("event = %+v, want the recovery of the incident", ev)
+	}
+	history, _ := m.History("KATX")
+	if latest := history[0]; latest.Kind != message.Recovery || latest.Incident == nil {
+		t.Errorf("latest history entry = %+v, want the recovery", latest)
+	}
+	if st, _ := m.Station("KATX"); st.Incident != nil {
+		t.Errorf("incident = %+v, want none after the recovery", st.Incident)
+	}
+}
+
+func TestQuietHoursSuppressAndDowngrade(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R31", PowerSource: "Commercial Utility"})
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	m := New(radarMock, rec, nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true, PowerSource: true},
+		QuietHours: []config.QuietHours{
+			allDay(config.QuietSuppress, radar.FieldVCP),
+			allDay(config.QuietDowngrade),
+		},
+	})
+
+	for _, data := range []*radar.Data{
+		{Name: "Seattle", VCP: "R31", PowerSource: "Commercial Utility"},
+		{Name: "Seattle", VCP: "R12", PowerSource: "Commercial Utility"},
+		{Name: "Seattle", VCP: "R35", PowerSource: "Generator"},
+	} {
+		radarMock.SetResponse("KATX", data)
+		if err := m.processStation(ctx, "KATX"); err != nil {
+			t.Fatalf("processStation() error: %v", err)
+		}
+	}
+
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events, want startup + power source", len(rec.events))
+	}
+	if ev := rec.events[1]; !ev.Quiet || len(ev.Changes) != 1 || ev.Changes[0].Field != radar.FieldPowerSource {
+		t.Errorf("event = %+v, want a quiet power source change only", ev)
+	}
+	history, _ := m.History("KATX")
+	if len(history) != 2 || len(history[0].Changes) != 1 || history[0].Changes[0].Field != radar.FieldPowerSource {
+		t.Errorf("history = %+v, want startup and the power source change only, without the suppressed ones", history)
+	}
+}
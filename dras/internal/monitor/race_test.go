@@ -31,7 +31,7 @@ func TestRaceConditions(t *testing.T) {
 	}
 
 	t.Run("concurrent map access race detection", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -81,7 +81,7 @@ func TestRaceConditions(t *testing.T) {
 	})
 
 	t.Run("concurrent read/write race detection", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -149,7 +149,7 @@ func TestRaceConditions(t *testing.T) {
 	})
 
 	t.Run("stress test with high concurrency", func(t *testing.T) {
-		radarService := radar.New()
+		radarService := radar.New(radar.Config{})
 		var notifyService *notify.Service
 		monitor := New(radarService, notifyService, nil, cfg)
 
@@ -202,7 +202,7 @@ func TestRaceWithRealMonitoring(t *testing.T) {
 		},
 	}
 
-	radarService := radar.New()
+	radarService := radar.New(radar.Config{})
 	var notifyService *notify.Service
 	monitor := New(radarService, notifyService, nil, cfg)
 
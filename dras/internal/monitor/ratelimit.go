@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// tokenBucket allows count notifications per period: it starts full and
+// refills a token every period/count.
+type tokenBucket struct {
+	count  int
+	period time.Duration
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(count int, period time.Duration, now time.Time) *tokenBucket {
+	return &tokenBucket{count: count, period: period, tokens: float64(count), last: now}
+}
+
+// take spends a token, reporting false when none is left at now.
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(float64(b.count), b.tokens+float64(b.count)*elapsed.Seconds()/b.period.Seconds())
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// overflow is a notifier's notifications held back by the rate limit.
+type overflow struct {
+	since  time.Time
+	events []notify.Event
+}
+
+// rateLimiter enforces config.RateLimit across every notifier. It is
+// reconfigured lazily: a changed limit starts a new, full bucket.
+type rateLimiter struct {
+	mu     sync.Mutex
+	bucket *tokenBucket
+	// order and held are the notifiers with held notifications, in the
+	// order they were first held.
+	order []notify.Notifier
+	held  map[notify.Notifier]*overflow
+}
+
+// allow reports whether a notification may be sent at now under the given
+// limit, spending a token if so. A zero count disables the limit.
+func (l *rateLimiter) allow(count int, period time.Duration, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if count <= 0 || period <= 0 {
+		l.bucket = nil
+		return true
+	}
+	if l.bucket == nil || l.bucket.count != count || l.bucket.period != period {
+		l.bucket = newTokenBucket(count, period, now)
+	}
+	return l.bucket.take(now)
+}
+
+// hold keeps ev for n's next overflow summary. Its attachment is dropped,
+// as the summary carries none.
+func (l *rateLimiter) hold(n notify.Notifier, ev notify.Event, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held == nil {
+		l.held = make(map[notify.Notifier]*overflow)
+	}
+	o, ok := l.held[n]
+	if !ok {
+		o = &overflow{since: now}
+		l.held[n] = o
+		l.order = append(l.order, n)
+	}
+	ev.Attachment = nil
+	o.events = append(o.events, ev)
+}
+
+// take removes and returns the notifiers' held notifications.
+func (l *rateLimiter) take() ([]notify.Notifier, map[notify.Notifier]*overflow) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	order, held := l.order, l.held
+	l.order, l.held = nil, nil
+	return order, held
+}
+
+// putBack returns summaries that could not be sent, in order, to the front
+// of the notifiers' held notifications.
+func (l *rateLimiter) putBack(order []notify.Notifier, held map[notify.Notifier]*overflow) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held == nil {
+		l.held = make(map[notify.Notifier]*overflow)
+	}
+	var front []notify.Notifier
+	for _, n := range order {
+		o := held[n]
+		if later, ok := l.held[n]; ok {
+			later.since = o.since
+			later.events = append(o.events, later.events...)
+			continue
+		}
+		l.held[n] = o
+		front = append(front, n)
+	}
+	l.order = append(front, l.order...)
+}
+
+// deliver sends ev through n unless the rate limit is reached, in which
+// case ev is held for the next overflow summary and counts as delivered:
+// the change it reports is recorded now and summarized later.
+func (m *Monitor) deliver(ctx context.Context, n notify.Notifier, ev notify.Event, logger *slog.Logger) error {
+	cfg := m.cfg()
+	now := time.Now()
+	if n != nil && !m.limiter.allow(cfg.RateLimit, cfg.RateLimitPeriod, now) {
+		logger.Warn("Rate limit reached, holding the notification for a summary", "title", ev.Title)
+		m.limiter.hold(n, ev, now)
+		return nil
+	}
+	return deliveryError(notify.Send(ctx, n, ev), logger)
+}
+
+// flushOverflow sends each notifier's notifications held back by the rate
+// limit as one summary, as far as the limit allows. Summaries that cannot
+// be sent yet, or fail, are kept for the next poll round.
+func (m *Monitor) flushOverflow(ctx context.Context) {
+	order, held := m.limiter.take()
+	if len(order) == 0 {
+		return
+	}
+	cfg := m.cfg()
+	var kept []notify.Notifier
+	defer func() { m.limiter.putBack(kept, held) }()
+	for _, n := range order {
+		o := held[n]
+		now := time.Now()
+		if !m.limiter.allow(cfg.RateLimit, cfg.RateLimitPeriod, now) {
+			kept = append(kept, n)
+			continue
+		}
+		logger := slog.With("notifications", len(o.events))
+		ev := m.batchEvent(o.events, o.since, now, logger)
+		if err := deliveryError(notify.Send(ctx, n, ev), logger); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to send notifications held back by the rate limit: %v", err))
+			kept = append(kept, n)
+			continue
+		}
+		logger.Info("Notifications held back by the rate limit sent", "since", o.since.Format(time.RFC3339))
+	}
+}
+
+// batchEvent combines events into one batch notification. since is when
+// the first of them was held back by the rate limit, or zero for a poll
+// round's batch. It is quiet only when all of them are.
+func (m *Monitor) batchEvent(events []notify.Event, since, now time.Time, logger *slog.Logger) notify.Event {
+	notifications := make([]message.Notification, len(events))
+	quiet := true
+	inner := make([]notify.Event, len(events))
+	for i, ev := range events {
+		notifications[i] = message.Notification{
+			StationID: ev.StationID,
+			Title:     ev.Title,
+			Body:      ev.Message,
+			Severity:  eventSeverity(ev),
+		}
+		quiet = quiet && ev.Quiet
+		ev.Attachment = nil
+		inner[i] = ev
+	}
+	title, body := m.render(message.Batch, message.NewBatchData(notifications, since, now), logger)
+	return notify.Event{
+		Kind:    notify.EventBatch,
+		Events:  inner,
+		Quiet:   quiet,
+		Title:   title,
+		Message: body,
+		Time:    now,
+	}
+}
+
+// eventSeverity ranks a notification for a batch: a change by its changes,
+// a station that stopped reporting as critical, anything else as info.
+func eventSeverity(ev notify.Event) radar.Severity {
+	switch ev.Kind {
+	case notify.EventChange:
+		return radar.MaxSeverity(ev.Changes)
+	case notify.EventOutage:
+		return radar.SeverityCritical
+	}
+	return radar.SeverityInfo
+}
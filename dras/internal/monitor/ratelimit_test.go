@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	b := newTokenBucket(2, time.Hour, start)
+	if !b.take(start) || !b.take(start) {
+		t.Fatal("a new bucket should allow its full count")
+	}
+	if b.take(start.Add(29 * time.Minute)) {
+		t.Error("take() = true before a token refilled")
+	}
+	if !b.take(start.Add(30 * time.Minute)) {
+		t.Error("take() = false after period/count")
+	}
+	if !b.take(start.Add(10*time.Hour)) || !b.take(start.Add(10*time.Hour)) || b.take(start.Add(10*time.Hour)) {
+		t.Error("an idle bucket should refill to its count, no more")
+	}
+}
+
+func TestRateLimitHoldsAndSummarizesNotifications(t *testing.T) {
+	ctx := context.Background()
+	radarMock := radar.NewMockDataFetcher()
+	rec := &eventNotifier{MockNotifier: notify.NewMockNotifier()}
+	cfg := &config.Config{
+		StationInput:    "KATX",
+		CheckInterval:   time.Minute,
+		AlertConfig:     radar.AlertConfig{VCP: true},
+		RateLimit:       1,
+		RateLimitPeriod: 24 * time.Hour,
+	}
+	m := New(radarMock, rec, nil, cfg)
+	pass := func(vcp string) {
+		t.Helper()
+		radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: vcp})
+		if err := m.fetchAndReportRadarData(ctx, []string{"KATX"}); err != nil {
+			t.Fatalf("fetchAndReportRadarData() error: %v", err)
+		}
+	}
+
+	pass("R35")
+	pass("R212")
+	pass("R215")
+	if len(rec.events) != 1 || rec.events[0].Kind != notify.EventStartup {
+		t.Fatalf("events = %+v, want only the startup notification within the limit", rec.events)
+	}
+	if st, _ := m.Station("KATX"); st.Data.VCP != "R215" {
+		t.Errorf("reported VCP = %q, want R215 although its notification was held", st.Data.VCP)
+	}
+
+	// A new limit starts with a full bucket: the held changes go out as one.
+	m.Reload(&config.Config{StationInput: "KATX", CheckInterval: time.Minute, AlertConfig: cfg.AlertConfig, RateLimit: 2, RateLimitPeriod: 24 * time.Hour})
+	pass("R215")
+	if len(rec.events) != 2 {
+		t.Fatalf("got %d events, want the overflow summary", len(rec.events))
+	}
+	ev := rec.events[1]
+	if ev.Kind != notify.EventBatch || len(ev.Events) != 2 || !strings.HasPrefix(ev.Message, "Held back by the rate limit since ") {
+		t.Errorf("summary = %+v, want a batch of the two held changes", ev)
+	}
+	pass("R215")
+	if len(rec.events) != 2 {
+		t.Errorf("held notifications were sent %d times", len(rec.events)-1)
+	}
+}
+
+func TestRateLimitKeepsOverflowWhenSummaryFails(t *testing.T) {
+	ctx := context.Background()
+	notifier := notify.NewMockNotifier()
+	m := New(radar.NewMockDataFetcher(), notifier, nil, &config.Config{RateLimit: 1, RateLimitPeriod: time.Hour})
+	m.limiter.hold(notifier, notify.Event{Kind: notify.EventChange, StationID: "KATX", Title: "KATX", Message: "Precipitation Mode Active"}, time.Now())
+
+	notifier.SetShouldError(true)
+	m.flushOverflow(ctx)
+	notifier.SetShouldError(false)
+	if order, _ := m.limiter.take(); len(order) != 1 {
+		t.Fatalf("overflow kept for %d notifiers after a failed summary, want 1", len(order))
+	}
+}
@@ -0,0 +1,215 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/jacaudi/dras/internal/events"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// historySize is how many reported changes are kept per station.
+const historySize = 100
+
+// HistoryEntry is a reported change in a station's radar state: the
+// startup snapshot, a change that passed debouncing, or the start or end
+// of an outage.
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// Kind is "startup", "change", "recovery", "outage" or "resumed".
+	Kind    message.Kind   `json:"kind"`
+	Old     *radar.Data    `json:"old,omitempty"`
+	New     *radar.Data    `json:"new"`
+	Changes []radar.Change `json:"changes,omitempty"`
+	// Outage is set for outage and resumed entries, whose New is the last
+	// data fetched, if any.
+	Outage *radar.Outage `json:"outage,omitempty"`
+	// Incident is set for change and recovery entries reported while an
+	// incident was open, or that ended one.
+	Incident *radar.Incident `json:"incident,omitempty"`
+}
+
+// StationState is what the monitor knows about a station: its poll status
+// and its last reported radar data.
+type StationState struct {
+	StationStatus
+	// Data is the last reported radar data; nil until the station has been
+	// fetched successfully.
+	Data *radar.Data `json:"data"`
+	// VCPDescription describes Data's volume coverage pattern; empty
+	// without data.
+	VCPDescription string `json:"vcp_description,omitempty"`
+	// Severity rates Data's state (see radar.StateSeverity); empty without
+	// data.
+	Severity radar.Severity `json:"severity,omitempty"`
+	// LastChange is when a change was last reported, or the startup
+	// snapshot taken.
+	LastChange time.Time `json:"last_change,omitzero"`
+	// Paused reports whether polling the station is paused.
+	Paused bool `json:"paused"`
+	// Alerts are the station's effective alert toggles.
+	Alerts radar.AlertConfig `json:"alerts"`
+	// Incident is the station's open incident, if its status or
+	// operability is away from normal.
+	Incident *radar.Incident `json:"incident,omitempty"`
+}
+
+// stationRecord is the monitor's bookkeeping for a station beyond its
+// radarDataMap entry.
+type stationRecord struct {
+	status StationStatus
+	// history is a ring of the most recent reported changes, oldest first.
+	history []HistoryEntry
+	// image is the last radar image fetched for the station.
+	image *image.Image
+	// fetchFailures counts the fetches of the station's data that have
+	// failed in a row, the first of them at firstFetchFailure.
+	fetchFailures     int
+	firstFetchFailure time.Time
+	// incident is the station's open incident, nil while its status and
+	// operability are normal.
+	incident *radar.Incident
+	// quiet holds the changes queued during quiet hours, nil when there
+	// are none.
+	quiet *quietQueue
+}
+
+// stationLocked returns the station's record, creating it if needed. m.mu
+// must be held.
+func (m *Monitor) stationLocked(stationID string) *stationRecord {
+	rec, ok := m.stations[stationID]
+	if !ok {
+		rec = &stationRecord{status: StationStatus{ID: stationID}}
+		m.stations[stationID] = rec
+	}
+	return rec
+}
+
+// recordHistory appends a reported change to the station's history,
+// dropping the oldest entry once historySize is reached, and publishes it
+// as an event.
+func (m *Monitor) recordHistory(stationID string, entry HistoryEntry) {
+	m.mu.Lock()
+	rec := m.stationLocked(stationID)
+	if len(rec.history) >= historySize {
+		rec.history = append(rec.history[:0], rec.history[1:]...)
+	}
+	rec.history = append(rec.history, entry)
+	m.mu.Unlock()
+
+	m.events.Publish(events.Event{
+		Type:     events.Type(entry.Kind),
+		Station:  stationID,
+		Time:     entry.Time,
+		Old:      entry.Old,
+		New:      entry.New,
+		Changes:  entry.Changes,
+		Outage:   entry.Outage,
+		Incident: entry.Incident,
+	})
+}
+
+// recordImage remembers the last image fetched for the station.
+func (m *Monitor) recordImage(stationID string, img *image.Image) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stationLocked(stationID).image = img
+}
+
+// Stations returns the state of every monitored station, in configuration
+// order.
+func (m *Monitor) Stations() []StationState {
+	ids := m.cfg().StationIDs()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]StationState, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, m.stationStateLocked(id))
+	}
+	return out
+}
+
+// Station returns the state of a monitored station, and false if the
+// station is not monitored.
+func (m *Monitor) Station(stationID string) (StationState, bool) {
+	if !m.monitors(stationID) {
+		return StationState{}, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stationStateLocked(stationID), true
+}
+
+// History returns the station's recent reported changes, newest first, and
+// false if the station is not monitored.
+func (m *Monitor) History(stationID string) ([]HistoryEntry, bool) {
+	if !m.monitors(stationID) {
+		return nil, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.stations[stationID]
+	if !ok {
+		return []HistoryEntry{}, true
+	}
+	out := make([]HistoryEntry, len(rec.history))
+	for i, e := range rec.history {
+		out[len(rec.history)-1-i] = e
+	}
+	return out, true
+}
+
+// LatestImage returns the station's most recent radar image: the image
+// source's cached one when it has one, otherwise the last image the
+// monitor fetched (the renderer keeps no cache). It returns false when
+// images are disabled for the station or none has been fetched yet.
+func (m *Monitor) LatestImage(stationID string) (*image.Image, bool) {
+	if !m.monitors(stationID) || !m.imagesEnabled(stationID) {
+		return nil, false
+	}
+	if img, ok := m.imageService.Latest(stationID); ok {
+		return img, true
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec, ok := m.stations[stationID]; ok && rec.image != nil {
+		return rec.image, true
+	}
+	return nil, false
+}
+
+// monitors reports whether the station is in the current configuration.
+func (m *Monitor) monitors(stationID string) bool {
+	for _, id := range m.cfg().StationIDs() {
+		if id == stationID {
+			return true
+		}
+	}
+	return false
+}
+
+// stationStateLocked assembles the station's state. m.mu must be held.
+func (m *Monitor) stationStateLocked(stationID string) StationState {
+	sc := m.cfg().Station(stationID)
+	st := StationState{
+		StationStatus: StationStatus{ID: stationID},
+		Paused:        sc.Paused,
+		Alerts:        sc.AlertConfig,
+	}
+	if last, ok := m.radarDataMap[stationID]["last"].(*radar.Data); ok {
+		st.Data = last
+		// Unknown VCPs still get a fallback description.
+		info, _ := radar.GetVCPInfo(last.VCP)
+		st.VCPDescription = info.Description
+		st.Severity = radar.StateSeverity(last)
+	}
+	if rec, ok := m.stations[stationID]; ok {
+		st.StationStatus = rec.status
+		st.Incident = rec.incident.Clone()
+		if n := len(rec.history); n > 0 {
+			st.LastChange = rec.history[n-1].Time
+		}
+	}
+	return st
+}
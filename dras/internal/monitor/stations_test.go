@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/events"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestStationsAndHistory(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	m := New(radarMock, notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX,KRAX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	})
+	ctx := context.Background()
+
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air"})
+	radarMock.SetError("KRAX", errors.New("NWS unavailable"))
+	_ = m.processStation(ctx, "KATX")
+	_ = m.processStation(ctx, "KRAX")
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R215", Mode: "Precipitation"})
+	_ = m.processStation(ctx, "KATX")
+	_ = m.processStation(ctx, "KATX") // unchanged
+
+	stations := m.Stations()
+	if len(stations) != 2 {
+		t.Fatalf("Stations() = %+v, want KATX and KRAX", stations)
+	}
+	katx, krax := stations[0], stations[1]
+	if katx.ID != "KATX" || katx.Data == nil || katx.Data.VCP != "R215" || katx.LastChange.IsZero() {
+		t.Errorf("KATX = %+v, want the reported R215 data", katx)
+	}
+	if katx.VCPDescription == "" || katx.Severity != radar.SeverityInfo {
+		t.Errorf("KATX = %+v, want the R215 description and info severity", katx)
+	}
+	if krax.Data != nil || krax.LastError == "" {
+		t.Errorf("KRAX = %+v, want no data and the fetch error", krax)
+	}
+
+	history, ok := m.History("KATX")
+	if !ok || len(history) != 2 {
+		t.Fatalf("History(KATX) = %+v, %t; want the change and the startup snapshot", history, ok)
+	}
+	if history[0].Kind != message.Change || history[0].Old.VCP != "R35" || history[0].New.VCP != "R215" || len(history[0].Changes) != 1 {
+		t.Errorf("newest entry = %+v, want the VCP change", history[0])
+	}
+	if history[1].Kind != message.Startup || history[1].Old != nil {
+		t.Errorf("oldest entry = %+v, want the startup snapshot", history[1])
+	}
+
+	if h, ok := m.History("KRAX"); !ok || len(h) != 0 {
+		t.Errorf("History(KRAX) = %+v, %t; want empty", h, ok)
+	}
+	if _, ok := m.Station("KXYZ"); ok {
+		t.Error("Station(KXYZ) found an unmonitored station")
+	}
+	if _, ok := m.History("KXYZ"); ok {
+		t.Error("History(KXYZ) found an unmonitored station")
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), nil, nil, &config.Config{StationInput: "KATX", DryRun: false})
+	for i := 0; i < historySize+10; i++ {
+		m.recordHistory("KATX", HistoryEntry{Time: time.Unix(int64(i), 0), Kind: message.Change})
+	}
+	history, _ := m.History("KATX")
+	if len(history) != historySize {
+		t.Fatalf("len(history) = %d, want %d", len(history), historySize)
+	}
+	if got := history[0].Time.Unix(); got != historySize+9 {
+		t.Errorf("newest entry at %d, want %d", got, historySize+9)
+	}
+}
+
+// noCacheSource is an image source without a cache, like the renderer.
+type noCacheSource struct{}
+
+func (noCacheSource) Fetch(_ context.Context, stationID string) (*image.Image, error) {
+	return &image.Image{StationID: stationID, Data: []byte("PNG"), ContentType: "image/png"}, nil
+}
+
+func (noCacheSource) Latest(string) (*image.Image, bool) { return nil, false }
+
+func TestLatestImageFallsBackToLastFetched(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), noCacheSource{}, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+	})
+	if _, ok := m.LatestImage("KATX"); ok {
+		t.Fatal("LatestImage() before any fetch found an image")
+	}
+	if err := m.processStation(context.Background(), "KATX"); err != nil {
+		t.Fatal(err)
+	}
+	img, ok := m.LatestImage("KATX")
+	if !ok || string(img.Data) != "PNG" {
+		t.Errorf("LatestImage() = %+v, %t; want the startup image", img, ok)
+	}
+}
+
+func TestProcessStationPublishesEvents(t *testing.T) {
+	radarMock := radar.NewMockDataFetcher()
+	broker := events.NewBroker(0)
+	m := New(radarMock, notify.NewMockNotifier(), nil, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		AlertConfig:   radar.AlertConfig{VCP: true},
+	}, WithEvents(broker))
+	ctx := context.Background()
+
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R35"})
+	_ = m.processStation(ctx, "KATX")
+	radarMock.SetResponse("KATX", &radar.Data{Name: "Seattle", VCP: "R215"})
+	_ = m.processStation(ctx, "KATX")
+	_ = m.processStation(ctx, "KATX") // unchanged: no event
+	radarMock.SetError("KATX", errors.New("NWS unavailable"))
+	_ = m.processStation(ctx, "KATX")
+
+	sub, got := broker.Subscribe(events.Filter{Replay: 10})
+	defer sub.Close()
+	want := []events.Type{events.Startup, events.Change, events.PollError}
+	if len(got) != len(want) {
+		t.Fatalf("events = %+v, want %v", got, want)
+	}
+	for i, ev := range got {
+		if ev.Type != want[i] || ev.Station != "KATX" {
+			t.Errorf("event %d = %+v, want a KATX %s", i, ev, want[i])
+		}
+	}
+	if got[1].Old.VCP != "R35" || got[1].New.VCP != "R215" || len(got[1].Changes) != 1 {
+		t.Errorf("change event = %+v, want the VCP change", got[1])
+	}
+	if got[2].Error == "" {
+		t.Error("poll error event has no error")
+	}
+}
+
+func TestDryRunStillFetchesImages(t *testing.T) {
+	m := New(radar.NewMockDataFetcher(), nil, noCacheSource{}, &config.Config{
+		StationInput:  "KATX",
+		CheckInterval: time.Minute,
+		DryRun:        true,
+	})
+	if err := m.processStation(context.Background(), "KATX"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.LatestImage("KATX"); !ok {
+		t.Error("LatestImage() found nothing after a dry-run startup")
+	}
+}
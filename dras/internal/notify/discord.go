@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// discordContentLimit is the maximum length of a Discord message.
+const discordContentLimit = 2000
+
+// Discord posts notifications to a Discord channel webhook, with the
+// attachment uploaded as a file.
+type Discord struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewDiscord creates a Discord notifier from cfg.URL, the channel webhook
+// URL.
+func NewDiscord(cfg BackendConfig) (*Discord, error) {
+	webhookURL := strings.TrimSpace(cfg.URL)
+	if webhookURL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	return &Discord{webhookURL: webhookURL, client: httpClientFor(cfg)}, nil
+}
+
+// SendNotification posts the title in bold followed by the message.
+func (d *Discord) SendNotification(ctx context.Context, title, message string) error {
+	return d.SendNotificationWithAttachment(ctx, title, message, nil)
+}
+
+// SendNotificationWithAttachment posts the notification, uploading the
+// attachment as a file when one is given.
+func (d *Discord) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
+	payload := map[string]string{"content": discordContent(title, message)}
+	if attachment == nil || len(attachment.Data) == 0 {
+		return postJSON(ctx, d.client, d.webhookURL, payload, nil)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	if err := mw.WriteField("payload_json", string(payloadJSON)); err != nil {
+		return fmt.Errorf("write payload: %w", err)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[0]"; filename=%q`, attachment.Filename))
+	if attachment.ContentType != "" {
+		header.Set("Content-Type", attachment.ContentType)
+	}
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create attachment part: %w", err)
+	}
+	if _, err := part.Write(attachment.Data); err != nil {
+		return fmt.Errorf("write attachment: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("finish multipart body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, &body)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return do(d.client, req)
+}
+
+// discordContent formats the message, truncated to Discord's length limit.
+func discordContent(title, message string) string {
+	content := fmt.Sprintf("**%s**\n%s", title, message)
+	if runes := []rune(content); len(runes) > discordContentLimit {
+		content = string(runes[:discordContentLimit-1]) + "…"
+	}
+	return content
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDiscordUploadsAttachmentAsMultipart(t *testing.T) {
+	var content, filename string
+	var fileData []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error: %v", err)
+			return
+		}
+		var payload map[string]string
+		_ = json.Unmarshal([]byte(r.FormValue("payload_json")), &payload)
+		content = payload["content"]
+		f, header, err := r.FormFile("files[0]")
+		if err != nil {
+			t.Errorf("FormFile() error: %v", err)
+			return
+		}
+		defer f.Close()
+		filename = header.Filename
+		fileData, _ = io.ReadAll(f)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d, err := NewDiscord(BackendConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewDiscord() error: %v", err)
+	}
+	att := &Attachment{Data: []byte("gif-bytes"), ContentType: "image/gif", Filename: "KATX.gif"}
+	if err := d.SendNotificationWithAttachment(context.Background(), "KATX Update", "VCP changed", att); err != nil {
+		t.Fatalf("SendNotificationWithAttachment() error: %v", err)
+	}
+
+	if content != "**KATX Update**\nVCP changed" {
+		t.Errorf("content = %q", content)
+	}
+	if filename != "KATX.gif" || string(fileData) != "gif-bytes" {
+		t.Errorf("file = %q (%q)", filename, fileData)
+	}
+}
+
+func TestDiscordContentTruncated(t *testing.T) {
+	got := discordContent("title", strings.Repeat("x", 3000))
+	if n := len([]rune(got)); n != discordContentLimit {
+		t.Errorf("content length = %d, want %d", n, discordContentLimit)
+	}
+}
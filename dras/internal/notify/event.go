@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// EventKind says what an Event is about.
+type EventKind string
+
+const (
+	// EventStartup is sent the first time a station is seen.
+	EventStartup EventKind = "startup"
+	// EventChange is sent when a station's radar data changed.
+	EventChange EventKind = "change"
+	// EventShutdown is sent when dras stops monitoring.
+	EventShutdown EventKind = "shutdown"
+	// EventTest is a sample notification sent by "dras test-notify".
+	EventTest EventKind = "test"
+	// EventOutage is sent when a station stops reporting: its data could
+	// not be fetched for several polls in a row, or has gone stale.
+	EventOutage EventKind = "outage"
+	// EventResumed is sent when a station reports again after an outage.
+	EventResumed EventKind = "resumed"
+	// EventDigest is the scheduled summary of the stations a notifier
+	// handles.
+	EventDigest EventKind = "digest"
+	// EventBatch stands for several notifications sent as one, because
+	// enough were due at once or the rate limit held them back.
+	EventBatch EventKind = "batch"
+)
+
+// Event is a notification with the structured data behind it. Title and
+// Message are the human-readable rendering that text-only backends send;
+// backends that implement EventNotifier can use the rest.
+type Event struct {
+	Kind        EventKind
+	StationID   string
+	StationName string
+	// Old is the previous radar data (nil for a startup event) and New the
+	// current data (nil for a shutdown event).
+	Old *radar.Data
+	New *radar.Data
+	// Changes lists each detected change (change events only).
+	Changes []radar.Change
+	// Outage is the station's outage (outage and resumed events only). New
+	// is then the last data fetched, if any.
+	Outage *radar.Outage
+	// Incident is the station's incident (see radar.Incident) for a change
+	// event while one is open or as it ends; it has Ended set when the
+	// change brings the radar back to normal.
+	Incident *radar.Incident
+	// Stations summarizes each station in a digest event; the event has no
+	// StationID of its own.
+	Stations []radar.StationSummary
+	// Events are the notifications a batch event stands for, without
+	// their attachments.
+	Events []Event
+	// Quiet marks a change sent at low priority because it falls in quiet
+	// hours. Backends with priorities send it at a low one.
+	Quiet bool
+
+	Title      string
+	Message    string
+	Attachment *Attachment
+	// ImageURL is a public URL of the station's radar image, when the image
+	// source has one.
+	ImageURL string
+	Time     time.Time
+}
+
+// EventNotifier is implemented by notifiers that can deliver an Event's
+// structured data rather than just its title and message.
+type EventNotifier interface {
+	Notifier
+	SendEvent(ctx context.Context, ev Event) error
+}
+
+// Send delivers ev through n: as an event when n is an EventNotifier, and
+// as its title, message and attachment otherwise.
+func Send(ctx context.Context, n Notifier, ev Event) error {
+	if en, ok := n.(EventNotifier); ok {
+		return en.SendEvent(ctx, ev)
+	}
+	return n.SendNotificationWithAttachment(ctx, ev.Title, ev.Message, ev.Attachment)
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// gotifyQuietPriority is the priority quiet changes are sent at, which
+// Gotify's clients show without a sound.
+const gotifyQuietPriority = 1
+
+// Gotify sends notifications to a Gotify server. Gotify messages are text
+// only, so attachments are dropped.
+type Gotify struct {
+	server   string
+	token    string
+	priority int
+	client   *http.Client
+}
+
+// NewGotify creates a Gotify notifier from cfg.URL (the server, required),
+// cfg.Token (an application token, required) and cfg.Priority (optional,
+// 0–10).
+func NewGotify(cfg BackendConfig) (*Gotify, error) {
+	server := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if server == "" {
+		return nil, errors.New("server URL is required")
+	}
+	if cfg.Token == "" {
+		return nil, errors.New("application token is required")
+	}
+	if cfg.Priority < 0 || cfg.Priority > 10 {
+		return nil, fmt.Errorf("priority must be between 0 and 10, got %d", cfg.Priority)
+	}
+	return &Gotify{
+		server:   server,
+		token:    cfg.Token,
+		priority: cfg.Priority,
+		client:   httpClientFor(cfg),
+	}, nil
+}
+
+// SendNotification posts a message to the Gotify application.
+func (g *Gotify) SendNotification(ctx context.Context, title, message string) error {
+	payload := map[string]any{
+		"title":   title,
+		"message": message,
+	}
+	if g.priority > 0 {
+		payload["priority"] = g.priority
+	}
+	return postJSON(ctx, g.client, g.server+"/message", payload, map[string]string{"X-Gotify-Key": g.token})
+}
+
+// SendNotificationWithAttachment sends the text of the notification; Gotify
+// has no attachment support.
+func (g *Gotify) SendNotificationWithAttachment(ctx context.Context, title, message string, _ *Attachment) error {
+	return g.SendNotification(ctx, title, message)
+}
+
+// SendEvent sends the event's notification, at low priority when it is
+// quiet.
+func (g *Gotify) SendEvent(ctx context.Context, ev Event) error {
+	if ev.Quiet && (g.priority == 0 || g.priority > gotifyQuietPriority) {
+		low := *g
+		low.priority = gotifyQuietPriority
+		return low.SendNotification(ctx, ev.Title, ev.Message)
+	}
+	return g.SendNotification(ctx, ev.Title, ev.Message)
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGotifyPostsMessage(t *testing.T) {
+	var payload map[string]any
+	var path, key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, key = r.URL.Path, r.Header.Get("X-Gotify-Key")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	g, err := NewGotify(BackendConfig{URL: server.URL + "/", Token: "app-token", Priority: 8})
+	if err != nil {
+		t.Fatalf("NewGotify() error: %v", err)
+	}
+	if err := g.SendNotification(context.Background(), "KATX Update", "VCP changed"); err != nil {
+		t.Fatalf("SendNotification() error: %v", err)
+	}
+	if path != "/message" || key != "app-token" {
+		t.Errorf("path %q, key %q", path, key)
+	}
+	if payload["title"] != "KATX Update" || payload["message"] != "VCP changed" || payload["priority"] != float64(8) {
+		t.Errorf("payload = %v", payload)
+	}
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/httpretry"
+)
+
+// requestTimeout bounds each attempt of a request made by an HTTP-based
+// backend. Retries get their own clock; see httpretry.Transport.
+const requestTimeout = 15 * time.Second
+
+// httpClientFor returns cfg.HTTPClient, or a client that retries transient
+// failures with backoff.
+func httpClientFor(cfg BackendConfig) *http.Client {
+	if cfg.HTTPClient != nil {
+		return cfg.HTTPClient
+	}
+	rt := httpretry.DefaultTransport()
+	rt.PerAttemptTimeout = requestTimeout
+	return &http.Client{Transport: rt}
+}
+
+// postJSON marshals payload and POSTs it to url.
+func postJSON(ctx context.Context, client *http.Client, url string, payload any, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return do(client, req)
+}
+
+// do sends req and treats any non-2xx response as an error that includes
+// the start of the response body, which is where services explain why.
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		// client.Do's *url.Error repeats the full URL; keep only its cause.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s %s: %w", req.Method, redactURL(req.URL.String()), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: unexpected status %d: %s",
+			req.Method, redactURL(req.URL.String()), resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// redactURL strips the path and query from a URL for error messages.
+// Webhook URLs carry their secret in the path (Slack, Discord), so the full
+// URL must never reach the logs.
+func redactURL(raw string) string {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		if j := strings.IndexAny(raw[i+3:], "/?"); j >= 0 {
+			return raw[:i+3+j] + "/…"
+		}
+	}
+	return raw
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Target is a Notifier with the name it is reported under.
+type Target struct {
+	Name     string
+	Notifier Notifier
+}
+
+// Multi fans a notification out to several notifiers concurrently. One
+// backend failing does not stop delivery to the others.
+type Multi struct {
+	targets []Target
+}
+
+// NewMulti returns a Notifier that delivers to every target.
+func NewMulti(targets ...Target) *Multi {
+	return &Multi{targets: targets}
+}
+
+// Targets returns the notifiers Multi delivers to.
+func (m *Multi) Targets() []Target {
+	return append([]Target(nil), m.targets...)
+}
+
+// SendNotification sends the notification to every target.
+func (m *Multi) SendNotification(ctx context.Context, title, message string) error {
+	return m.SendNotificationWithAttachment(ctx, title, message, nil)
+}
+
+// SendNotificationWithAttachment sends the notification to every target and
+// waits for all of them. It returns nil when every target succeeded and a
+// *DeliveryError otherwise.
+func (m *Multi) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
+	return m.each(func(n Notifier) error {
+		return n.SendNotificationWithAttachment(ctx, title, message, attachment)
+	})
+}
+
+// SendEvent delivers ev to every target through Send, so targets that
+// understand events get the structured data and the rest get text.
+func (m *Multi) SendEvent(ctx context.Context, ev Event) error {
+	return m.each(func(n Notifier) error {
+		return Send(ctx, n, ev)
+	})
+}
+
+// each calls send for every target concurrently and collects the results.
+func (m *Multi) each(send func(Notifier) error) error {
+	errs := make([]error, len(m.targets))
+	var wg sync.WaitGroup
+	for i, t := range m.targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			errs[i] = send(t.Notifier)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var de DeliveryError
+	for i, t := range m.targets {
+		if errs[i] != nil {
+			de.Failed = append(de.Failed, TargetError{Name: t.Name, Err: errs[i]})
+		} else {
+			de.Delivered = append(de.Delivered, t.Name)
+		}
+	}
+	if len(de.Failed) == 0 {
+		return nil
+	}
+	return &de
+}
+
+// TargetError is the failure of a single Multi target.
+type TargetError struct {
+	Name string
+	Err  error
+}
+
+func (e TargetError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e TargetError) Unwrap() error {
+	return e.Err
+}
+
+// DeliveryError reports which of a Multi's targets failed. Delivered lists
+// the targets that received the notification, so callers can tell a partial
+// delivery from a total failure.
+type DeliveryError struct {
+	Failed    []TargetError
+	Delivered []string
+}
+
+func (e *DeliveryError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = f.Error()
+	}
+	return fmt.Sprintf("delivery failed for %d of %d notifiers: %s",
+		len(e.Failed), len(e.Failed)+len(e.Delivered), strings.Join(msgs, "; "))
+}
+
+// Unwrap exposes each target's error to errors.Is and errors.As.
+func (e *DeliveryError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f
+	}
+	return errs
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMultiDeliversToEveryTarget(t *testing.T) {
+	a, b := NewMockNotifier(), NewMockNotifier()
+	m := NewMulti(Target{Name: "a", Notifier: a}, Target{Name: "b", Notifier: b})
+
+	att := &Attachment{Data: []byte("img"), Filename: "KATX.png"}
+	if err := m.SendNotificationWithAttachment(context.Background(), "KATX Update", "VCP changed", att); err != nil {
+		t.Fatalf("SendNotificationWithAttachment() error: %v", err)
+	}
+	for name, n := range map[string]*MockNotifier{"a": a, "b": b} {
+		last := n.GetLastNotification()
+		if last == nil || last.Title != "KATX Update" || last.Attachment != att {
+			t.Errorf("target %s got %+v", name, last)
+		}
+	}
+}
+
+func TestMultiReportsFailuresIndependently(t *testing.T) {
+	errDown := errors.New("service down")
+	ok, failing := NewMockNotifier(), NewMockNotifier()
+	failing.SetError("KATX Update", errDown)
+	m := NewMulti(Target{Name: "ntfy", Notifier: ok}, Target{Name: "slack", Notifier: failing})
+
+	err := m.SendNotification(context.Background(), "KATX Update", "VCP changed")
+
+	var de *DeliveryError
+	if !errors.As(err, &de) {
+		t.Fatalf("error = %v, want *DeliveryError", err)
+	}
+	if len(de.Delivered) != 1 || de.Delivered[0] != "ntfy" {
+		t.Errorf("Delivered = %v, want [ntfy]", de.Delivered)
+	}
+	if len(de.Failed) != 1 || de.Failed[0].Name != "slack" {
+		t.Errorf("Failed = %+v, want slack", de.Failed)
+	}
+	if !errors.Is(err, errDown) {
+		t.Error("errors.Is(err, errDown) = false, want the target's error to unwrap")
+	}
+	if ok.GetCallCount() != 1 {
+		t.Errorf("healthy target called %d times, want 1", ok.GetCallCount())
+	}
+}
+
+// eventRecorder is an EventNotifier that records the events it receives.
+type eventRecorder struct {
+	MockNotifier
+	events []Event
+}
+
+func (e *eventRecorder) SendEvent(_ context.Context, ev Event) error {
+	e.events = append(e.events, ev)
+	return nil
+}
+
+func TestMultiSendEventRoutesByCapability(t *testing.T) {
+	text := NewMockNotifier()
+	structured := &eventRecorder{MockNotifier: *NewMockNotifier()}
+	m := NewMulti(Target{Name: "pushover", Notifier: text}, Target{Name: "webhook", Notifier: structured})
+
+	ev := Event{Kind: EventChange, StationID: "KATX", Title: "KATX Update", Message: "VCP changed"}
+	if err := Send(context.Background(), m, ev); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	if last := text.GetLastNotification(); last == nil || last.Title != "KATX Update" {
+		t.Errorf("text target got %+v, want the rendered title", last)
+	}
+	if len(structured.events) != 1 || structured.events[0].StationID != "KATX" {
+		t.Errorf("event target got %+v, want the event", structured.events)
+	}
+	if structured.GetCallCount() != 0 {
+		t.Error("event target also received the text notification")
+	}
+}
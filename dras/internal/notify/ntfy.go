@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultNtfyServer is used when no server URL is configured.
+const defaultNtfyServer = "https://ntfy.sh"
+
+// ntfyQuietPriority is the "low" priority quiet changes are sent at: no
+// sound or vibration.
+const ntfyQuietPriority = 2
+
+// Ntfy publishes notifications to an ntfy topic. Attachments are uploaded
+// as the message body, which ntfy stores and shows inline.
+type Ntfy struct {
+	server   string
+	topic    string
+	token    string
+	priority int
+	client   *http.Client
+}
+
+// NewNtfy creates an ntfy notifier from cfg.URL (the server, default
+// https://ntfy.sh), cfg.Topic (required), cfg.Token (optional access token)
+// and cfg.Priority (optional, 1–5).
+func NewNtfy(cfg BackendConfig) (*Ntfy, error) {
+	topic := strings.TrimSpace(cfg.Topic)
+	if topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	if cfg.Priority < 0 || cfg.Priority > 5 {
+		return nil, fmt.Errorf("priority must be between 1 and 5, got %d", cfg.Priority)
+	}
+	server := strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	return &Ntfy{
+		server:   server,
+		topic:    topic,
+		token:    cfg.Token,
+		priority: cfg.Priority,
+		client:   httpClientFor(cfg),
+	}, nil
+}
+
+// SendNotification publishes a text notification to the topic.
+func (n *Ntfy) SendNotification(ctx context.Context, title, message string) error {
+	return n.SendNotificationWithAttachment(ctx, title, message, nil)
+}
+
+// SendNotificationWithAttachment publishes the notification, uploading the
+// attachment when one is given.
+func (n *Ntfy) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
+	if attachment == nil || len(attachment.Data) == 0 {
+		payload := map[string]any{
+			"topic":   n.topic,
+			"title":   title,
+			"message": message,
+		}
+		if n.priority > 0 {
+			payload["priority"] = n.priority
+		}
+		return postJSON(ctx, n.client, n.server, payload, n.authHeaders())
+	}
+
+	// With a body upload the title and message travel as query parameters;
+	// headers would need RFC 2047 encoding for anything non-ASCII.
+	q := url.Values{}
+	q.Set("title", title)
+	q.Set("message", message)
+	q.Set("filename", attachment.Filename)
+	if n.priority > 0 {
+		q.Set("priority", strconv.Itoa(n.priority))
+	}
+	endpoint := fmt.Sprintf("%s/%s?%s", n.server, url.PathEscape(n.topic), q.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(attachment.Data))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	if attachment.ContentType != "" {
+		req.Header.Set("Content-Type", attachment.ContentType)
+	}
+	for k, v := range n.authHeaders() {
+		req.Header.Set(k, v)
+	}
+	return do(n.client, req)
+}
+
+// SendEvent publishes the event's notification, at low priority when it is
+// quiet.
+func (n *Ntfy) SendEvent(ctx context.Context, ev Event) error {
+	if ev.Quiet && (n.priority == 0 || n.priority > ntfyQuietPriority) {
+		low := *n
+		low.priority = ntfyQuietPriority
+		return low.SendNotificationWithAttachment(ctx, ev.Title, ev.Message, ev.Attachment)
+	}
+	return n.SendNotificationWithAttachment(ctx, ev.Title, ev.Message, ev.Attachment)
+}
+
+func (n *Ntfy) authHeaders() map[string]string {
+	if n.token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + n.token}
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNtfySendsJSONWithoutAttachment(t *testing.T) {
+	var got map[string]any
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.Method != http.MethodPost || r.URL.Path != "/" {
+			t.Errorf("request = %s %s, want POST /", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n, err := NewNtfy(BackendConfig{URL: server.URL, Topic: "dras", Token: "tk_secret", Priority: 4})
+	if err != nil {
+		t.Fatalf("NewNtfy() error: %v", err)
+	}
+	if err := n.SendNotification(context.Background(), "KATX Update", "VCP changed"); err != nil {
+		t.Fatalf("SendNotification() error: %v", err)
+	}
+
+	if got["topic"] != "dras" || got["title"] != "KATX Update" || got["message"] != "VCP changed" || got["priority"] != float64(4) {
+		t.Errorf("payload = %v", got)
+	}
+	if auth != "Bearer tk_secret" {
+		t.Errorf("Authorization = %q, want bearer token", auth)
+	}
+}
+
+func TestNtfySendsQuietEventsAtLowPriority(t *testing.T) {
+	var got []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		got = append(got, payload)
+	}))
+	defer server.Close()
+
+	n, err := NewNtfy(BackendConfig{URL: server.URL, Topic: "dras", Priority: 4})
+	if err != nil {
+		t.Fatalf("NewNtfy() error: %v", err)
+	}
+	for _, quiet := range []bool{true, false} {
+		if err := Send(context.Background(), n, Event{Kind: EventChange, Title: "KATX Update", Message: "VCP changed", Quiet: quiet}); err != nil {
+			t.Fatalf("Send() error: %v", err)
+		}
+	}
+	if len(got) != 2 || got[0]["priority"] != float64(2) || got[1]["priority"] != float64(4) {
+		t.Errorf("payloads = %v, want priority 2 then 4", got)
+	}
+}
+
+func TestNtfyUploadsAttachment(t *testing.T) {
+	var method, path, title, filename, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		title, filename = r.URL.Query().Get("title"), r.URL.Query().Get("filename")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	n, err := NewNtfy(BackendConfig{URL: server.URL, Topic: "dras"})
+	if err != nil {
+		t.Fatalf("NewNtfy() error: %v", err)
+	}
+	att := &Attachment{Data: []byte("png-bytes"), ContentType: "image/png", Filename: "KATX.png"}
+	if err := n.SendNotificationWithAttachment(context.Background(), "KATX Update – R12", "VCP changed", att); err != nil {
+		t.Fatalf("SendNotificationWithAttachment() error: %v", err)
+	}
+
+	if method != http.MethodPut || path != "/dras" {
+		t.Errorf("request = %s %s, want PUT /dras", method, path)
+	}
+	if title != "KATX Update – R12" || filename != "KATX.png" || contentType != "image/png" {
+		t.Errorf("title %q, filename %q, content type %q", title, filename, contentType)
+	}
+	if string(body) != "png-bytes" {
+		t.Errorf("body = %q, want attachment bytes", body)
+	}
+}
+
+func TestNtfyReportsServerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"topic is reserved"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	n, err := NewNtfy(BackendConfig{URL: server.URL, Topic: "dras"})
+	if err != nil {
+		t.Fatalf("NewNtfy() error: %v", err)
+	}
+	err = n.SendNotification(context.Background(), "t", "m")
+	if err == nil {
+		t.Fatal("SendNotification() error = nil, want 403 error")
+	}
+	if !strings.Contains(err.Error(), "topic is reserved") {
+		t.Errorf("error = %v, want it to include the server's reason", err)
+	}
+}
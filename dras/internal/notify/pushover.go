@@ -7,13 +7,15 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/gregdel/pushover"
-	"github.com/nikoksr/notify"
-	pushoverNotify "github.com/nikoksr/notify/service/pushover"
+	"github.com/jacaudi/dras/internal/radar"
 )
 
-// Attachment is an optional image to include with a Pushover notification.
+// Attachment is an optional image to include with a notification. Backends
+// that cannot carry files send the text alone.
 type Attachment struct {
 	Data        []byte
 	ContentType string
@@ -24,14 +26,35 @@ type Attachment struct {
 type Service struct {
 	apiToken string
 	userKey  string
+	messages map[string]PushoverMessage
+
+	// receiptPollInterval is how often an emergency-priority receipt is
+	// checked. Pushover asks for no more than one request every 5 seconds.
+	receiptPollInterval time.Duration
+}
+
+// ServiceOption configures a Pushover Service.
+type ServiceOption func(*Service)
+
+// WithPushoverMessages sets the message options used for each kind of
+// notification; see PushoverMessageKeys.
+func WithPushoverMessages(messages map[string]PushoverMessage) ServiceOption {
+	return func(s *Service) {
+		s.messages = messages
+	}
 }
 
 // New creates a new notification service.
-func New(apiToken, userKey string) *Service {
-	return &Service{
-		apiToken: apiToken,
-		userKey:  userKey,
+func New(apiToken, userKey string, opts ...ServiceOption) *Service {
+	s := &Service{
+		apiToken:            apiToken,
+		userKey:             userKey,
+		receiptPollInterval: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // ValidateCredentials validates Pushover API credentials format
@@ -95,56 +118,102 @@ func ValidateUserKey(userKey string) error {
 	return nil
 }
 
-// SendNotification sends a Pushover notification with the specified title and message.
-// It uses the Pushover service to send the notification.
-// The function returns an error if the notification fails to send, otherwise it returns nil.
+// SendNotification sends a Pushover notification with the specified title
+// and message, using the "default" message options.
 func (s *Service) SendNotification(ctx context.Context, title, message string) error {
-	// Create a new Pushover service
-	pushoverService := pushoverNotify.New(s.apiToken)
-
-	// Add a recipient
-	pushoverService.AddReceivers(s.userKey)
+	return s.SendNotificationWithAttachment(ctx, title, message, nil)
+}
 
-	// Create a new notification
-	notification := notify.New()
-	notification.UseServices(pushoverService)
+// SendNotificationWithAttachment sends a Pushover notification that includes
+// an image attachment, using the "default" message options. When attachment
+// is nil this is equivalent to SendNotification.
+func (s *Service) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
+	return s.send(ctx, title, message, attachment, s.messages[PushoverDefault])
+}
 
-	// Send the notification
-	err := notification.Send(ctx, title, message)
-	if err != nil {
-		return err
+// SendEvent sends the event's title, message and attachment with the
+// message options for its kind of change; see PushoverMessageKeys.
+func (s *Service) SendEvent(ctx context.Context, ev Event) error {
+	opts := s.messageFor(ev)
+	if ev.Quiet {
+		// Low priority: delivered without sound or vibration.
+		opts.Priority = min(opts.Priority, pushover.PriorityLow)
 	}
-
-	slog.Debug("Pushover notification sent successfully")
-	return nil
+	opts.URL = strings.ReplaceAll(opts.URL, "{station}", ev.StationID)
+	return s.send(ctx, ev.Title, ev.Message, ev.Attachment, opts)
 }
 
-// SendNotificationWithAttachment sends a Pushover notification that includes
-// an image attachment. When attachment is nil this is equivalent to
-// SendNotification. The Pushover REST API is invoked directly because the
-// nikoksr/notify wrapper does not expose attachment support.
-func (s *Service) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
-	if attachment == nil || len(attachment.Data) == 0 {
-		return s.SendNotification(ctx, title, message)
+// messageFor picks the message options for ev: those for its kind (startup,
+// shutdown, outage, resumed, digest or batch), for a recovery or the end of
+// an incident, or for the changed field with the highest priority, falling
+// back to the "default" options. A batch without options of its own uses
+// those of its notification with the highest priority.
+func (s *Service) messageFor(ev Event) PushoverMessage {
+	switch ev.Kind {
+	case EventStartup, EventShutdown, EventOutage, EventResumed, EventDigest:
+		if m, ok := s.messages[string(ev.Kind)]; ok {
+			return m
+		}
+	case EventBatch:
+		if m, ok := s.messages[string(ev.Kind)]; ok {
+			return m
+		}
+		if len(ev.Events) > 0 {
+			best := s.messageFor(ev.Events[0])
+			for _, inner := range ev.Events[1:] {
+				if m := s.messageFor(inner); m.Priority > best.Priority {
+					best = m
+				}
+			}
+			return best
+		}
+	case EventChange:
+		if m, ok := s.messages[PushoverRecovery]; ok && (radar.Recovered(ev.Changes) || ev.Incident != nil && !ev.Incident.Open()) {
+			return m
+		}
+		var best *PushoverMessage
+		for _, c := range ev.Changes {
+			if m, ok := s.messages[string(c.Field)]; ok && (best == nil || m.Priority > best.Priority) {
+				best = &m
+			}
+		}
+		if best != nil {
+			return *best
+		}
 	}
+	return s.messages[PushoverDefault]
+}
 
+// send posts the message through the Pushover API. The client has no context
+// support, so the call runs in its own goroutine and is abandoned when ctx
+// is done. An emergency-priority message's receipt is then tracked until it
+// is acknowledged or expires.
+func (s *Service) send(ctx context.Context, title, message string, attachment *Attachment, opts PushoverMessage) error {
 	app := pushover.New(s.apiToken)
 	recipient := pushover.NewRecipient(s.userKey)
 
+	if runes := []rune(message); len(runes) > pushover.MessageMaxLength {
+		message = string(runes[:pushover.MessageMaxLength-1]) + "…"
+	}
 	msg := pushover.NewMessageWithTitle(message, title)
-	if err := msg.AddAttachment(bytes.NewReader(attachment.Data)); err != nil {
-		return fmt.Errorf("failed to attach image to notification: %w", err)
+	opts.apply(msg)
+	if attachment != nil && len(attachment.Data) > 0 {
+		if err := msg.AddAttachment(bytes.NewReader(attachment.Data)); err != nil {
+			return fmt.Errorf("failed to attach image to notification: %w", err)
+		}
 	}
 
 	type result struct {
-		err error
+		resp *pushover.Response
+		err  error
 	}
 	resCh := make(chan result, 1)
 	go func() {
-		_, err := app.SendMessage(msg, recipient)
-		resCh <- result{err: err}
+		resp, err := app.SendMessage(msg, recipient)
+		resCh <- result{resp: resp, err: err}
 	}()
 
+	var resp *pushover.Response
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -152,8 +221,17 @@ func (s *Service) SendNotificationWithAttachment(ctx context.Context, title, mes
 		if r.err != nil {
 			return r.err
 		}
+		resp = r.resp
 	}
 
-	slog.Debug("Pushover notification with attachment sent successfully", "bytes", fmt.Sprintf("%d", len(attachment.Data)))
+	if attachment != nil {
+		slog.Debug("Pushover notification with attachment sent successfully", "bytes", fmt.Sprintf("%d", len(attachment.Data)))
+	} else {
+		slog.Debug("Pushover notification sent successfully")
+	}
+
+	if resp != nil && resp.Receipt != "" {
+		s.trackReceipt(ctx, app, resp.Receipt, title)
+	}
 	return nil
 }
@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gregdel/pushover"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// Pushover message option keys. Besides these, each radar.Field ("vcp",
+// "status", "operability", "power_source", "gen_state") selects the options
+// for a change to that field.
+const (
+	// PushoverDefault applies to any notification without options of its own.
+	PushoverDefault = "default"
+	// PushoverRecovery applies to changes that bring the radar back to
+	// normal (see radar.Recovered).
+	PushoverRecovery = "recovery"
+)
+
+// PushoverMessageKeys lists the valid keys of a Pushover message options map.
+func PushoverMessageKeys() []string {
+	keys := []string{
+		PushoverDefault,
+		string(EventStartup),
+		string(EventShutdown),
+		string(EventOutage),
+		string(EventResumed),
+		string(EventDigest),
+		string(EventBatch),
+		PushoverRecovery,
+		string(radar.FieldVCP),
+		string(radar.FieldStatus),
+		string(radar.FieldOperability),
+		string(radar.FieldPowerSource),
+		string(radar.FieldGenState),
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Emergency-priority limits from the Pushover API documentation.
+const (
+	minEmergencyRetry  = 30 * time.Second
+	maxEmergencyExpire = 3 * time.Hour
+	// Used when an emergency message doesn't set Retry or Expire.
+	defaultEmergencyRetry  = time.Minute
+	defaultEmergencyExpire = time.Hour
+)
+
+// PushoverMessage holds the Pushover-specific options for a notification.
+// A change with several fields uses the options of the field with the
+// highest priority.
+type PushoverMessage struct {
+	// Priority is -2 (lowest) to 2 (emergency). Emergency messages repeat
+	// every Retry until acknowledged or until Expire has passed.
+	Priority int
+	// Sound is a Pushover sound name, e.g. "siren"; empty uses the
+	// recipient's default.
+	Sound string
+	// URL is a supplementary link; "{station}" is replaced with the station
+	// ID. URLTitle is its label.
+	URL      string
+	URLTitle string
+	// TTL deletes the message from the device after this long; zero keeps it.
+	TTL time.Duration
+	// HTML enables Pushover's HTML formatting of the message body.
+	HTML   bool
+	Retry  time.Duration
+	Expire time.Duration
+}
+
+// Validate checks the options against the Pushover API's limits.
+func (m PushoverMessage) Validate() error {
+	var errs []string
+	if m.Priority < pushover.PriorityLowest || m.Priority > pushover.PriorityEmergency {
+		errs = append(errs, fmt.Sprintf("priority must be between %d and %d, got %d", pushover.PriorityLowest, pushover.PriorityEmergency, m.Priority))
+	}
+	if m.URLTitle != "" && m.URL == "" {
+		errs = append(errs, "url_title requires url")
+	}
+	if m.TTL < 0 {
+		errs = append(errs, "ttl cannot be negative")
+	}
+	if m.Priority == pushover.PriorityEmergency {
+		if m.Retry != 0 && m.Retry < minEmergencyRetry {
+			errs = append(errs, fmt.Sprintf("retry must be at least %v", minEmergencyRetry))
+		}
+		if m.Expire < 0 || m.Expire > maxEmergencyExpire {
+			errs = append(errs, fmt.Sprintf("expire must be at most %v", maxEmergencyExpire))
+		}
+	} else if m.Retry != 0 || m.Expire != 0 {
+		errs = append(errs, "retry and expire only apply to priority 2")
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ValidatePushoverMessages checks every entry of a message options map and
+// that its keys are known. Errors name the offending key.
+func ValidatePushoverMessages(messages map[string]PushoverMessage) error {
+	valid := make(map[string]bool)
+	for _, k := range PushoverMessageKeys() {
+		valid[k] = true
+	}
+	keys := make([]string, 0, len(messages))
+	for k := range messages {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		if !valid[k] {
+			errs = append(errs, fmt.Errorf("%s: unknown message type (one of %v)", k, PushoverMessageKeys()))
+			continue
+		}
+		if err := messages[k].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", k, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply copies the options onto msg.
+func (m PushoverMessage) apply(msg *pushover.Message) {
+	msg.Priority = m.Priority
+	msg.Sound = m.Sound
+	msg.URL = m.URL
+	msg.URLTitle = m.URLTitle
+	msg.TTL = m.TTL
+	msg.HTML = m.HTML
+	if m.Priority == pushover.PriorityEmergency {
+		msg.Retry, msg.Expire = m.Retry, m.Expire
+		if msg.Retry == 0 {
+			msg.Retry = defaultEmergencyRetry
+		}
+		if msg.Expire == 0 {
+			msg.Expire = defaultEmergencyExpire
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gregdel/pushover"
+)
+
+// maxTrackedReceipts bounds how many receipts Receipts remembers; the oldest
+// settled receipts are dropped first.
+const maxTrackedReceipts = 50
+
+// receiptLog is every Service's tracked receipts, oldest first. It is kept
+// across Services so that it outlives the notifiers a reload replaces.
+var receiptLog struct {
+	mu       sync.Mutex
+	receipts []*ReceiptStatus
+}
+
+// Receipt outcomes passed to the observer set with SetReceiptObserver.
+const (
+	ReceiptSent         = "sent"
+	ReceiptAcknowledged = "acknowledged"
+	ReceiptExpired      = "expired"
+)
+
+// receiptObserver is called as receipts are tracked; see SetReceiptObserver.
+var receiptObserver atomic.Pointer[func(outcome string)]
+
+// SetReceiptObserver registers fn to be called with ReceiptSent when an
+// emergency-priority message is sent, and with ReceiptAcknowledged or
+// ReceiptExpired once its receipt settles. It is how receipt outcomes reach
+// the metrics endpoint. A nil fn removes the observer.
+func SetReceiptObserver(fn func(outcome string)) {
+	if fn == nil {
+		receiptObserver.Store(nil)
+		return
+	}
+	receiptObserver.Store(&fn)
+}
+
+func observeReceipt(outcome string) {
+	if observe := receiptObserver.Load(); observe != nil {
+		(*observe)(outcome)
+	}
+}
+
+// ReceiptStatus is the state of an emergency-priority Pushover message.
+type ReceiptStatus struct {
+	Receipt string    `json:"receipt"`
+	Title   string    `json:"title"`
+	SentAt  time.Time `json:"sent_at"`
+
+	Acknowledged   bool      `json:"acknowledged"`
+	AcknowledgedBy string    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt time.Time `json:"acknowledged_at,omitzero"`
+	Expired        bool      `json:"expired"`
+	ExpiresAt      time.Time `json:"expires_at,omitzero"`
+	// Error is the last error from polling the receipts API, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// settled reports whether the receipt needs no more polling.
+func (r *ReceiptStatus) settled() bool {
+	return r.Acknowledged || r.Expired
+}
+
+// Receipts returns the most recent emergency-priority messages sent by any
+// Service, most recent first, with their acknowledgement state as of the
+// last poll.
+func Receipts() []ReceiptStatus {
+	receiptLog.mu.Lock()
+	defer receiptLog.mu.Unlock()
+	out := make([]ReceiptStatus, len(receiptLog.receipts))
+	for i, r := range receiptLog.receipts {
+		out[len(receiptLog.receipts)-1-i] = *r
+	}
+	return out
+}
+
+// recordReceipt adds status to the receipt log. Past maxTrackedReceipts the
+// oldest settled receipt is dropped, or the oldest of all when none has
+// settled.
+func recordReceipt(status *ReceiptStatus) {
+	receiptLog.mu.Lock()
+	defer receiptLog.mu.Unlock()
+	receiptLog.receipts = append(receiptLog.receipts, status)
+	if len(receiptLog.receipts) <= maxTrackedReceipts {
+		return
+	}
+	drop := 0
+	for i, r := range receiptLog.receipts {
+		if r.settled() {
+			drop = i
+			break
+		}
+	}
+	receiptLog.receipts = append(receiptLog.receipts[:drop], receiptLog.receipts[drop+1:]...)
+}
+
+// trackReceipt records receipt and polls the receipts API in the background
+// until the message is acknowledged or expires, logging the outcome.
+// Polling stops early when ctx is done.
+func (s *Service) trackReceipt(ctx context.Context, app *pushover.Pushover, receipt, title string) {
+	status := &ReceiptStatus{Receipt: receipt, Title: title, SentAt: time.Now()}
+	recordReceipt(status)
+
+	logger := slog.Default().With("receipt", receipt, "title", title)
+	logger.Info("Pushover emergency notification sent, waiting for acknowledgement")
+	observeReceipt(ReceiptSent)
+
+	go func() {
+		ticker := time.NewTicker(s.receiptPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			details, err := receiptDetails(app, receipt)
+			receiptLog.mu.Lock()
+			status.Error = ""
+			if err != nil {
+				status.Error = err.Error()
+			} else {
+				status.Acknowledged = details.Acknowledged
+				status.AcknowledgedBy = details.AcknowledgedBy
+				status.Expired = details.Expired
+				if details.AcknowledgedAt != nil {
+					status.AcknowledgedAt = *details.AcknowledgedAt
+				}
+				if details.ExpiresAt != nil {
+					status.ExpiresAt = *details.ExpiresAt
+				}
+			}
+			done := status.settled()
+			snapshot := *status
+			receiptLog.mu.Unlock()
+
+			switch {
+			case err != nil:
+				logger.Warn("Failed to check Pushover receipt", "error", err)
+			case snapshot.Acknowledged:
+				observeReceipt(ReceiptAcknowledged)
+				logger.Info("Pushover emergency notification acknowledged",
+					"acknowledged_by", snapshot.AcknowledgedBy,
+					"acknowledged_at", snapshot.AcknowledgedAt.Format(time.RFC3339))
+			case snapshot.Expired:
+				observeReceipt(ReceiptExpired)
+				logger.Warn("Pushover emergency notification expired without acknowledgement")
+			}
+			if done {
+				return
+			}
+		}
+	}()
+}
+
+// receiptDetails calls the receipts API. The client panics on a response
+// that lacks any of the timestamp fields, so a malformed reply is turned
+// into an error rather than taking the process down.
+func receiptDetails(app *pushover.Pushover, receipt string) (details *pushover.ReceiptDetails, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			details, err = nil, fmt.Errorf("malformed receipt response: %v", r)
+		}
+	}()
+	return app.GetReceiptDetails(receipt)
+}
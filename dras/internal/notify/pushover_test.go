@@ -1,7 +1,18 @@
 package notify
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gregdel/pushover"
+	"github.com/jacaudi/dras/internal/radar"
 )
 
 func TestValidateAPIToken(t *testing.T) {
@@ -123,3 +134,262 @@ func TestService_ValidateCredentials(t *testing.T) {
 		})
 	}
 }
+
+const (
+	testPushoverToken = "abcdef1234567890123456789012ab"
+	testPushoverUser  = "uvwxyz1234567890123456789012uv"
+)
+
+// pushoverStandIn is an httptest stand-in for the Pushover API. Emergency
+// messages get receipt "r1", which is acknowledged once it has been polled
+// ackAfter times.
+type pushoverStandIn struct {
+	mu       sync.Mutex
+	messages []map[string]string
+	polls    int
+	ackAfter int
+}
+
+func newPushoverStandIn(t *testing.T, ackAfter int) *pushoverStandIn {
+	t.Helper()
+	p := &pushoverStandIn{ackAfter: ackAfter}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/messages.json":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("parse form: %v", err)
+				}
+			}
+			fields := make(map[string]string)
+			for k := range r.Form {
+				fields[k] = r.Form.Get(k)
+			}
+			p.mu.Lock()
+			p.messages = append(p.messages, fields)
+			p.mu.Unlock()
+
+			w.Header().Set("X-Limit-App-Limit", "10000")
+			w.Header().Set("X-Limit-App-Remaining", "9999")
+			w.Header().Set("X-Limit-App-Reset", "1393653600")
+			resp := map[string]any{"status": 1, "request": "req"}
+			if fields["priority"] == "2" {
+				resp["receipt"] = "r1"
+			}
+			json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/receipts/r1.json":
+			p.mu.Lock()
+			p.polls++
+			acked := p.polls >= p.ackAfter
+			p.mu.Unlock()
+			resp := map[string]any{
+				"status": 1, "request": "req", "acknowledged": 0, "acknowledged_by": "",
+				"acknowledged_at": 0, "last_delivered_at": 1714564700, "expired": 0,
+				"expires_at": 1893456000, "called_back": 0, "called_back_at": 0,
+			}
+			if acked {
+				resp["acknowledged"] = 1
+				resp["acknowledged_by"] = testPushoverUser
+				resp["acknowledged_at"] = 1714564800
+			}
+			json.NewEncoder(w).Encode(resp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	prev := pushover.APIEndpoint
+	pushover.APIEndpoint = srv.URL
+	t.Cleanup(func() { pushover.APIEndpoint = prev })
+	return p
+}
+
+func (p *pushoverStandIn) sent() []map[string]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]map[string]string(nil), p.messages...)
+}
+
+func TestServiceSendEventUsesMessageOptions(t *testing.T) {
+	api := newPushoverStandIn(t, 1)
+	s := New(testPushoverToken, testPushoverUser, WithPushoverMessages(map[string]PushoverMessage{
+		PushoverDefault:  {Priority: -1},
+		"startup":        {Priority: -2, Sound: "none"},
+		"vcp":            {Priority: 0, Sound: "pushover"},
+		"status":         {Priority: 1, Sound: "siren", URL: "https://radar.weather.gov/station/{station}", URLTitle: "Station page", TTL: time.Hour, HTML: true},
+		PushoverRecovery: {Priority: 0, Sound: "magic"},
+	}))
+	ctx := context.Background()
+
+	vcp := radar.Change{Field: radar.FieldVCP, Old: "R31", New: "R12", Severity: radar.SeverityInfo}
+	down := radar.Change{Field: radar.FieldStatus, Old: "Operate", New: "Standby", Severity: radar.SeverityCritical}
+	up := radar.Change{Field: radar.FieldStatus, Old: "Standby", New: "Operate", Severity: radar.SeverityInfo}
+	for _, ev := range []Event{
+		{Kind: EventStartup, StationID: "KATX", Title: "DRAS Startup", Message: "KATX online"},
+		{Kind: EventChange, StationID: "KATX", Title: "KATX Update", Message: "vcp and status", Changes: []radar.Change{vcp, down}},
+		{Kind: EventChange, StationID: "KATX", Title: "KATX Update", Message: "recovered", Changes: []radar.Change{up}},
+		{Kind: EventShutdown, Title: "DRAS Shutdown", Message: "bye"},
+		{Kind: EventChange, StationID: "KATX", Title: "KATX Update", Message: "quiet", Changes: []radar.Change{vcp, down}, Quiet: true},
+		{Kind: EventBatch, Title: "DRAS: 2 Notifications", Message: strings.Repeat("x", 2000), Events: []Event{
+			{Kind: EventStartup, StationID: "KRAX"},
+			{Kind: EventChange, StationID: "KATX", Changes: []radar.Change{down}},
+		}},
+	} {
+		if err := s.SendEvent(ctx, ev); err != nil {
+			t.Fatalf("SendEvent(%s) error: %v", ev.Kind, err)
+		}
+	}
+
+	sent := api.sent()
+	if len(sent) != 6 {
+		t.Fatalf("stand-in received %d messages, want 6", len(sent))
+	}
+	checks := []map[string]string{
+		{"priority": "-2", "sound": "none", "title": "DRAS Startup"},
+		{"priority": "1", "sound": "siren", "url": "https://radar.weather.gov/station/KATX", "url_title": "Station page", "ttl": "3600", "html": "1"},
+		{"priority": "0", "sound": "magic", "message": "recovered"},
+		{"priority": "-1", "sound": ""},
+		{"priority": "-1", "sound": "siren", "message": "quiet"},
+		// A batch takes its highest-priority notification's options and
+		// is cut to Pushover's length limit.
+		{"priority": "1", "sound": "siren", "message": strings.Repeat("x", pushover.MessageMaxLength-1) + "…"},
+	}
+	for i, want := range checks {
+		for k, v := range want {
+			if got := sent[i][k]; got != v {
+				t.Errorf("message %d: %s = %q, want %q", i, k, got, v)
+			}
+		}
+		if sent[i]["token"] != testPushoverToken || sent[i]["user"] != testPushoverUser {
+			t.Errorf("message %d sent with the wrong credentials", i)
+		}
+	}
+}
+
+func TestServiceTracksEmergencyReceipts(t *testing.T) {
+	api := newPushoverStandIn(t, 2)
+	s := New(testPushoverToken, testPushoverUser, WithPushoverMessages(map[string]PushoverMessage{
+		"status": {Priority: 2, Sound: "siren"},
+	}))
+	s.receiptPollInterval = 10 * time.Millisecond
+	var outcomesMu sync.Mutex
+	var outcomes []string
+	SetReceiptObserver(func(outcome string) {
+		outcomesMu.Lock()
+		defer outcomesMu.Unlock()
+		outcomes = append(outcomes, outcome)
+	})
+	t.Cleanup(func() { SetReceiptObserver(nil) })
+	resetReceipts(t)
+
+	ev := Event{
+		Kind:      EventChange,
+		StationID: "KATX",
+		Title:     "KATX Update",
+		Message:   "Radar status changed from Operate to Standby",
+		Changes:   []radar.Change{{Field: radar.FieldStatus, Old: "Operate", New: "Standby", Severity: radar.SeverityCritical}},
+	}
+	if err := s.SendEvent(context.Background(), ev); err != nil {
+		t.Fatalf("SendEvent() error: %v", err)
+	}
+	if sent := api.sent(); len(sent) != 1 || sent[0]["retry"] != "60" || sent[0]["expire"] != "3600" {
+		t.Fatalf("emergency message = %v, want default retry and expire", sent)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		receipts := Receipts()
+		if len(receipts) != 1 {
+			t.Fatalf("Receipts() = %+v, want one", receipts)
+		}
+		if r := receipts[0]; r.Acknowledged {
+			if r.Receipt != "r1" || r.AcknowledgedBy != testPushoverUser || r.AcknowledgedAt.IsZero() || r.Title != "KATX Update" {
+				t.Errorf("receipt = %+v", r)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("receipt never acknowledged")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Acknowledged receipts are not polled again.
+	api.mu.Lock()
+	polls := api.polls
+	api.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if api.polls != polls {
+		t.Errorf("receipt polled %d more times after acknowledgement", api.polls-polls)
+	}
+	outcomesMu.Lock()
+	defer outcomesMu.Unlock()
+	if len(outcomes) != 2 || outcomes[0] != ReceiptSent || outcomes[1] != ReceiptAcknowledged {
+		t.Errorf("observed receipt outcomes %v, want [sent acknowledged]", outcomes)
+	}
+}
+
+func TestValidatePushoverMessages(t *testing.T) {
+	err := ValidatePushoverMessages(map[string]PushoverMessage{
+		"default": {Priority: 0},
+		"status":  {Priority: 2, Retry: 10 * time.Second, Expire: 4 * time.Hour},
+		"vcp":     {Priority: 3},
+		"gen":     {},
+		"startup": {URLTitle: "no url", Expire: time.Minute},
+	})
+	if err == nil {
+		t.Fatal("ValidatePushoverMessages() error = nil, want errors")
+	}
+	for _, want := range []string{
+		"gen: unknown message type",
+		"status: retry must be at least 30s; expire must be at most 3h0m0s",
+		"vcp: priority must be between -2 and 2, got 3",
+		"startup: url_title requires url; retry and expire only apply to priority 2",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q\ngot: %v", want, err)
+		}
+	}
+	if err := ValidatePushoverMessages(nil); err != nil {
+		t.Errorf("ValidatePushoverMessages(nil) error: %v", err)
+	}
+}
+
+// resetReceipts empties the receipt log for the test and again after it.
+func resetReceipts(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		receiptLog.mu.Lock()
+		defer receiptLog.mu.Unlock()
+		receiptLog.receipts = nil
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestReceiptLogIsBounded(t *testing.T) {
+	resetReceipts(t)
+	for i := range maxTrackedReceipts + 2 {
+		recordReceipt(&ReceiptStatus{Receipt: fmt.Sprintf("r%d", i), Acknowledged: i == 5})
+	}
+	receipts := Receipts()
+	if len(receipts) != maxTrackedReceipts {
+		t.Fatalf("kept %d receipts, want %d", len(receipts), maxTrackedReceipts)
+	}
+	// The settled r5 goes first, then the oldest unsettled one.
+	if oldest := receipts[len(receipts)-1].Receipt; oldest != "r1" {
+		t.Errorf("oldest receipt kept = %s, want r1", oldest)
+	}
+	for _, r := range receipts {
+		if r.Receipt == "r5" {
+			t.Error("the settled receipt was kept over unsettled ones")
+		}
+	}
+	if latest := receipts[0].Receipt; latest != fmt.Sprintf("r%d", maxTrackedReceipts+1) {
+		t.Errorf("latest receipt = %s", latest)
+	}
+}
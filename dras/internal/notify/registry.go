@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BackendConfig describes one notification backend. Which fields are used
+// depends on Type; each backend's constructor documents its own.
+type BackendConfig struct {
+	// Type selects the backend implementation, e.g. "pushover" or "ntfy".
+	Type string
+	// Name identifies the backend in logs and delivery errors. Defaults to
+	// Type when empty.
+	Name string
+
+	// URL is the server or webhook URL.
+	URL string
+	// URLs are additional webhook URLs; each receives every notification.
+	URLs []string
+	// Topic is the ntfy topic.
+	Topic string
+	// Token is the Pushover API token, ntfy access token or Gotify app token.
+	Token string
+	// UserKey is the Pushover user key.
+	UserKey string
+	// Priority is passed through to backends that support it (ntfy 1–5,
+	// Gotify 0–10). Zero means the server default.
+	Priority int
+	// Headers are added to every request (webhook only).
+	Headers map[string]string
+	// Secret signs webhook requests with HMAC-SHA256 (webhook only).
+	Secret string
+	// ImageMode is how the webhook carries the radar image: "base64"
+	// (default), "url" or "none".
+	ImageMode string
+	// PushoverMessages are the Pushover priority, sound and other options
+	// per kind of notification (pushover only); see PushoverMessageKeys.
+	PushoverMessages map[string]PushoverMessage
+
+	// HTTPClient overrides the HTTP client used by HTTP-based backends.
+	// Defaults to one that retries transient failures.
+	HTTPClient *http.Client
+}
+
+// DisplayName returns Name, or Type when Name is empty.
+func (c BackendConfig) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
+}
+
+// Factory builds a Notifier from its backend configuration. Factories
+// validate the fields their backend requires.
+type Factory func(cfg BackendConfig) (Notifier, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"pushover": newPushoverBackend,
+		"ntfy":     func(cfg BackendConfig) (Notifier, error) { return NewNtfy(cfg) },
+		"gotify":   func(cfg BackendConfig) (Notifier, error) { return NewGotify(cfg) },
+		"slack":    func(cfg BackendConfig) (Notifier, error) { return NewSlack(cfg) },
+		"discord":  func(cfg BackendConfig) (Notifier, error) { return NewDiscord(cfg) },
+		"webhook":  func(cfg BackendConfig) (Notifier, error) { return NewWebhook(cfg) },
+	}
+)
+
+// Register makes a backend available to Build under backendType. It panics
+// if the type is already registered, like database/sql.Register.
+func Register(backendType string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	backendType = strings.ToLower(backendType)
+	if _, dup := registry[backendType]; dup {
+		panic(fmt.Sprintf("notify: backend %q registered twice", backendType))
+	}
+	registry[backendType] = factory
+}
+
+// Types returns the registered backend types in sorted order.
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Build creates the Notifier for cfg using the factory registered for its
+// type.
+func Build(cfg BackendConfig) (Notifier, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(cfg.Type)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier type %q (available: %s)", cfg.Type, strings.Join(Types(), ", "))
+	}
+	n, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s notifier %q: %w", strings.ToLower(cfg.Type), cfg.DisplayName(), err)
+	}
+	return n, nil
+}
+
+// newPushoverBackend builds the Pushover Service from Token, UserKey and
+// PushoverMessages.
+func newPushoverBackend(cfg BackendConfig) (Notifier, error) {
+	s := New(cfg.Token, cfg.UserKey, WithPushoverMessages(cfg.PushoverMessages))
+	if err := s.ValidateCredentials(); err != nil {
+		return nil, err
+	}
+	if err := ValidatePushoverMessages(cfg.PushoverMessages); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     BackendConfig
+		wantErr string
+	}{
+		{name: "pushover", cfg: BackendConfig{Type: "pushover", Token: "abcdef1234567890123456789012ab", UserKey: "uvwxyz1234567890123456789012uv"}},
+		{name: "ntfy", cfg: BackendConfig{Type: "ntfy", Topic: "dras"}},
+		{name: "type is case-insensitive", cfg: BackendConfig{Type: "Slack", URL: "https://hooks.slack.com/services/x"}},
+		{name: "gotify", cfg: BackendConfig{Type: "gotify", URL: "https://gotify.example.com", Token: "t"}},
+		{name: "discord", cfg: BackendConfig{Type: "discord", URL: "https://discord.com/api/webhooks/1/x"}},
+		{name: "webhook", cfg: BackendConfig{Type: "webhook", URL: "https://example.com/hook"}},
+		{name: "unknown type", cfg: BackendConfig{Type: "carrier-pigeon"}, wantErr: `unknown notifier type "carrier-pigeon"`},
+		{name: "invalid pushover", cfg: BackendConfig{Type: "pushover", Token: "short"}, wantErr: `pushover notifier "pushover"`},
+		{name: "ntfy without topic", cfg: BackendConfig{Type: "ntfy", Name: "ops"}, wantErr: `ntfy notifier "ops": topic is required`},
+		{name: "gotify without token", cfg: BackendConfig{Type: "gotify", URL: "https://gotify.example.com"}, wantErr: "application token is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Build(tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Build() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || n == nil {
+				t.Errorf("Build() = %v, %v; want notifier", n, err)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	mock := NewMockNotifier()
+	Register("test-register", func(BackendConfig) (Notifier, error) { return mock, nil })
+
+	n, err := Build(BackendConfig{Type: "test-register"})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if err := n.SendNotification(context.Background(), "t", "m"); err != nil || mock.GetCallCount() != 1 {
+		t.Errorf("registered notifier not used: err %v, calls %d", err, mock.GetCallCount())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() of a duplicate type did not panic")
+		}
+	}()
+	Register("test-register", func(BackendConfig) (Notifier, error) { return mock, nil })
+}
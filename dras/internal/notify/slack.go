@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Slack posts notifications to a Slack incoming webhook. Incoming webhooks
+// cannot upload files, so attachments are dropped.
+type Slack struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewSlack creates a Slack notifier from cfg.URL, the incoming webhook URL.
+func NewSlack(cfg BackendConfig) (*Slack, error) {
+	webhookURL := strings.TrimSpace(cfg.URL)
+	if webhookURL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	return &Slack{webhookURL: webhookURL, client: httpClientFor(cfg)}, nil
+}
+
+// SendNotification posts the title in bold followed by the message.
+func (s *Slack) SendNotification(ctx context.Context, title, message string) error {
+	payload := map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", title, message),
+	}
+	return postJSON(ctx, s.client, s.webhookURL, payload, nil)
+}
+
+// SendNotificationWithAttachment sends the text of the notification;
+// incoming webhooks have no file upload.
+func (s *Slack) SendNotificationWithAttachment(ctx context.Context, title, message string, _ *Attachment) error {
+	return s.SendNotification(ctx, title, message)
+}
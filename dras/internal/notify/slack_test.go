@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlackPostsText(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	s, err := NewSlack(BackendConfig{URL: server.URL + "/services/T000/B000/secret"})
+	if err != nil {
+		t.Fatalf("NewSlack() error: %v", err)
+	}
+	// Slack webhooks can't take files; the text still goes out.
+	att := &Attachment{Data: []byte("img")}
+	if err := s.SendNotificationWithAttachment(context.Background(), "KATX Update", "VCP changed", att); err != nil {
+		t.Fatalf("SendNotificationWithAttachment() error: %v", err)
+	}
+	if payload["text"] != "*KATX Update*\nVCP changed" {
+		t.Errorf("text = %q", payload["text"])
+	}
+}
+
+func TestSlackErrorsDoNotLeakWebhookSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	s, err := NewSlack(BackendConfig{URL: server.URL + "/services/T000/B000/secret"})
+	if err != nil {
+		t.Fatalf("NewSlack() error: %v", err)
+	}
+	err = s.SendNotification(context.Background(), "t", "m")
+	if err == nil {
+		t.Fatal("SendNotification() error = nil, want error")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %q contains the webhook path", err)
+	}
+}
+
+func TestSlackNetworkErrorsDoNotLeakWebhookSecret(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	s, err := NewSlack(BackendConfig{URL: server.URL + "/services/T000/B000/secret", HTTPClient: &http.Client{}})
+	if err != nil {
+		t.Fatalf("NewSlack() error: %v", err)
+	}
+	err = s.SendNotification(context.Background(), "t", "m")
+	if err == nil {
+		t.Fatal("SendNotification() error = nil, want error")
+	}
+	if strings.Contains(err.Error(), "secret") || strings.Contains(err.Error(), "/services/") {
+		t.Errorf("error %q contains the webhook path", err)
+	}
+}
@@ -0,0 +1,256 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// Webhook signing headers. The signature is
+//
+//	"sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// where timestamp is the X-DRAS-Timestamp value in Unix seconds. Receivers
+// should recompute it over the raw body and reject stale timestamps to
+// guard against replays.
+const (
+	WebhookSignatureHeader = "X-DRAS-Signature"
+	WebhookTimestampHeader = "X-DRAS-Timestamp"
+)
+
+// Webhook image modes.
+const (
+	// WebhookImageBase64 embeds the image in the payload (the default).
+	WebhookImageBase64 = "base64"
+	// WebhookImageURL sends the image's public URL instead, when there is one.
+	WebhookImageURL = "url"
+	// WebhookImageNone leaves images out.
+	WebhookImageNone = "none"
+)
+
+// Webhook POSTs each notification as a JSON document to one or more URLs,
+// optionally signed with HMAC-SHA256. Requests retry transient failures.
+type Webhook struct {
+	urls      []string
+	headers   map[string]string
+	secret    []byte
+	imageMode string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// WebhookPayload is the JSON body sent by Webhook. Fields that don't apply
+// to an event are omitted.
+type WebhookPayload struct {
+	// Event is "startup", "change", "outage", "resumed", "digest",
+	// "batch", "shutdown" or "test", or "message" for a plain
+	// notification.
+	Event       string          `json:"event"`
+	Station     string          `json:"station,omitempty"`
+	StationName string          `json:"station_name,omitempty"`
+	Title       string          `json:"title"`
+	Message     string          `json:"message"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Old         *radar.Data     `json:"old,omitempty"`
+	New         *radar.Data     `json:"new,omitempty"`
+	Changes     []radar.Change  `json:"changes,omitempty"`
+	Outage      *radar.Outage   `json:"outage,omitempty"`
+	Incident    *radar.Incident `json:"incident,omitempty"`
+	// Stations summarizes each station in a digest.
+	Stations []radar.StationSummary `json:"stations,omitempty"`
+	// Events are the payloads of the notifications in a batch.
+	Events     []WebhookPayload `json:"events,omitempty"`
+	Quiet      bool             `json:"quiet,omitempty"`
+	Attachment *WebhookImage    `json:"attachment,omitempty"`
+	ImageURL   string           `json:"image_url,omitempty"`
+}
+
+// WebhookImage is an image embedded in the payload.
+type WebhookImage struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	// Data is the base64-encoded image.
+	Data string `json:"data"`
+}
+
+// NewWebhook creates a webhook notifier. cfg.URL and cfg.URLs list the
+// endpoints (at least one is required); cfg.Headers are added to every
+// request; cfg.Secret, when set, signs every request; cfg.ImageMode is one
+// of "base64" (default), "url" or "none".
+func NewWebhook(cfg BackendConfig) (*Webhook, error) {
+	var urls []string
+	for _, u := range append([]string{cfg.URL}, cfg.URLs...) {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, errors.New("URL is required")
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(cfg.ImageMode))
+	switch mode {
+	case "":
+		mode = WebhookImageBase64
+	case WebhookImageBase64, WebhookImageURL, WebhookImageNone:
+	default:
+		return nil, fmt.Errorf("image mode must be %q, %q or %q, got %q", WebhookImageBase64, WebhookImageURL, WebhookImageNone, cfg.ImageMode)
+	}
+
+	w := &Webhook{
+		urls:      urls,
+		headers:   cfg.Headers,
+		imageMode: mode,
+		client:    httpClientFor(cfg),
+		now:       time.Now,
+	}
+	if cfg.Secret != "" {
+		w.secret = []byte(cfg.Secret)
+	}
+	return w, nil
+}
+
+// SendNotification posts a plain notification.
+func (w *Webhook) SendNotification(ctx context.Context, title, message string) error {
+	return w.SendNotificationWithAttachment(ctx, title, message, nil)
+}
+
+// SendNotificationWithAttachment posts a plain notification with an
+// optional image.
+func (w *Webhook) SendNotificationWithAttachment(ctx context.Context, title, message string, attachment *Attachment) error {
+	return w.SendEvent(ctx, Event{Title: title, Message: message, Attachment: attachment})
+}
+
+// SendEvent posts the event's structured data to every configured URL. A
+// failure at one URL does not stop delivery to the others. With several
+// URLs, failures are reported as a *DeliveryError naming each URL's host.
+func (w *Webhook) SendEvent(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(w.payload(ev))
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+
+	headers := make(map[string]string, len(w.headers)+2)
+	for k, v := range w.headers {
+		headers[k] = v
+	}
+	if w.secret != nil {
+		ts := w.now()
+		headers[WebhookTimestampHeader] = strconv.FormatInt(ts.Unix(), 10)
+		headers[WebhookSignatureHeader] = SignWebhookPayload(w.secret, ts, body)
+	}
+
+	if len(w.urls) == 1 {
+		return w.post(ctx, w.urls[0], body, headers)
+	}
+	var de DeliveryError
+	for _, u := range w.urls {
+		if err := w.post(ctx, u, body, headers); err != nil {
+			de.Failed = append(de.Failed, TargetError{Name: redactURL(u), Err: err})
+		} else {
+			de.Delivered = append(de.Delivered, redactURL(u))
+		}
+	}
+	if len(de.Failed) == 0 {
+		return nil
+	}
+	return &de
+}
+
+func (w *Webhook) payload(ev Event) WebhookPayload {
+	kind := string(ev.Kind)
+	if kind == "" {
+		kind = "message"
+	}
+	ts := ev.Time
+	if ts.IsZero() {
+		ts = w.now()
+	}
+	p := WebhookPayload{
+		Event:       kind,
+		Station:     ev.StationID,
+		StationName: ev.StationName,
+		Title:       ev.Title,
+		Message:     ev.Message,
+		Timestamp:   ts.UTC(),
+		Old:         ev.Old,
+		New:         ev.New,
+		Changes:     ev.Changes,
+		Outage:      ev.Outage,
+		Incident:    ev.Incident,
+		Stations:    ev.Stations,
+		Quiet:       ev.Quiet,
+	}
+
+	for _, inner := range ev.Events {
+		inner.Attachment = nil
+		p.Events = append(p.Events, w.payload(inner))
+	}
+
+	switch w.imageMode {
+	case WebhookImageBase64:
+		if ev.Attachment != nil && len(ev.Attachment.Data) > 0 {
+			p.Attachment = &WebhookImage{
+				Filename:    ev.Attachment.Filename,
+				ContentType: ev.Attachment.ContentType,
+				Data:        base64.StdEncoding.EncodeToString(ev.Attachment.Data),
+			}
+		}
+	case WebhookImageURL:
+		// Only link the image when the event would have carried one.
+		if ev.Attachment != nil {
+			p.ImageURL = ev.ImageURL
+		}
+	}
+	return p
+}
+
+func (w *Webhook) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return do(w.client, req)
+}
+
+// SignWebhookPayload returns the X-DRAS-Signature value for body sent at ts.
+func SignWebhookPayload(secret []byte, ts time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is valid for body and the
+// X-DRAS-Timestamp value timestamp, and that the timestamp is within maxAge
+// of now. A maxAge of zero skips the age check.
+func VerifyWebhookSignature(secret []byte, timestamp, signature string, body []byte, maxAge time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	ts := time.Unix(unix, 0)
+	if maxAge > 0 {
+		if age := time.Since(ts); age > maxAge || age < -maxAge {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(signature), []byte(SignWebhookPayload(secret, ts, body)))
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestWebhookPostsJSONWithHeaders(t *testing.T) {
+	var payload WebhookPayload
+	var custom string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		custom = r.Header.Get("X-Team")
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(BackendConfig{URL: server.URL, Headers: map[string]string{"X-Team": "wx"}})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	att := &Attachment{Data: []byte("img"), ContentType: "image/png", Filename: "KATX.png"}
+	if err := w.SendNotificationWithAttachment(context.Background(), "KATX Update", "VCP changed", att); err != nil {
+		t.Fatalf("SendNotificationWithAttachment() error: %v", err)
+	}
+
+	if custom != "wx" {
+		t.Errorf("X-Team header = %q, want wx", custom)
+	}
+	if payload.Title != "KATX Update" || payload.Message != "VCP changed" || payload.Timestamp.IsZero() {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.Attachment == nil || payload.Attachment.Data != base64.StdEncoding.EncodeToString([]byte("img")) {
+		t.Errorf("attachment = %+v", payload.Attachment)
+	}
+}
+
+func TestWebhookSendsSignedEvent(t *testing.T) {
+	secret := []byte("s3cret")
+	var body []byte
+	var sig, ts string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		sig, ts = r.Header.Get(WebhookSignatureHeader), r.Header.Get(WebhookTimestampHeader)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(BackendConfig{URL: server.URL, Secret: string(secret)})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	ev := Event{
+		Kind:        EventChange,
+		StationID:   "KATX",
+		StationName: "Seattle",
+		Old:         &radar.Data{VCP: "R31", Mode: "Clear Air"},
+		New:         &radar.Data{VCP: "R12", Mode: "Precipitation"},
+		Changes: []radar.Change{{
+			Field: radar.FieldVCP, Old: "R31", New: "R12",
+			Severity: radar.SeverityInfo, Text: "VCP changed from R31 to R12",
+		}},
+		Title:   "KATX Update",
+		Message: "VCP changed from R31 to R12",
+		Time:    time.Date(2026, 4, 26, 15, 32, 0, 0, time.UTC),
+	}
+	if err := Send(context.Background(), w, ev); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	if !VerifyWebhookSignature(secret, ts, sig, body, time.Minute) {
+		t.Errorf("signature %q (timestamp %q) does not verify", sig, ts)
+	}
+	if VerifyWebhookSignature([]byte("wrong"), ts, sig, body, 0) {
+		t.Error("signature verified with the wrong secret")
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != "change" || payload.Station != "KATX" || payload.StationName != "Seattle" {
+		t.Errorf("payload header = %+v", payload)
+	}
+	if payload.Old == nil || payload.Old.VCP != "R31" || payload.New == nil || payload.New.VCP != "R12" {
+		t.Errorf("old/new = %+v / %+v", payload.Old, payload.New)
+	}
+	if len(payload.Changes) != 1 || payload.Changes[0] != ev.Changes[0] {
+		t.Errorf("changes = %+v", payload.Changes)
+	}
+	if !payload.Timestamp.Equal(ev.Time) {
+		t.Errorf("timestamp = %v, want %v", payload.Timestamp, ev.Time)
+	}
+}
+
+func TestWebhookImageURLMode(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(BackendConfig{URL: server.URL, ImageMode: "url"})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	ev := Event{
+		Kind:       EventChange,
+		Attachment: &Attachment{Data: []byte("img")},
+		ImageURL:   "https://radar.weather.gov/ridge/standard/KATX_0.gif",
+	}
+	if err := w.SendEvent(context.Background(), ev); err != nil {
+		t.Fatalf("SendEvent() error: %v", err)
+	}
+	if payload.ImageURL != ev.ImageURL || payload.Attachment != nil {
+		t.Errorf("image_url %q, attachment %+v; want URL only", payload.ImageURL, payload.Attachment)
+	}
+}
+
+func TestWebhookSendsDigestStations(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(BackendConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	ev := Event{
+		Kind: EventDigest,
+		Stations: []radar.StationSummary{
+			{StationID: "KATX", Data: &radar.Data{VCP: "R35"}},
+			{StationID: "KRAX"},
+		},
+		Title: "DRAS Digest",
+	}
+	if err := w.SendEvent(context.Background(), ev); err != nil {
+		t.Fatalf("SendEvent() error: %v", err)
+	}
+	if payload.Event != "digest" || payload.Station != "" || len(payload.Stations) != 2 || payload.Stations[0].Data.VCP != "R35" || payload.Stations[1].Data != nil {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookSendsBatchedEvents(t *testing.T) {
+	var payload WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	w, err := NewWebhook(BackendConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	ev := Event{
+		Kind:  EventBatch,
+		Title: "DRAS: 2 Notifications",
+		Events: []Event{
+			{Kind: EventChange, StationID: "KATX", New: &radar.Data{VCP: "R212"}, Attachment: &Attachment{Data: []byte("img")}},
+			{Kind: EventOutage, StationID: "KRAX", Outage: &radar.Outage{Reason: radar.OutageUnreachable}},
+		},
+	}
+	if err := w.SendEvent(context.Background(), ev); err != nil {
+		t.Fatalf("SendEvent() error: %v", err)
+	}
+	if payload.Event != "batch" || len(payload.Events) != 2 {
+		t.Fatalf("payload = %+v, want a batch of two", payload)
+	}
+	if inner := payload.Events[0]; inner.Event != "change" || inner.Station != "KATX" || inner.New.VCP != "R212" || inner.Attachment != nil {
+		t.Errorf("first event = %+v, want the KATX change without its image", inner)
+	}
+	if inner := payload.Events[1]; inner.Event != "outage" || inner.Outage == nil {
+		t.Errorf("second event = %+v, want the KRAX outage", inner)
+	}
+}
+
+func TestWebhookReportsFailingURLsIndividually(t *testing.T) {
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer good.Close()
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer bad.Close()
+
+	w, err := NewWebhook(BackendConfig{URLs: []string{good.URL, bad.URL}})
+	if err != nil {
+		t.Fatalf("NewWebhook() error: %v", err)
+	}
+	err = w.SendNotification(context.Background(), "t", "m")
+	var de *DeliveryError
+	if !errors.As(err, &de) || len(de.Delivered) != 1 || len(de.Failed) != 1 {
+		t.Fatalf("error = %v, want a DeliveryError with one delivered and one failed URL", err)
+	}
+}
+
+func TestNewWebhookRejectsUnknownImageMode(t *testing.T) {
+	if _, err := NewWebhook(BackendConfig{URL: "https://example.com", ImageMode: "inline"}); err == nil {
+		t.Error("NewWebhook() error = nil, want image mode error")
+	}
+}
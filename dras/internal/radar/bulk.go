@@ -0,0 +1,86 @@
+package radar
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// maxCollectionSize caps how much of the station collection is read. The
+// WSR-88D collection, with every station's RDA and performance properties,
+// is a few megabytes.
+const maxCollectionSize = 32 << 20
+
+// BulkFetcher is implemented by fetchers that can get the data of many
+// stations in one request.
+type BulkFetcher interface {
+	// FetchAll returns the data of those of stationIDs it could get, by
+	// station ID. A station that is missing from the result, or whose data
+	// cannot be read, is left out rather than failing the call; the caller
+	// fetches it on its own. An error means nothing could be fetched.
+	FetchAll(ctx context.Context, stationIDs []string) (map[string]*Data, error)
+}
+
+// collectionResponse is the part of the NWS API's radar station collection
+// (GET /radar/stations, a GeoJSON FeatureCollection) that DRAS uses.
+type collectionResponse struct {
+	Features []stationResponse `json:"features"`
+}
+
+// FetchAll retrieves the data of every requested station from the NWS
+// API's station collection, in a single request however many stations
+// there are. The collection is not cached, and does not update the cache
+// FetchData uses.
+func (s *Service) FetchAll(ctx context.Context, stationIDs []string) (map[string]*Data, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/radar/stations?stationType=WSR-88D", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/geo+json")
+	req.Header.Set("User-Agent", s.userAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get RADAR station collection: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body := io.LimitReader(resp.Body, maxCollectionSize)
+	if resp.StatusCode != http.StatusOK {
+		var p problem
+		_ = json.NewDecoder(body).Decode(&p)
+		if msg := cmp.Or(p.Detail, p.Title); msg != "" {
+			return nil, fmt.Errorf("NWS API returned status %d for the station collection: %s", resp.StatusCode, msg)
+		}
+		return nil, fmt.Errorf("NWS API returned status %d for the station collection", resp.StatusCode)
+	}
+
+	var collection collectionResponse
+	if err := json.NewDecoder(body).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("decode NWS station collection: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(stationIDs))
+	for _, id := range stationIDs {
+		wanted[id] = true
+	}
+	out := make(map[string]*Data, len(stationIDs))
+	for i := range collection.Features {
+		station := &collection.Features[i]
+		id := station.Properties.ID
+		if !wanted[id] {
+			continue
+		}
+		data, err := stationData(id, station)
+		if err != nil {
+			slog.Debug("Skipping station in NWS collection", "station", id, "error", err)
+			continue
+		}
+		out[id] = data
+	}
+	return out, nil
+}
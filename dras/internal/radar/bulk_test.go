@@ -0,0 +1,62 @@
+package radar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// collectionDocument is a trimmed GET /radar/stations response: KATX as in
+// stationDocument, KRAX with a generator state DRAS cannot read, and a
+// station nobody asked for.
+const collectionDocument = `{
+  "type": "FeatureCollection",
+  "features": [
+    ` + stationDocument + `,
+    {"type": "Feature", "properties": {"id": "KRAX", "name": "RALEIGH/DURHAM", "rda": {"properties": {"volumeCoveragePattern": "R212", "generatorState": "Mystery"}}}},
+    {"type": "Feature", "properties": {"id": "KLOT", "name": "CHICAGO", "rda": {"properties": {"volumeCoveragePattern": "R31", "generatorState": "Utility PWR Available"}}}}
+  ]
+}`
+
+func TestServiceFetchAll(t *testing.T) {
+	var requests int
+	var gotURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		gotURL = r.URL.String()
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(collectionDocument))
+	}))
+	defer server.Close()
+
+	s := New(Config{BaseURL: server.URL})
+	got, err := s.FetchAll(context.Background(), []string{"KATX", "KRAX", "KFOO"})
+	if err != nil {
+		t.Fatalf("FetchAll() error: %v", err)
+	}
+	if requests != 1 || gotURL != "/radar/stations?stationType=WSR-88D" {
+		t.Errorf("requests = %d to %q, want one to /radar/stations?stationType=WSR-88D", requests, gotURL)
+	}
+	if len(got) != 1 || got["KATX"] == nil {
+		t.Fatalf("FetchAll() = %v, want only KATX", got)
+	}
+	if d := got["KATX"]; d.VCP != "R35" || d.GenState != "Off" || d.PowerSource != "Utility" {
+		t.Errorf("KATX = %+v", *d)
+	}
+}
+
+func TestServiceFetchAllError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"title": "Service Unavailable"}`))
+	}))
+	defer server.Close()
+
+	s := New(Config{BaseURL: server.URL, HTTPClient: server.Client()})
+	_, err := s.FetchAll(context.Background(), []string{"KATX"})
+	if err == nil || !strings.Contains(err.Error(), "status 503 for the station collection: Service Unavailable") {
+		t.Errorf("FetchAll() error = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package radar
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheResult says how a FetchData call used the station cache.
+type CacheResult string
+
+const (
+	// CacheHit means the cached data was still fresh and no request was
+	// made.
+	CacheHit CacheResult = "hit"
+	// CacheRevalidated means a conditional request was answered with 304
+	// Not Modified and the cached data was reused.
+	CacheRevalidated CacheResult = "revalidated"
+	// CacheMiss means the full document was downloaded.
+	CacheMiss CacheResult = "miss"
+)
+
+// maxFreshness caps how long a response is served from the cache without
+// asking the NWS API, whatever its caching headers allow, so that a change
+// is never noticed much later than the poll that should have seen it. A
+// shorter cap can be set with SetMaxFreshness.
+const maxFreshness = 5 * time.Minute
+
+// FreshnessCapper is implemented by fetchers that cache responses. The
+// monitor caps their freshness to its poll interval, so that a cached
+// response never delays a change past the poll that should see it.
+type FreshnessCapper interface {
+	// SetMaxFreshness caps how long a response is served from the cache.
+	// d <= 0 restores the default cap.
+	SetMaxFreshness(d time.Duration)
+}
+
+// cacheEntry is the last response for a station: the data derived from it,
+// its validators for conditional requests, and until when it is fresh.
+type cacheEntry struct {
+	data         *Data
+	etag         string
+	lastModified string
+	expires      time.Time
+}
+
+// newCacheEntry caches data from a response with the given headers, fresh
+// for at most limit. It returns nil when the response must not be stored.
+func newCacheEntry(data *Data, header http.Header, now time.Time, limit time.Duration) *cacheEntry {
+	expires, store := freshUntil(header, now, limit)
+	if !store {
+		return nil
+	}
+	return &cacheEntry{
+		data:         data,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+		expires:      expires,
+	}
+}
+
+// revalidate returns e updated from the headers of a 304 response, which
+// may carry new validators and a new lifetime, capped at limit.
+func (e *cacheEntry) revalidate(header http.Header, now time.Time, limit time.Duration) *cacheEntry {
+	expires, store := freshUntil(header, now, limit)
+	if !store {
+		return nil
+	}
+	next := *e
+	next.expires = expires
+	if v := header.Get("ETag"); v != "" {
+		next.etag = v
+	}
+	if v := header.Get("Last-Modified"); v != "" {
+		next.lastModified = v
+	}
+	return &next
+}
+
+// dataCopy returns a copy of the cached data, so callers cannot change the
+// cache through it.
+func (e *cacheEntry) dataCopy() *Data {
+	d := *e.data
+	return &d
+}
+
+// freshUntil reads the response's lifetime from Cache-Control (max-age,
+// less Age) or, failing that, Expires relative to Date, capped at limit. It
+// reports false
+// when the response must not be stored (no-store). A response without a
+// lifetime, or with no-cache, is stored but stale at once, so it is only
+// used to revalidate.
+func freshUntil(header http.Header, now time.Time, limit time.Duration) (time.Time, bool) {
+	var maxAge time.Duration
+	hasMaxAge := false
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			return time.Time{}, false
+		case "no-cache":
+			return now, true
+		case "max-age":
+			if secs, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				maxAge, hasMaxAge = time.Duration(secs)*time.Second, true
+			}
+		}
+	}
+
+	var lifetime time.Duration
+	switch {
+	case hasMaxAge:
+		lifetime = maxAge
+		if age, err := strconv.Atoi(header.Get("Age")); err == nil {
+			lifetime -= time.Duration(age) * time.Second
+		}
+	case header.Get("Expires") != "":
+		expires, err := http.ParseTime(header.Get("Expires"))
+		if err != nil {
+			// An invalid Expires means already expired (RFC 9111 5.3).
+			return now, true
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			date = now
+		}
+		lifetime = expires.Sub(date)
+	}
+	return now.Add(max(0, min(lifetime, limit))), true
+}
+
+// cached returns the station's cache entry, if any.
+func (s *Service) cached(stationID string) *cacheEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cache[stationID]
+}
+
+// store replaces the station's cache entry; a nil entry removes it.
+func (s *Service) store(stationID string, e *cacheEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e == nil {
+		delete(s.cache, stationID)
+		return
+	}
+	s.cache[stationID] = e
+}
+
+// observeCache logs how a fetch used the cache and reports it to OnCache.
+func (s *Service) observeCache(stationID string, result CacheResult) {
+	slog.Debug("Fetched NWS station data", "station", stationID, "cache", string(result))
+	if s.onCache != nil {
+		s.onCache(result)
+	}
+}
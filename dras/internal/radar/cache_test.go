@@ -0,0 +1,199 @@
+package radar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// cachingServer serves stationDocument with the given caching headers and
+// answers a matching If-None-Match with 304.
+type cachingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	etag     string
+	header   http.Header
+	requests []http.Header
+}
+
+func newCachingServer(t *testing.T, etag string, header http.Header) *cachingServer {
+	cs := &cachingServer{etag: etag, header: header}
+	cs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cs.mu.Lock()
+		defer cs.mu.Unlock()
+		cs.requests = append(cs.requests, r.Header.Clone())
+		for k, v := range cs.header {
+			w.Header()[k] = v
+		}
+		if cs.etag != "" {
+			w.Header().Set("ETag", cs.etag)
+			if r.Header.Get("If-None-Match") == cs.etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Write([]byte(stationDocument))
+	}))
+	t.Cleanup(cs.Close)
+	return cs
+}
+
+func (cs *cachingServer) requestCount() int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return len(cs.requests)
+}
+
+// newCachingService returns a Service against cs whose clock is *now and
+// that records every cache result.
+func newCachingService(cs *cachingServer, now *time.Time) (*Service, *[]CacheResult) {
+	var results []CacheResult
+	s := New(Config{BaseURL: cs.URL, OnCache: func(r CacheResult) { results = append(results, r) }})
+	s.now = func() time.Time { return *now }
+	return s, &results
+}
+
+func TestFetchDataRevalidatesWithETag(t *testing.T) {
+	cs := newCachingServer(t, `"v1"`, http.Header{"Cache-Control": {"max-age=0"}})
+	now := time.Now()
+	s, results := newCachingService(cs, &now)
+	ctx := context.Background()
+
+	first, err := s.FetchData(ctx, "KATX")
+	if err != nil {
+		t.Fatalf("first FetchData() error: %v", err)
+	}
+	second, err := s.FetchData(ctx, "KATX")
+	if err != nil {
+		t.Fatalf("second FetchData() error: %v", err)
+	}
+	if *second != *first {
+		t.Errorf("revalidated data = %+v, want %+v", *second, *first)
+	}
+	if second == first {
+		t.Error("revalidated data shares the cached pointer")
+	}
+	if got := cs.requests[1].Get("If-None-Match"); got != `"v1"` {
+		t.Errorf("If-None-Match = %q, want the ETag", got)
+	}
+	if got := strings.Join([]string{string((*results)[0]), string((*results)[1])}, ","); got != "miss,revalidated" {
+		t.Errorf("cache results = %s, want miss,revalidated", got)
+	}
+
+	// A new ETag means a new document.
+	cs.mu.Lock()
+	cs.etag = `"v2"`
+	cs.mu.Unlock()
+	if _, err := s.FetchData(ctx, "KATX"); err != nil {
+		t.Fatalf("third FetchData() error: %v", err)
+	}
+	if (*results)[2] != CacheMiss {
+		t.Errorf("cache result after a changed ETag = %s, want miss", (*results)[2])
+	}
+}
+
+func TestFetchDataServesFreshResponsesFromCache(t *testing.T) {
+	cs := newCachingServer(t, "", http.Header{
+		"Cache-Control": {"public, max-age=120"},
+		"Last-Modified": {"Fri, 16 Oct 2026 12:00:00 GMT"},
+	})
+	now := time.Now()
+	s, results := newCachingService(cs, &now)
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := s.FetchData(ctx, "KATX"); err != nil {
+			t.Fatalf("FetchData() error: %v", err)
+		}
+	}
+	if cs.requestCount() != 1 || (*results)[1] != CacheHit {
+		t.Errorf("requests = %d, results = %v; want one request then a hit", cs.requestCount(), *results)
+	}
+
+	// Once stale, the entry is revalidated with Last-Modified.
+	now = now.Add(121 * time.Second)
+	if _, err := s.FetchData(ctx, "KATX"); err != nil {
+		t.Fatalf("FetchData() error: %v", err)
+	}
+	if cs.requestCount() != 2 {
+		t.Fatalf("requests = %d, want a second one once stale", cs.requestCount())
+	}
+	if got := cs.requests[1].Get("If-Modified-Since"); got != "Fri, 16 Oct 2026 12:00:00 GMT" {
+		t.Errorf("If-Modified-Since = %q", got)
+	}
+}
+
+func TestFetchDataCapsFreshness(t *testing.T) {
+	cs := newCachingServer(t, `"v1"`, http.Header{"Cache-Control": {"max-age=300"}})
+	now := time.Now()
+	s, results := newCachingService(cs, &now)
+	s.SetMaxFreshness(time.Minute)
+	ctx := context.Background()
+
+	for _, step := range []time.Duration{0, 30 * time.Second, 31 * time.Second} {
+		now = now.Add(step)
+		if _, err := s.FetchData(ctx, "KATX"); err != nil {
+			t.Fatalf("FetchData() error: %v", err)
+		}
+	}
+	if got := strings.Join([]string{string((*results)[0]), string((*results)[1]), string((*results)[2])}, ","); got != "miss,hit,revalidated" {
+		t.Errorf("cache results = %s, want the max-age capped at a minute", got)
+	}
+
+	s.SetMaxFreshness(0)
+	if got := time.Duration(s.freshness.Load()); got != maxFreshness {
+		t.Errorf("freshness after SetMaxFreshness(0) = %v, want the default", got)
+	}
+}
+
+func TestFetchDataHonorsNoStore(t *testing.T) {
+	cs := newCachingServer(t, `"v1"`, http.Header{"Cache-Control": {"no-store"}})
+	now := time.Now()
+	s, results := newCachingService(cs, &now)
+
+	for range 2 {
+		if _, err := s.FetchData(context.Background(), "KATX"); err != nil {
+			t.Fatalf("FetchData() error: %v", err)
+		}
+	}
+	if got := cs.requests[1].Get("If-None-Match"); got != "" {
+		t.Errorf("If-None-Match = %q on an uncacheable response", got)
+	}
+	if (*results)[1] != CacheMiss {
+		t.Errorf("cache results = %v, want two misses", *results)
+	}
+}
+
+func TestFreshUntil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name      string
+		header    http.Header
+		want      time.Duration
+		wantStore bool
+	}{
+		{"no headers", http.Header{}, 0, true},
+		{"max-age", http.Header{"Cache-Control": {"public, max-age=60"}}, time.Minute, true},
+		{"max-age less age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"20"}}, 40 * time.Second, true},
+		{"capped", http.Header{"Cache-Control": {"max-age=86400"}}, maxFreshness, true},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache, max-age=60"}}, 0, true},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}}, 0, false},
+		{"expires", http.Header{"Date": {"Fri, 16 Oct 2026 11:00:00 GMT"}, "Expires": {"Fri, 16 Oct 2026 11:01:30 GMT"}}, 90 * time.Second, true},
+		{"invalid expires", http.Header{"Expires": {"0"}}, 0, true},
+		{"expired", http.Header{"Cache-Control": {"max-age=10"}, "Age": {"30"}}, 0, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, store := freshUntil(tt.header, now, maxFreshness)
+			if store != tt.wantStore {
+				t.Fatalf("store = %t, want %t", store, tt.wantStore)
+			}
+			if store && got.Sub(now) != tt.want {
+				t.Errorf("fresh for %v, want %v", got.Sub(now), tt.want)
+			}
+		})
+	}
+}
@@ -3,59 +3,315 @@ package radar
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // AlertConfig holds configuration for which events to alert on.
 type AlertConfig struct {
-	VCP         bool
-	Status      bool
-	Operability bool
-	PowerSource bool
-	GenState    bool
+	VCP         bool `json:"vcp"`
+	Status      bool `json:"status"`
+	Operability bool `json:"operability"`
+	PowerSource bool `json:"power_source"`
+	GenState    bool `json:"gen_state"`
 }
 
-// CompareData compares the old and new radar data and returns whether there are any changes and the details of the changes.
-// It takes two pointers to Data structs as input and returns a boolean indicating if there are any changes and a string containing the details of the changes.
-func CompareData(oldData, newData *Data, alertConfig AlertConfig) (bool, string) {
-	var changes []string
+// Field identifies a radar.Data field whose change can trigger an alert.
+type Field string
+
+const (
+	FieldVCP         Field = "vcp"
+	FieldStatus      Field = "status"
+	FieldOperability Field = "operability"
+	FieldPowerSource Field = "power_source"
+	FieldGenState    Field = "gen_state"
+)
+
+// Fields lists every Field, in the order Diff reports them.
+func Fields() []Field {
+	return []Field{FieldVCP, FieldStatus, FieldOperability, FieldPowerSource, FieldGenState}
+}
+
+// Severity ranks how much a change matters to whoever is watching the radar.
+type Severity string
+
+const (
+	// SeverityInfo is routine: a scan-mode switch, or a return to normal.
+	SeverityInfo Severity = "info"
+	// SeverityWarning is degraded but working, e.g. running on generator.
+	SeverityWarning Severity = "warning"
+	// SeverityCritical means the radar is down or out of service.
+	SeverityCritical Severity = "critical"
+)
+
+// Change is a single detected change between two radar.Data snapshots.
+type Change struct {
+	Field    Field    `json:"field"`
+	Old      string   `json:"old"`
+	New      string   `json:"new"`
+	Severity Severity `json:"severity"`
+	// Text is the human-readable description used in notifications.
+	Text string `json:"text"`
+	// Flaps counts earlier changes of this field that were suppressed by
+	// debouncing because they didn't last.
+	Flaps int `json:"flaps,omitempty"`
+}
+
+// Diff returns the changes from oldData to newData for every field enabled
+// in alertConfig, in a fixed order: VCP, status, operability, power source,
+// generator state.
+func Diff(oldData, newData *Data, alertConfig AlertConfig) []Change {
+	var changes []Change
 
 	if alertConfig.VCP && oldData.VCP != newData.VCP {
-		// Look up the new VCP in the catalog. Known VCPs surface their
-		// AlertText (preferred) or fall back to "<Mode> Mode Active".
-		// Unknown VCPs report the raw transition so users still see what
-		// changed.
-		if info, err := GetVCPInfo(newData.VCP); err == nil {
-			switch {
-			case info.AlertText != "":
-				changes = append(changes, info.AlertText)
-			default:
-				changes = append(changes, fmt.Sprintf("%s Mode Active", info.Mode))
-			}
-		} else {
-			changes = append(changes, fmt.Sprintf("Radar mode changed from %s to %s", oldData.VCP, newData.VCP))
-		}
+		changes = append(changes, Change{
+			Field:    FieldVCP,
+			Old:      oldData.VCP,
+			New:      newData.VCP,
+			Severity: SeverityInfo,
+			Text:     vcpChangeText(oldData.VCP, newData.VCP),
+		})
 	}
 
 	if alertConfig.Status && oldData.Status != newData.Status {
-		changes = append(changes, fmt.Sprintf("Radar status changed from %s to %s", oldData.Status, newData.Status))
+		changes = append(changes, Change{
+			Field:    FieldStatus,
+			Old:      oldData.Status,
+			New:      newData.Status,
+			Severity: statusSeverity(newData.Status),
+			Text:     fmt.Sprintf("Radar status changed from %s to %s", oldData.Status, newData.Status),
+		})
 	}
 
 	if alertConfig.Operability && oldData.OperabilityStatus != newData.OperabilityStatus {
-		changes = append(changes, fmt.Sprintf("Radar operability changed from %s to %s", oldData.OperabilityStatus, newData.OperabilityStatus))
+		changes = append(changes, Change{
+			Field:    FieldOperability,
+			Old:      oldData.OperabilityStatus,
+			New:      newData.OperabilityStatus,
+			Severity: operabilitySeverity(newData.OperabilityStatus),
+			Text:     fmt.Sprintf("Radar operability changed from %s to %s", oldData.OperabilityStatus, newData.OperabilityStatus),
+		})
 	}
 
 	if alertConfig.PowerSource && oldData.PowerSource != newData.PowerSource {
-		changes = append(changes, fmt.Sprintf("Power source changed from %s to %s", oldData.PowerSource, newData.PowerSource))
+		changes = append(changes, Change{
+			Field:    FieldPowerSource,
+			Old:      oldData.PowerSource,
+			New:      newData.PowerSource,
+			Severity: powerSourceSeverity(newData.PowerSource),
+			Text:     fmt.Sprintf("Power source changed from %s to %s", oldData.PowerSource, newData.PowerSource),
+		})
 	}
 
 	if alertConfig.GenState && oldData.GenState != newData.GenState {
-		changes = append(changes, fmt.Sprintf("Generator state changed from %s to %s", oldData.GenState, newData.GenState))
+		changes = append(changes, Change{
+			Field:    FieldGenState,
+			Old:      oldData.GenState,
+			New:      newData.GenState,
+			Severity: genStateSeverity(newData.GenState),
+			Text:     fmt.Sprintf("Generator state changed from %s to %s", oldData.GenState, newData.GenState),
+		})
+	}
+
+	return changes
+}
+
+// CompareData compares the old and new radar data and returns whether there are any changes and the details of the changes.
+// The details are the Text of each change from Diff, one per line.
+func CompareData(oldData, newData *Data, alertConfig AlertConfig) (bool, string) {
+	changes := Diff(oldData, newData, alertConfig)
+	if len(changes) == 0 {
+		return false, ""
+	}
+	return true, JoinText(changes)
+}
+
+// Same reports whether a and b hold the same radar state, ignoring
+// UpdatedAt, e.g. because the NWS API answered 304 Not Modified. A nil
+// Data is only the same as another nil.
+func Same(a, b *Data) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.UpdatedAt, y.UpdatedAt = time.Time{}, time.Time{}
+	return x == y
+}
+
+// JoinText returns the Text of each change, one per line.
+func JoinText(changes []Change) string {
+	texts := make([]string, len(changes))
+	for i, c := range changes {
+		texts[i] = c.Text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// KeepFields returns a copy of newData with the given fields taken from
+// oldData instead. Keeping FieldVCP keeps the mode derived from it too.
+func KeepFields(newData, oldData *Data, fields []Field) *Data {
+	merged := *newData
+	for _, f := range fields {
+		switch f {
+		case FieldVCP:
+			merged.VCP, merged.Mode = oldData.VCP, oldData.Mode
+		case FieldStatus:
+			merged.Status = oldData.Status
+		case FieldOperability:
+			merged.OperabilityStatus = oldData.OperabilityStatus
+		case FieldPowerSource:
+			merged.PowerSource = oldData.PowerSource
+		case FieldGenState:
+			merged.GenState = oldData.GenState
+		}
+	}
+	return &merged
+}
+
+// HasField reports whether any of the changes is to field.
+func HasField(changes []Change, field Field) bool {
+	for _, c := range changes {
+		if c.Field == field {
+			return true
+		}
 	}
+	return false
+}
 
-	if len(changes) > 0 {
-		return true, strings.Join(changes, "\n")
+// MaxSeverity returns the highest severity among changes, or SeverityInfo
+// when there are none.
+func MaxSeverity(changes []Change) Severity {
+	max := SeverityInfo
+	for _, c := range changes {
+		if severityRank[c.Severity] > severityRank[max] {
+			max = c.Severity
+		}
 	}
+	return max
+}
 
-	return false, ""
+// Higher returns the higher of s and other.
+func (s Severity) Higher(other Severity) Severity {
+	if severityRank[other] > severityRank[s] {
+		return other
+	}
+	return s
+}
+
+// StateSeverity rates a radar's current state: the highest FieldSeverity of
+// its status, operability, power source and generator state. Fields left
+// empty are not rated.
+func StateSeverity(d *Data) Severity {
+	max := SeverityInfo
+	for _, f := range []struct {
+		field Field
+		value string
+	}{
+		{FieldStatus, d.Status},
+		{FieldOperability, d.OperabilityStatus},
+		{FieldPowerSource, d.PowerSource},
+		{FieldGenState, d.GenState},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if s := FieldSeverity(f.field, f.value); severityRank[s] > severityRank[max] {
+			max = s
+		}
+	}
+	return max
+}
+
+// Recovered reports whether changes bring the radar back to normal: none of
+// them is above SeverityInfo, and at least one leaves a field that was at
+// warning or critical severity. A VCP switch on its own is never a recovery.
+func Recovered(changes []Change) bool {
+	recovered := false
+	for _, c := range changes {
+		if c.Severity != SeverityInfo {
+			return false
+		}
+		if severityRank[FieldSeverity(c.Field, c.Old)] > severityRank[SeverityInfo] {
+			recovered = true
+		}
+	}
+	return recovered
+}
+
+// FieldSeverity rates a single field value with the same rules Diff uses
+// for the value a field changed to.
+func FieldSeverity(field Field, value string) Severity {
+	switch field {
+	case FieldStatus:
+		return statusSeverity(value)
+	case FieldOperability:
+		return operabilitySeverity(value)
+	case FieldPowerSource:
+		return powerSourceSeverity(value)
+	case FieldGenState:
+		return genStateSeverity(value)
+	default:
+		return SeverityInfo
+	}
 }
 
+var severityRank = map[Severity]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityCritical: 2,
+}
+
+// vcpChangeText looks up the new VCP in the catalog. Known VCPs surface
+// their AlertText (preferred) or fall back to "<Mode> Mode Active". Unknown
+// VCPs report the raw transition so users still see what changed.
+func vcpChangeText(oldVCP, newVCP string) string {
+	info, err := GetVCPInfo(newVCP)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("Radar mode changed from %s to %s", oldVCP, newVCP)
+	case info.AlertText != "":
+		return info.AlertText
+	default:
+		return fmt.Sprintf("%s Mode Active", info.Mode)
+	}
+}
+
+// statusSeverity rates an RDA status: "Operate" is normal, anything else
+// means the radar is not producing data.
+func statusSeverity(status string) Severity {
+	if status == "Operate" {
+		return SeverityInfo
+	}
+	return SeverityCritical
+}
+
+// operabilitySeverity rates an RDA operability status. "Maintenance
+// Required" still scans; "Maintenance Mandatory", "Commanded Shutdown" and
+// "Inoperable" do not.
+func operabilitySeverity(status string) Severity {
+	switch {
+	case strings.HasSuffix(status, "On-line"):
+		return SeverityInfo
+	case strings.HasSuffix(status, "Maintenance Required"):
+		return SeverityWarning
+	default:
+		return SeverityCritical
+	}
+}
+
+// powerSourceSeverity rates a power source: commercial utility power is
+// normal, anything else (generator/auxiliary) is a warning.
+func powerSourceSeverity(source string) Severity {
+	if strings.Contains(strings.ToLower(source), "utility") {
+		return SeverityInfo
+	}
+	return SeverityWarning
+}
+
+// genStateSeverity rates a generator state: a running generator means the
+// site has lost utility power.
+func genStateSeverity(state string) Severity {
+	if state == "On" {
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
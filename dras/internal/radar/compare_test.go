@@ -3,6 +3,7 @@ package radar
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCompareData(t *testing.T) {
@@ -182,3 +183,181 @@ func TestCompareData(t *testing.T) {
 		}
 	})
 }
+
+func TestDiff(t *testing.T) {
+	oldData := &Data{
+		VCP:               "R31",
+		Status:            "Operate",
+		OperabilityStatus: "RDA - On-line",
+		PowerSource:       "Commercial Utility",
+		GenState:          "Off",
+	}
+	newData := &Data{
+		VCP:               "R212",
+		Status:            "Standby",
+		OperabilityStatus: "RDA - Maintenance Required",
+		PowerSource:       "Auxiliary Power",
+		GenState:          "On",
+	}
+	all := AlertConfig{VCP: true, Status: true, Operability: true, PowerSource: true, GenState: true}
+
+	changes := Diff(oldData, newData, all)
+	want := []Change{
+		{Field: FieldVCP, Old: "R31", New: "R212", Severity: SeverityInfo, Text: "Precipitation Mode Active"},
+		{Field: FieldStatus, Old: "Operate", New: "Standby", Severity: SeverityCritical, Text: "Radar status changed from Operate to Standby"},
+		{Field: FieldOperability, Old: "RDA - On-line", New: "RDA - Maintenance Required", Severity: SeverityWarning, Text: "Radar operability changed from RDA - On-line to RDA - Maintenance Required"},
+		{Field: FieldPowerSource, Old: "Commercial Utility", New: "Auxiliary Power", Severity: SeverityWarning, Text: "Power source changed from Commercial Utility to Auxiliary Power"},
+		{Field: FieldGenState, Old: "Off", New: "On", Severity: SeverityWarning, Text: "Generator state changed from Off to On"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff() returned %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if MaxSeverity(changes) != SeverityCritical {
+		t.Errorf("MaxSeverity() = %q, want critical", MaxSeverity(changes))
+	}
+	if SeverityWarning.Higher(SeverityCritical) != SeverityCritical || SeverityWarning.Higher(SeverityInfo) != SeverityWarning {
+		t.Error("Higher() did not pick the higher severity")
+	}
+	if !HasField(changes, FieldGenState) || HasField(changes[:1], FieldStatus) {
+		t.Error("HasField() gave the wrong answer")
+	}
+
+	// CompareData is Diff's text, one change per line.
+	changed, message := CompareData(oldData, newData, all)
+	if !changed || message != JoinText(changes) {
+		t.Errorf("CompareData() = %v, %q; want Diff text", changed, message)
+	}
+
+	// Recovery back to normal is informational.
+	recovered := Diff(newData, oldData, AlertConfig{Status: true, PowerSource: true, GenState: true})
+	if MaxSeverity(recovered) != SeverityInfo {
+		t.Errorf("recovery MaxSeverity() = %q, want info: %+v", MaxSeverity(recovered), recovered)
+	}
+
+	if got := Diff(oldData, newData, AlertConfig{}); len(got) != 0 {
+		t.Errorf("Diff() with all alerts off = %+v, want none", got)
+	}
+}
+
+func TestRecovered(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []Change
+		want    bool
+	}{
+		{
+			name:    "no changes",
+			changes: nil,
+			want:    false,
+		},
+		{
+			name:    "VCP switch only",
+			changes: []Change{{Field: FieldVCP, Old: "R31", New: "R12", Severity: SeverityInfo}},
+			want:    false,
+		},
+		{
+			name:    "back to utility power",
+			changes: []Change{{Field: FieldPowerSource, Old: "Auxiliary Power", New: "Commercial Utility", Severity: SeverityInfo}},
+			want:    true,
+		},
+		{
+			name: "status recovers with a VCP switch",
+			changes: []Change{
+				{Field: FieldVCP, Old: "R31", New: "R12", Severity: SeverityInfo},
+				{Field: FieldStatus, Old: "Standby", New: "Operate", Severity: SeverityInfo},
+			},
+			want: true,
+		},
+		{
+			name: "partial recovery",
+			changes: []Change{
+				{Field: FieldStatus, Old: "Standby", New: "Operate", Severity: SeverityInfo},
+				{Field: FieldGenState, Old: "Off", New: "On", Severity: SeverityWarning},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Recovered(tt.changes); got != tt.want {
+				t.Errorf("Recovered() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateSeverity(t *testing.T) {
+	tests := []struct {
+		name string
+		data Data
+		want Severity
+	}{
+		{
+			name: "normal",
+			data: Data{Status: "Operate", OperabilityStatus: "RDA - On-line", PowerSource: "Commercial Utility", GenState: "Off"},
+			want: SeverityInfo,
+		},
+		{
+			name: "on generator",
+			data: Data{Status: "Operate", OperabilityStatus: "RDA - On-line", PowerSource: "Auxiliary Power", GenState: "On"},
+			want: SeverityWarning,
+		},
+		{
+			name: "down",
+			data: Data{Status: "Standby", OperabilityStatus: "RDA - On-line", PowerSource: "Auxiliary Power"},
+			want: SeverityCritical,
+		},
+		{
+			name: "empty fields are not rated",
+			data: Data{VCP: "R35"},
+			want: SeverityInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StateSeverity(&tt.data); got != tt.want {
+				t.Errorf("StateSeverity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepFields(t *testing.T) {
+	oldData := &Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Operate", PowerSource: "Commercial Utility"}
+	newData := &Data{Name: "Seattle", VCP: "R12", Mode: "Precipitation", Status: "Standby", PowerSource: "Auxiliary Power"}
+
+	got := KeepFields(newData, oldData, []Field{FieldVCP, FieldPowerSource})
+	want := Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", Status: "Standby", PowerSource: "Commercial Utility"}
+	if *got != want {
+		t.Errorf("KeepFields() = %+v, want %+v", *got, want)
+	}
+	if newData.VCP != "R12" {
+		t.Error("KeepFields() modified newData")
+	}
+}
+
+func TestSame(t *testing.T) {
+	data := &Data{Name: "Seattle", VCP: "R31", Mode: "Clear Air", UpdatedAt: time.Now()}
+	later := *data
+	later.UpdatedAt = later.UpdatedAt.Add(time.Minute)
+	changed := later
+	changed.VCP = "R12"
+
+	if !Same(data, &later) {
+		t.Error("Same() = false for data that differs only in UpdatedAt")
+	}
+	if Same(data, &changed) {
+		t.Error("Same() = true for a changed VCP")
+	}
+	if Same(data, nil) || !Same(nil, nil) {
+		t.Error("Same() should only match nil with nil")
+	}
+}
@@ -0,0 +1,114 @@
+package radar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Incident is a period during which a radar's status or operability is
+// away from normal, from the first abnormal value to the return to normal.
+type Incident struct {
+	Started time.Time `json:"started"`
+	// Ended is when both fields were back to normal; zero while the
+	// incident is open.
+	Ended time.Time `json:"ended,omitzero"`
+	// States lists each abnormal state the radar went through, in order,
+	// starting with the one that opened the incident.
+	States []IncidentState `json:"states"`
+}
+
+// IncidentState is a radar's status and operability during an incident.
+type IncidentState struct {
+	Time              time.Time `json:"time"`
+	Status            string    `json:"status"`
+	OperabilityStatus string    `json:"operability_status"`
+	Severity          Severity  `json:"severity"`
+}
+
+// Normal reports whether d's status and operability are both at
+// SeverityInfo. A field left empty counts as normal.
+func Normal(d *Data) bool {
+	return incidentSeverity(d) == SeverityInfo
+}
+
+// incidentSeverity is the higher severity of d's status and operability.
+func incidentSeverity(d *Data) Severity {
+	sev := SeverityInfo
+	if d.Status != "" {
+		sev = statusSeverity(d.Status)
+	}
+	if d.OperabilityStatus != "" {
+		if s := operabilitySeverity(d.OperabilityStatus); severityRank[s] > severityRank[sev] {
+			sev = s
+		}
+	}
+	return sev
+}
+
+// NextIncident returns the incident that follows from open, the station's
+// open incident (nil if none), once d is reported at now:
+//
+//   - abnormal data opens an incident, or adds its state to the open one
+//     when it differs from the last;
+//   - normal data ends the open incident, which is returned as ended.
+//
+// The result is nil when d is normal and no incident was open. open is not
+// modified.
+func NextIncident(open *Incident, d *Data, now time.Time) *Incident {
+	if Normal(d) {
+		if open == nil {
+			return nil
+		}
+		ended := open.Clone()
+		ended.Ended = now
+		return ended
+	}
+	state := IncidentState{Time: now, Status: d.Status, OperabilityStatus: d.OperabilityStatus, Severity: incidentSeverity(d)}
+	if open == nil {
+		return &Incident{Started: now, States: []IncidentState{state}}
+	}
+	next := open.Clone()
+	if last := next.States[len(next.States)-1]; last.Status != state.Status || last.OperabilityStatus != state.OperabilityStatus {
+		next.States = append(next.States, state)
+	}
+	return next
+}
+
+// Open reports whether the incident has not ended.
+func (i *Incident) Open() bool {
+	return i.Ended.IsZero()
+}
+
+// Severity is the highest severity among the incident's states.
+func (i *Incident) Severity() Severity {
+	sev := SeverityInfo
+	for _, s := range i.States {
+		if severityRank[s.Severity] > severityRank[sev] {
+			sev = s.Severity
+		}
+	}
+	return sev
+}
+
+// Text describes an ended incident for a recovery notification: how long
+// it lasted and the states the radar went through.
+func (i *Incident) Text() string {
+	states := make([]string, len(i.States))
+	for n, s := range i.States {
+		states[n] = strings.Join(slices.DeleteFunc([]string{s.Status, s.OperabilityStatus}, func(v string) bool { return v == "" }), " / ")
+	}
+	return fmt.Sprintf("Back to normal after %s: %s", roundDuration(i.Ended.Sub(i.Started)), strings.Join(states, " → "))
+}
+
+// Clone returns a copy of the incident that shares nothing with it, or nil
+// for a nil incident.
+func (i *Incident) Clone() *Incident {
+	if i == nil {
+		return nil
+	}
+	c := *i
+	c.States = slices.Clone(i.States)
+	return &c
+}
@@ -0,0 +1,59 @@
+package radar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextIncident(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	normal := &Data{Status: "Operate", OperabilityStatus: "RDA - On-line"}
+	standby := &Data{Status: "Standby", OperabilityStatus: "RDA - On-line"}
+	maintenance := &Data{Status: "Operate", OperabilityStatus: "RDA - Maintenance Required"}
+
+	if got := NextIncident(nil, normal, start); got != nil {
+		t.Fatalf("normal data opened %+v", got)
+	}
+
+	open := NextIncident(nil, standby, start)
+	if open == nil || !open.Open() || !open.Started.Equal(start) || len(open.States) != 1 || open.States[0].Severity != SeverityCritical {
+		t.Fatalf("opened incident = %+v", open)
+	}
+	same := NextIncident(open, standby, start.Add(time.Minute))
+	if len(same.States) != 1 {
+		t.Errorf("an unchanged state was added: %+v", same.States)
+	}
+	next := NextIncident(open, maintenance, start.Add(30*time.Minute))
+	if len(next.States) != 2 || len(open.States) != 1 {
+		t.Fatalf("states = %+v (open: %+v), want a second state on a copy", next.States, open.States)
+	}
+	if next.Severity() != SeverityCritical {
+		t.Errorf("Severity() = %s, want the highest state's", next.Severity())
+	}
+
+	ended := NextIncident(next, normal, start.Add(2*time.Hour+15*time.Minute))
+	if ended.Open() || !next.Open() {
+		t.Fatalf("ended = %+v, open copy = %+v", ended, next)
+	}
+	want := "Back to normal after 2h15m0s: Standby / RDA - On-line → Operate / RDA - Maintenance Required"
+	if got := ended.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
+
+func TestNormal(t *testing.T) {
+	for _, tt := range []struct {
+		data *Data
+		want bool
+	}{
+		{&Data{Status: "Operate", OperabilityStatus: "RDA - On-line"}, true},
+		{&Data{}, true},
+		{&Data{Status: "Operate", PowerSource: "Generator"}, true},
+		{&Data{Status: "Standby"}, false},
+		{&Data{Status: "Operate", OperabilityStatus: "RDA - Inoperable"}, false},
+	} {
+		if got := Normal(tt.data); got != tt.want {
+			t.Errorf("Normal(%+v) = %t, want %t", *tt.data, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package radar
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -17,7 +18,7 @@ func TestRealNWSIntegration(t *testing.T) {
 		t.Skip("Skipping integration tests in short mode")
 	}
 
-	service := New()
+	service := New(Config{})
 
 	t.Run("fetch real radar data", func(t *testing.T) {
 		// Test with known radar stations
@@ -25,7 +26,7 @@ func TestRealNWSIntegration(t *testing.T) {
 
 		for _, stationID := range testStations {
 			t.Run("station_"+stationID, func(t *testing.T) {
-				data, err := service.FetchData(stationID)
+				data, err := service.FetchData(context.Background(), stationID)
 				if err != nil {
 					t.Errorf("Failed to fetch data for %s: %v", stationID, err)
 					return
@@ -67,7 +68,7 @@ func TestRealNWSIntegration(t *testing.T) {
 
 	t.Run("test error handling with invalid station", func(t *testing.T) {
 		invalidStation := "INVALID"
-		_, err := service.FetchData(invalidStation)
+		_, err := service.FetchData(context.Background(), invalidStation)
 		if err == nil {
 			t.Errorf("Expected error for invalid station %s, got nil", invalidStation)
 		}
@@ -79,7 +80,7 @@ func TestRealNWSIntegration(t *testing.T) {
 		start := time.Now()
 
 		for _, stationID := range stations {
-			_, err := service.FetchData(stationID)
+			_, err := service.FetchData(context.Background(), stationID)
 			if err != nil {
 				t.Logf("Warning: Failed to fetch %s: %v", stationID, err)
 			}
@@ -102,12 +103,12 @@ func TestRealDataComparison(t *testing.T) {
 		t.Skip("Skipping integration tests in short mode")
 	}
 
-	service := New()
+	service := New(Config{})
 	stationID := "KATX" // Seattle radar
 
 	t.Run("compare real data changes over time", func(t *testing.T) {
 		// Fetch initial data
-		initialData, err := service.FetchData(stationID)
+		initialData, err := service.FetchData(context.Background(), stationID)
 		if err != nil {
 			t.Skipf("Skipping comparison test due to fetch error: %v", err)
 		}
@@ -116,7 +117,7 @@ func TestRealDataComparison(t *testing.T) {
 		time.Sleep(2 * time.Second)
 
 		// Fetch data again
-		laterData, err := service.FetchData(stationID)
+		laterData, err := service.FetchData(context.Background(), stationID)
 		if err != nil {
 			t.Errorf("Failed to fetch later data: %v", err)
 			return
@@ -183,10 +184,10 @@ func TestNWSConnectivity(t *testing.T) {
 		t.Skip("Skipping connectivity test in short mode")
 	}
 
-	service := New()
+	service := New(Config{})
 
 	// Quick connectivity test
-	_, err := service.FetchData("KATX")
+	_, err := service.FetchData(context.Background(), "KATX")
 	if err != nil {
 		t.Logf("NWS API connectivity issue: %v", err)
 		t.Skip("Skipping further integration tests due to connectivity issues")
@@ -1,12 +1,14 @@
 package radar
 
 import (
+	"context"
 	"errors"
 )
 
-// DataFetcher interface for abstracting radar data fetching
+// DataFetcher interface for abstracting radar data fetching. Cancelling ctx
+// aborts the fetch.
 type DataFetcher interface {
-	FetchData(stationID string) (*Data, error)
+	FetchData(ctx context.Context, stationID string) (*Data, error)
 }
 
 // MockDataFetcher provides a mock implementation for testing
@@ -34,8 +36,13 @@ func (m *MockDataFetcher) SetError(stationID string, err error) {
 	m.errors[stationID] = err
 }
 
+// ClearError removes the mock error for a station ID
+func (m *MockDataFetcher) ClearError(stationID string) {
+	delete(m.errors, stationID)
+}
+
 // FetchData returns the mock response or error
-func (m *MockDataFetcher) FetchData(stationID string) (*Data, error) {
+func (m *MockDataFetcher) FetchData(ctx context.Context, stationID string) (*Data, error) {
 	m.callCount++
 
 	if err, exists := m.errors[stationID]; exists {
@@ -1,6 +1,7 @@
 package radar
 
 import (
+	"context"
 	"testing"
 )
 
@@ -8,7 +9,7 @@ func TestMockDataFetcher(t *testing.T) {
 	mock := NewMockDataFetcher()
 
 	t.Run("default response", func(t *testing.T) {
-		data, err := mock.FetchData("KATX")
+		data, err := mock.FetchData(context.Background(), "KATX")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -36,7 +37,7 @@ func TestMockDataFetcher(t *testing.T) {
 		}
 		mock.SetResponse("KRAX", customData)
 
-		data, err := mock.FetchData("KRAX")
+		data, err := mock.FetchData(context.Background(), "KRAX")
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
@@ -52,7 +53,7 @@ func TestMockDataFetcher(t *testing.T) {
 		testError := SimulateError("API connection failed")
 		mock.SetError("KBGM", testError)
 
-		data, err := mock.FetchData("KBGM")
+		data, err := mock.FetchData(context.Background(), "KBGM")
 		if err == nil {
 			t.Error("Expected error, got nil")
 		}
@@ -70,8 +71,8 @@ func TestMockDataFetcher(t *testing.T) {
 			t.Errorf("Expected call count to be 0 after reset, got %d", mock.GetCallCount())
 		}
 
-		_, _ = mock.FetchData("KATX")
-		_, _ = mock.FetchData("KRAX")
+		_, _ = mock.FetchData(context.Background(), "KATX")
+		_, _ = mock.FetchData(context.Background(), "KRAX")
 
 		if mock.GetCallCount() != 2 {
 			t.Errorf("Expected call count to be 2, got %d", mock.GetCallCount())
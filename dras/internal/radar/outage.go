@@ -0,0 +1,56 @@
+package radar
+
+import (
+	"fmt"
+	"time"
+)
+
+// OutageReason says why a station is considered not to be reporting.
+type OutageReason string
+
+const (
+	// OutageUnreachable means the station's data could not be fetched for
+	// several polls in a row.
+	OutageUnreachable OutageReason = "unreachable"
+	// OutageStale means the data was fetched but the NWS has not received
+	// anything new from the radar for too long.
+	OutageStale OutageReason = "stale"
+)
+
+// Outage is a period during which a station is not reporting.
+type Outage struct {
+	Reason OutageReason `json:"reason"`
+	// Since is when the outage began: the first failed fetch, or when the
+	// NWS last received the radar's status.
+	Since time.Time `json:"since"`
+	// Failures counts the failed fetches in a row and LastError is the most
+	// recent one's error (unreachable only).
+	Failures  int    `json:"failures,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// Ended is when the station reported again; zero while the outage
+	// lasts.
+	Ended time.Time `json:"ended,omitzero"`
+}
+
+// Text describes the outage for a notification: that it is ongoing, or
+// once Ended is set, that it is over.
+func (o Outage) Text() string {
+	if !o.Ended.IsZero() {
+		return fmt.Sprintf("Radar is reporting again (%s for %s)", o.Reason, roundDuration(o.Ended.Sub(o.Since)))
+	}
+	switch o.Reason {
+	case OutageStale:
+		return fmt.Sprintf("Radar data has not been updated since %s", o.Since.UTC().Format("2006-01-02 15:04 MST"))
+	default:
+		return fmt.Sprintf("Radar data could not be fetched %d times in a row since %s: %s", o.Failures, o.Since.UTC().Format("2006-01-02 15:04 MST"), o.LastError)
+	}
+}
+
+// roundDuration rounds d to the minute, or to the second below one minute,
+// for display.
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Minute)
+}
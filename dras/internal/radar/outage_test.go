@@ -0,0 +1,26 @@
+package radar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutageText(t *testing.T) {
+	since := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name   string
+		outage Outage
+		want   string
+	}{
+		{"unreachable", Outage{Reason: OutageUnreachable, Since: since, Failures: 3, LastError: "timeout"}, "Radar data could not be fetched 3 times in a row since 2026-10-16 12:00 UTC: timeout"},
+		{"stale", Outage{Reason: OutageStale, Since: since}, "Radar data has not been updated since 2026-10-16 12:00 UTC"},
+		{"ended", Outage{Reason: OutageStale, Since: since, Ended: since.Add(90*time.Minute + 20*time.Second)}, "Radar is reporting again (stale for 1h30m0s)"},
+		{"ended quickly", Outage{Reason: OutageUnreachable, Since: since, Ended: since.Add(42 * time.Second)}, "Radar is reporting again (unreachable for 42s)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.outage.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
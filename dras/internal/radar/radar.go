@@ -1,15 +1,40 @@
 package radar
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/jacaudi/nws/cmd/nws"
+	"github.com/jacaudi/dras/internal/httpretry"
 )
 
+// DefaultBaseURL is the root of the NWS API that station data is fetched
+// from.
+const DefaultBaseURL = "https://api.weather.gov"
+
+// defaultUserAgent identifies DRAS when callers do not supply a User-Agent;
+// the NWS API rejects requests without one.
+const defaultUserAgent = "dras (+https://github.com/jacaudi/dras)"
+
+// defaultTimeout is the HTTP timeout used when callers do not supply a custom
+// client.
+const defaultTimeout = 30 * time.Second
+
+// maxResponseSize caps how much of an NWS API response is read. A station
+// document is a few kilobytes.
+const maxResponseSize = 1 << 20
+
 // ErrUnknownVCP is returned by GetMode/GetVCPInfo when the VCP code is empty
 // or not recognized. Callers can use errors.Is to detect this case and treat
 // it as a soft condition (use the returned fallback label, log a warning,
@@ -38,32 +63,151 @@ var vcpCatalog = map[string]VCPInfo{
 
 // Data represents the data for a radar.
 type Data struct {
-	Name              string // Name of the radar.
-	VCP               string // Volume Coverage Pattern of the radar.
-	Mode              string // Scanning mode of the radar.
-	Status            string // Status of the radar.
-	OperabilityStatus string // Operability Status of the radar.
-	PowerSource       string // Power source of the radar.
-	GenState          string // General state of the radar.
+	Name              string `json:"name"`               // Name of the radar.
+	VCP               string `json:"vcp"`                // Volume Coverage Pattern of the radar.
+	Mode              string `json:"mode"`               // Scanning mode of the radar.
+	Status            string `json:"status"`             // Status of the radar.
+	OperabilityStatus string `json:"operability_status"` // Operability Status of the radar.
+	PowerSource       string `json:"power_source"`       // Power source of the radar.
+	GenState          string `json:"gen_state"`          // General state of the radar.
+	// UpdatedAt is when the NWS last received the radar's status, zero when
+	// the API did not say. It is not compared between polls.
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// Config configures a radar Service.
+type Config struct {
+	// BaseURL is the root of the NWS API; station data is fetched from
+	// {BaseURL}/radar/stations/{id}. Empty defaults to DefaultBaseURL.
+	BaseURL string
+	// UserAgent is sent on every request. Empty sends a generic DRAS one.
+	UserAgent string
+	// HTTPClient is the client used for API requests. nil installs a
+	// client that retries transient failures with a per-attempt timeout.
+	HTTPClient *http.Client
+	// OnCache, if set, is called after every successful FetchData with how
+	// the station cache was used, e.g. to count hits and misses.
+	OnCache func(result CacheResult)
 }
 
 // Service handles radar data operations.
 type Service struct {
-	// Add HTTP client interface here if needed for testing
+	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	onCache    func(CacheResult)
+	now        func() time.Time
+	// freshness caps how long a cached response is served; see
+	// SetMaxFreshness.
+	freshness atomic.Int64
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+// New creates a new radar service from the supplied config. Empty / zero
+// fields fall back to defaults.
+func New(cfg Config) *Service {
+	// As for the image client, the timeout is applied per attempt rather
+	// than as http.Client.Timeout so that one slow attempt cannot use up
+	// the budget of the retries; see httpretry.Transport.
+	client := cfg.HTTPClient
+	if client == nil {
+		rt := httpretry.DefaultTransport()
+		rt.PerAttemptTimeout = defaultTimeout
+		client = &http.Client{Transport: rt}
+	}
+	s := &Service{
+		httpClient: client,
+		baseURL:    strings.TrimRight(cmp.Or(cfg.BaseURL, DefaultBaseURL), "/"),
+		userAgent:  cmp.Or(cfg.UserAgent, defaultUserAgent),
+		onCache:    cfg.OnCache,
+		now:        time.Now,
+		cache:      make(map[string]*cacheEntry),
+	}
+	s.freshness.Store(int64(maxFreshness))
+	return s
 }
 
-// New creates a new radar service.
-func New() *Service {
-	return &Service{}
+// SetMaxFreshness caps how long a response is served from the cache at d,
+// or at most 5 minutes. d <= 0 restores the 5 minute cap. It applies to
+// responses cached from then on.
+func (s *Service) SetMaxFreshness(d time.Duration) {
+	if d <= 0 || d > maxFreshness {
+		d = maxFreshness
+	}
+	s.freshness.Store(int64(d))
+}
+
+// stationResponse is the part of the NWS API's radar station document
+// (GET /radar/stations/{id}, GeoJSON) that DRAS uses. The features of the
+// station collection have the same shape.
+type stationResponse struct {
+	Properties struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		RDA  struct {
+			Timestamp  string `json:"timestamp"`
+			Properties struct {
+				VolumeCoveragePattern string `json:"volumeCoveragePattern"`
+				GeneratorState        string `json:"generatorState"`
+				Status                string `json:"status"`
+				OperabilityStatus     string `json:"operabilityStatus"`
+			} `json:"properties"`
+		} `json:"rda"`
+		Performance struct {
+			Properties struct {
+				PowerSource string `json:"powerSource"`
+			} `json:"properties"`
+		} `json:"performance"`
+	} `json:"properties"`
+}
+
+// problem is the application/problem+json body of an NWS API error.
+type problem struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
 }
 
 // FetchData retrieves radar data for a given station ID.
-// It returns a pointer to a Data struct and an error if any.
-func (s *Service) FetchData(stationID string) (*Data, error) {
-	radarResponse, err := nws.RadarStation(stationID)
+// It returns a pointer to a Data struct and an error if any. ctx bounds the
+// whole request, including retries.
+//
+// Responses are cached per station as the NWS API's caching headers allow
+// (see cache.go): a fresh entry is returned without a request, and a stale
+// one is revalidated with a conditional request.
+func (s *Service) FetchData(ctx context.Context, stationID string) (*Data, error) {
+	now := s.now()
+	prev := s.cached(stationID)
+	if prev != nil && now.Before(prev.expires) {
+		s.observeCache(stationID, CacheHit)
+		return prev.dataCopy(), nil
+	}
+
+	station, header, err := s.fetchStation(ctx, stationID, prev)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get RADAR data for station %q: %w", stationID, err)
 	}
+	if station == nil {
+		// 304 Not Modified: the document, and so the data derived from it,
+		// is unchanged; there is nothing to decode.
+		s.store(stationID, prev.revalidate(header, now, time.Duration(s.freshness.Load())))
+		s.observeCache(stationID, CacheRevalidated)
+		return prev.dataCopy(), nil
+	}
+
+	radarData, err := stationData(stationID, station)
+	if err != nil {
+		return nil, err
+	}
+	s.store(stationID, newCacheEntry(radarData, header, now, time.Duration(s.freshness.Load())))
+	s.observeCache(stationID, CacheMiss)
+	return radarData, nil
+}
+
+// stationData derives the radar data from the station's NWS document.
+func stationData(stationID string, station *stationResponse) (*Data, error) {
+	radarResponse := station.Properties
 
 	// Fetching radar VCP and determine mode. An empty or unrecognized VCP is
 	// not fatal: GetMode returns a "Unknown (VCP ...)" fallback label along
@@ -95,10 +239,60 @@ func (s *Service) FetchData(stationID string) (*Data, error) {
 		PowerSource:       radarResponse.Performance.Properties.PowerSource,
 		GenState:          genStateStatement,
 	}
+	// A missing or malformed timestamp just leaves the data's age unknown.
+	if ts, err := time.Parse(time.RFC3339, radarResponse.RDA.Timestamp); err == nil {
+		radarData.UpdatedAt = ts
+	}
 
 	return radarData, nil
 }
 
+// fetchStation requests the station's document from the NWS API, along
+// with the response headers. The request is conditional on prev's
+// validators when it has any; a 304 Not Modified answer returns a nil
+// document.
+func (s *Service) fetchStation(ctx context.Context, stationID string, prev *cacheEntry) (*stationResponse, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/radar/stations/"+url.PathEscape(stationID), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/geo+json")
+	req.Header.Set("User-Agent", s.userAgent)
+	if prev != nil {
+		if prev.etag != "" {
+			req.Header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.lastModified)
+		}
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && prev != nil {
+		return nil, resp.Header, nil
+	}
+	body := io.LimitReader(resp.Body, maxResponseSize)
+	if resp.StatusCode != http.StatusOK {
+		var p problem
+		_ = json.NewDecoder(body).Decode(&p)
+		if msg := cmp.Or(p.Detail, p.Title); msg != "" {
+			return nil, nil, fmt.Errorf("NWS API returned status %d: %s", resp.StatusCode, msg)
+		}
+		return nil, nil, fmt.Errorf("NWS API returned status %d", resp.StatusCode)
+	}
+
+	var station stationResponse
+	if err := json.NewDecoder(body).Decode(&station); err != nil {
+		return nil, nil, fmt.Errorf("decode NWS API response: %w", err)
+	}
+	return &station, resp.Header, nil
+}
+
 // GetMode returns the radar mode for a given VCP code, looked up from
 // vcpCatalog.
 //
@@ -1,11 +1,129 @@
 package radar
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
+// stationDocument is a trimmed GET /radar/stations/{id} response.
+const stationDocument = `{
+  "type": "Feature",
+  "properties": {
+    "id": "KATX",
+    "name": "SEATTLE/TACOMA",
+    "stationType": "WSR-88D",
+    "rda": {
+      "timestamp": "2026-10-16T12:00:00+00:00",
+      "properties": {
+        "volumeCoveragePattern": "R35",
+        "generatorState": "Utility PWR Available",
+        "status": "Operate",
+        "operabilityStatus": "RDA - On-line",
+        "mode": "Operational"
+      }
+    },
+    "performance": {
+      "properties": {"powerSource": "Utility", "ntp_status": 1}
+    }
+  }
+}`
+
+func TestServiceFetchData(t *testing.T) {
+	var gotPath, gotUA, gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotUA, gotAccept = r.URL.Path, r.UserAgent(), r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write([]byte(stationDocument))
+	}))
+	defer server.Close()
+
+	s := New(Config{BaseURL: server.URL + "/", UserAgent: "dras-test"})
+	data, err := s.FetchData(context.Background(), "KATX")
+	if err != nil {
+		t.Fatalf("FetchData() error: %v", err)
+	}
+	want := Data{
+		Name:              "SEATTLE/TACOMA",
+		VCP:               "R35",
+		Mode:              "Clear Air",
+		Status:            "Operate",
+		OperabilityStatus: "RDA - On-line",
+		PowerSource:       "Utility",
+		GenState:          "Off",
+		UpdatedAt:         time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	if !data.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want %v", data.UpdatedAt, want.UpdatedAt)
+	}
+	data.UpdatedAt = want.UpdatedAt
+	if *data != want {
+		t.Errorf("FetchData() = %+v, want %+v", *data, want)
+	}
+	if gotPath != "/radar/stations/KATX" {
+		t.Errorf("path = %q, want /radar/stations/KATX", gotPath)
+	}
+	if gotUA != "dras-test" || gotAccept != "application/geo+json" {
+		t.Errorf("User-Agent = %q, Accept = %q", gotUA, gotAccept)
+	}
+}
+
+func TestServiceFetchDataErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/radar/stations/KXXX":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title": "Not Found", "detail": "Radar station KXXX not found"}`))
+		case "/radar/stations/KBAD":
+			w.Write([]byte(`{"properties": `))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	s := New(Config{BaseURL: server.URL, HTTPClient: server.Client()})
+
+	for _, tt := range []struct{ station, want string }{
+		{"KXXX", "status 404: Radar station KXXX not found"},
+		{"KBAD", "decode NWS API response"},
+		{"KFOR", "status 403"},
+	} {
+		_, err := s.FetchData(context.Background(), tt.station)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.station) {
+			t.Errorf("FetchData(%s) error = %v, want it to contain %q", tt.station, err, tt.want)
+		}
+	}
+}
+
+func TestServiceFetchDataHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	s := New(Config{BaseURL: server.URL})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.FetchData(ctx, "KATX")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchData() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("FetchData() took %v after its context expired", elapsed)
+	}
+}
+
 func TestGetMode(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,50 @@
+package radar
+
+import "strings"
+
+// StationSummary is a station's entry in a digest: its current radar state
+// and what was reported for it over the digest's period.
+type StationSummary struct {
+	StationID string `json:"station"`
+	// Data is the station's current radar data; nil until it has been
+	// fetched.
+	Data *Data `json:"data"`
+	// Changes are the changes reported over the period, oldest first.
+	Changes []Change `json:"changes,omitempty"`
+	// Outage is set while the station is not reporting.
+	Outage *Outage `json:"outage,omitempty"`
+}
+
+// Text describes the station on one line — ID, name, VCP, mode and
+// status — followed by a line per change, or one saying there were none,
+// and its outage, if any.
+func (s StationSummary) Text() string {
+	var sb strings.Builder
+	sb.WriteString(s.StationID)
+	if s.Data == nil {
+		sb.WriteString(": no data yet")
+	} else {
+		if s.Data.Name != "" {
+			sb.WriteString(" " + s.Data.Name)
+		}
+		var state []string
+		for _, v := range []string{s.Data.VCP, s.Data.Mode, s.Data.Status} {
+			if v != "" {
+				state = append(state, v)
+			}
+		}
+		if len(state) > 0 {
+			sb.WriteString(": " + strings.Join(state, ", "))
+		}
+	}
+	if s.Outage != nil {
+		sb.WriteString("\n  " + s.Outage.Text())
+	}
+	if len(s.Changes) == 0 {
+		sb.WriteString("\n  No changes")
+	}
+	for _, c := range s.Changes {
+		sb.WriteString("\n  - " + c.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package radar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStationSummaryText(t *testing.T) {
+	since := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		summary StationSummary
+		want    string
+	}{
+		{
+			name:    "no data",
+			summary: StationSummary{StationID: "KATX"},
+			want:    "KATX: no data yet\n  No changes",
+		},
+		{
+			name: "changes",
+			summary: StationSummary{
+				StationID: "KATX",
+				Data:      &Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air", Status: "Operate"},
+				Changes: []Change{
+					{Field: FieldVCP, Text: "Clear Air Mode Active"},
+					{Field: FieldStatus, Text: "Radar status changed from Standby to Operate"},
+				},
+			},
+			want: "KATX Seattle: R35, Clear Air, Operate\n  - Clear Air Mode Active\n  - Radar status changed from Standby to Operate",
+		},
+		{
+			name: "outage",
+			summary: StationSummary{
+				StationID: "KATX",
+				Data:      &Data{VCP: "R35"},
+				Outage:    &Outage{Reason: OutageUnreachable, Since: since, Failures: 3},
+			},
+			want: "KATX: R35\n  " + (Outage{Reason: OutageUnreachable, Since: since, Failures: 3}).Text() + "\n  No changes",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -35,9 +35,15 @@ type Config struct {
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	// pingClient is used by Ping. It does not retry: a readiness probe
+	// wants the renderer's state now, not after a backoff.
+	pingClient *http.Client
 	userAgent  string
 }
 
+// pingTimeout bounds a Ping when the caller's context has no deadline.
+const pingTimeout = 5 * time.Second
+
 // New constructs a Client. Panics on empty BaseURL.
 //
 // When cfg.HTTPClient is nil, the default client wraps http.DefaultTransport
@@ -55,14 +61,17 @@ func New(cfg Config) *Client {
 		panic("renderer.New: BaseURL is required")
 	}
 	hc := cfg.HTTPClient
+	pc := cfg.HTTPClient
 	if hc == nil {
 		rt := httpretry.DefaultTransport()
 		rt.PerAttemptTimeout = cfg.Timeout
 		hc = &http.Client{Transport: rt}
+		pc = &http.Client{Timeout: pingTimeout}
 	}
 	return &Client{
 		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
 		httpClient: hc,
+		pingClient: pc,
 		userAgent:  cfg.UserAgent,
 	}
 }
@@ -132,6 +141,28 @@ func (c *Client) Fetch(ctx context.Context, stationID string) (*image.Image, err
 	}, nil
 }
 
+// Ping checks that the renderer is up by calling its /healthz endpoint,
+// without retrying.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return fmt.Errorf("build renderer health request: %w", err)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.pingClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("renderer health check failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("renderer health check returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // Latest always returns no cached image. The renderer is the source of truth;
 // dras does not cache rendered images locally and does not fall back to a
 // stale render.
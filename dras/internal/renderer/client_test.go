@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -156,3 +157,33 @@ func TestFetchPropagatesContextCancellation(t *testing.T) {
 		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
+
+func TestPing(t *testing.T) {
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/healthz" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL + "/", Timeout: 5 * time.Second})
+	if err := c.Ping(t.Context()); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Ping() error = %v, want a 503 error", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Ping made %d requests, want 1 (no retries)", n)
+	}
+
+	healthy.Store(true)
+	if err := c.Ping(t.Context()); err != nil {
+		t.Errorf("Ping() error = %v, want nil", err)
+	}
+}
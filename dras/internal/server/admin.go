@@ -0,0 +1,125 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/monitor"
+)
+
+// Admin is the part of monitor.Monitor the admin API changes.
+type Admin interface {
+	AddStation(id string) error
+	RemoveStation(id string) error
+	PauseStation(id string) error
+	ResumeStation(id string) error
+	SetStationAlerts(id string, alerts config.AlertOverride) error
+}
+
+// maxAdminBody bounds admin request bodies.
+const maxAdminBody = 64 << 10
+
+// addStationRequest is the body of POST /api/stations.
+type addStationRequest struct {
+	ID string `json:"id"`
+}
+
+// requireToken wraps an admin handler so it only runs for requests that
+// carry the admin bearer token.
+func (s *Server) requireToken(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			slog.Warn("Rejected admin API request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="dras"`)
+			writeError(w, http.StatusUnauthorized, "a valid admin bearer token is required")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleAddStation starts monitoring the station in the request body.
+func (s *Server) handleAddStation(w http.ResponseWriter, r *http.Request) {
+	var req addStationRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if err := s.admin.AddStation(req.ID); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	st, _ := s.monitor.Station(strings.ToUpper(strings.TrimSpace(req.ID)))
+	writeJSON(w, http.StatusCreated, st)
+}
+
+// handleRemoveStation stops monitoring a station.
+func (s *Server) handleRemoveStation(w http.ResponseWriter, r *http.Request) {
+	if err := s.admin.RemoveStation(stationID(r)); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePauseStation pauses polling a station.
+func (s *Server) handlePauseStation(w http.ResponseWriter, r *http.Request) {
+	s.respondStation(w, stationID(r), s.admin.PauseStation(stationID(r)))
+}
+
+// handleResumeStation resumes polling a station.
+func (s *Server) handleResumeStation(w http.ResponseWriter, r *http.Request) {
+	s.respondStation(w, stationID(r), s.admin.ResumeStation(stationID(r)))
+}
+
+// handleSetAlerts changes the alert toggles present in the request body.
+func (s *Server) handleSetAlerts(w http.ResponseWriter, r *http.Request) {
+	var alerts config.AlertOverride
+	if !decodeBody(w, r, &alerts) {
+		return
+	}
+	s.respondStation(w, stationID(r), s.admin.SetStationAlerts(stationID(r), alerts))
+}
+
+// respondStation writes err, or the station's state after a change.
+func (s *Server) respondStation(w http.ResponseWriter, id string, err error) {
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	st, _ := s.monitor.Station(id)
+	writeJSON(w, http.StatusOK, st)
+}
+
+// decodeBody decodes the JSON request body into v, rejecting unknown
+// fields. It writes a 400 and returns false when the body is invalid.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// writeAdminError maps a station management error to its status.
+func writeAdminError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, monitor.ErrInvalidStation):
+		status = http.StatusBadRequest
+	case errors.Is(err, monitor.ErrNotMonitored):
+		status = http.StatusNotFound
+	case errors.Is(err, monitor.ErrAlreadyMonitored):
+		status = http.StatusConflict
+	default:
+		slog.Error("Admin API request failed", "error", err)
+	}
+	writeError(w, status, "%v", err)
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/monitor"
+)
+
+const testToken = "s3cret"
+
+// fakeAdmin records the calls it receives and fails with err when set.
+type fakeAdmin struct {
+	calls  []string
+	alerts config.AlertOverride
+	err    error
+}
+
+func (f *fakeAdmin) record(call string) error {
+	f.calls = append(f.calls, call)
+	return f.err
+}
+
+func (f *fakeAdmin) AddStation(id string) error    { return f.record("add " + id) }
+func (f *fakeAdmin) RemoveStation(id string) error { return f.record("remove " + id) }
+func (f *fakeAdmin) PauseStation(id string) error  { return f.record("pause " + id) }
+func (f *fakeAdmin) ResumeStation(id string) error { return f.record("resume " + id) }
+func (f *fakeAdmin) SetStationAlerts(id string, alerts config.AlertOverride) error {
+	f.alerts = alerts
+	return f.record("alerts " + id)
+}
+
+func adminRequest(s *Server, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAPI(t *testing.T) {
+	admin := &fakeAdmin{}
+	s := New(Config{Monitor: newAPIFixture(), Admin: admin, AdminToken: testToken})
+
+	for _, tt := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/api/stations", `{"id": "KATX"}`, http.StatusCreated},
+		{http.MethodDelete, "/api/stations/krax", "", http.StatusNoContent},
+		{http.MethodPost, "/api/stations/KATX/pause", "", http.StatusOK},
+		{http.MethodPost, "/api/stations/KATX/resume", "", http.StatusOK},
+		{http.MethodPatch, "/api/stations/KATX/alerts", `{"vcp": false}`, http.StatusOK},
+	} {
+		if rec := adminRequest(s, tt.method, tt.path, tt.body, testToken); rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d (%s)", tt.method, tt.path, rec.Code, tt.want, rec.Body)
+		}
+	}
+
+	want := []string{"add KATX", "remove KRAX", "pause KATX", "resume KATX", "alerts KATX"}
+	if fmt.Sprint(admin.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", admin.calls, want)
+	}
+	if admin.alerts.VCP == nil || *admin.alerts.VCP || admin.alerts.Status != nil {
+		t.Errorf("alerts = %+v, want only VCP set to false", admin.alerts)
+	}
+}
+
+func TestAdminAPIRequiresToken(t *testing.T) {
+	admin := &fakeAdmin{}
+	s := New(Config{Monitor: newAPIFixture(), Admin: admin, AdminToken: testToken})
+
+	for _, token := range []string{"", "wrong"} {
+		rec := adminRequest(s, http.MethodPost, "/api/stations/KATX/pause", "", token)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, rec.Code)
+		}
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("token %q: no WWW-Authenticate header", token)
+		}
+	}
+	if len(admin.calls) != 0 {
+		t.Errorf("unauthorized requests reached the monitor: %v", admin.calls)
+	}
+
+	// Reads stay open.
+	if rec := adminRequest(s, http.MethodGet, "/api/stations", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/stations = %d, want 200 without a token", rec.Code)
+	}
+}
+
+func TestAdminAPIDisabledWithoutToken(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture(), Admin: &fakeAdmin{}})
+	if rec := adminRequest(s, http.MethodPost, "/api/stations/KATX/pause", "", ""); rec.Code == http.StatusOK {
+		t.Errorf("POST without an admin token configured = %d, want the route absent", rec.Code)
+	}
+}
+
+func TestAdminAPIErrors(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{fmt.Errorf("%w: %q", monitor.ErrInvalidStation, "X"), http.StatusBadRequest},
+		{fmt.Errorf("%w: KXYZ", monitor.ErrNotMonitored), http.StatusNotFound},
+		{fmt.Errorf("%w: KATX", monitor.ErrAlreadyMonitored), http.StatusConflict},
+		{fmt.Errorf("persist station changes: disk full"), http.StatusInternalServerError},
+	} {
+		s := New(Config{Monitor: newAPIFixture(), Admin: &fakeAdmin{err: tt.err}, AdminToken: testToken})
+		if rec := adminRequest(s, http.MethodPost, "/api/stations", `{"id": "KATX"}`, testToken); rec.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.want)
+		}
+	}
+
+	s := New(Config{Monitor: newAPIFixture(), Admin: &fakeAdmin{}, AdminToken: testToken})
+	for _, body := range []string{"", "{", `{"id": "KATX", "extra": 1}`} {
+		if rec := adminRequest(s, http.MethodPost, "/api/stations", body, testToken); rec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
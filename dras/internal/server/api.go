@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// handleStations lists every monitored station's state.
+func (s *Server) handleStations(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.monitor.Stations())
+}
+
+// handleStation returns one station's state.
+func (s *Server) handleStation(w http.ResponseWriter, r *http.Request) {
+	id := stationID(r)
+	st, ok := s.monitor.Station(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "station %s is not monitored", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// handleHistory returns a station's recent reported changes, newest first.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	id := stationID(r)
+	history, ok := s.monitor.History(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "station %s is not monitored", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// openIncident is a station's open incident in the incidents list.
+type openIncident struct {
+	Station string `json:"station"`
+	*radar.Incident
+}
+
+// handleIncidents lists the open incidents of every monitored station, in
+// configuration order.
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents := []openIncident{}
+	for _, st := range s.monitor.Stations() {
+		if st.Incident != nil {
+			incidents = append(incidents, openIncident{Station: st.ID, Incident: st.Incident})
+		}
+	}
+	writeJSON(w, http.StatusOK, incidents)
+}
+
+// handleReceipts lists the Pushover emergency messages sent, most recent
+// first, with whether each has been acknowledged or has expired.
+func (s *Server) handleReceipts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.receipts())
+}
+
+// handleImage serves a station's latest radar image as-is.
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	id := stationID(r)
+	if _, ok := s.monitor.Station(id); !ok {
+		writeError(w, http.StatusNotFound, "station %s is not monitored", id)
+		return
+	}
+	img, ok := s.monitor.LatestImage(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no radar image available for station %s", id)
+		return
+	}
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	if img.Filename != "" {
+		w.Header().Set("Content-Disposition", `inline; filename="`+img.Filename+`"`)
+	}
+	if !img.FetchedAt.IsZero() {
+		w.Header().Set("Last-Modified", img.FetchedAt.UTC().Format(http.TimeFormat))
+	}
+	_, _ = w.Write(img.Data)
+}
+
+// stationID returns the request's {id} path value as a station ID.
+func stationID(r *http.Request) string {
+	return strings.ToUpper(strings.TrimSpace(r.PathValue("id")))
+}
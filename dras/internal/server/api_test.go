@@ -0,0 +1,237 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/message"
+	"github.com/jacaudi/dras/internal/monitor"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// fakeMonitor serves canned state.
+type fakeMonitor struct {
+	health   monitor.Health
+	stations []monitor.StationState
+	history  map[string][]monitor.HistoryEntry
+	images   map[string]*image.Image
+}
+
+func (f *fakeMonitor) Health() monitor.Health { return f.health }
+
+func (f *fakeMonitor) Stations() []monitor.StationState { return f.stations }
+
+func (f *fakeMonitor) Station(id string) (monitor.StationState, bool) {
+	for _, st := range f.stations {
+		if st.ID == id {
+			return st, true
+		}
+	}
+	return monitor.StationState{}, false
+}
+
+func (f *fakeMonitor) History(id string) ([]monitor.HistoryEntry, bool) {
+	if _, ok := f.Station(id); !ok {
+		return nil, false
+	}
+	return f.history[id], true
+}
+
+func (f *fakeMonitor) LatestImage(id string) (*image.Image, bool) {
+	img, ok := f.images[id]
+	return img, ok
+}
+
+func newAPIFixture() *fakeMonitor {
+	polled := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	precip := &radar.Data{Name: "Seattle", VCP: "R215", Mode: "Precipitation", Status: "Operate"}
+	return &fakeMonitor{
+		stations: []monitor.StationState{
+			{
+				StationStatus: monitor.StationStatus{ID: "KATX", LastPoll: polled, LastSuccess: polled},
+				Data:          precip,
+				LastChange:    polled,
+			},
+			{
+				StationStatus: monitor.StationStatus{ID: "KRAX", LastPoll: polled, LastError: "NWS unavailable", ConsecutiveFailures: 1},
+			},
+			{
+				StationStatus: monitor.StationStatus{ID: "KLOT", LastPoll: polled, LastSuccess: polled},
+				Data:          &radar.Data{Name: "Chicago", VCP: "R31", Status: "Standby"},
+				Incident: &radar.Incident{
+					Started: polled.Add(-time.Hour),
+					States:  []radar.IncidentState{{Time: polled.Add(-time.Hour), Status: "Standby", Severity: radar.SeverityCritical}},
+				},
+			},
+		},
+		history: map[string][]monitor.HistoryEntry{
+			"KATX": {{
+				Time:    polled,
+				Kind:    message.Change,
+				Old:     &radar.Data{Name: "Seattle", VCP: "R35", Mode: "Clear Air"},
+				New:     precip,
+				Changes: []radar.Change{{Field: radar.FieldVCP, Old: "R35", New: "R215", Severity: radar.SeverityInfo}},
+			}},
+		},
+		images: map[string]*image.Image{
+			"KATX": {StationID: "KATX", Data: []byte("GIF89a"), ContentType: "image/gif", Filename: "KATX.gif", FetchedAt: polled},
+		},
+	}
+}
+
+func TestStationsAPI(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stations", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/stations = %d", rec.Code)
+	}
+	var stations []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &stations); err != nil {
+		t.Fatal(err)
+	}
+	if len(stations) != 3 {
+		t.Fatalf("stations = %v, want 3", stations)
+	}
+	if data, _ := stations[0]["data"].(map[string]any); data["vcp"] != "R215" || stations[0]["last_poll"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("KATX = %v, want its data and last poll", stations[0])
+	}
+	if stations[1]["last_error"] != "NWS unavailable" || stations[1]["data"] != nil {
+		t.Errorf("KRAX = %v, want its error and no data", stations[1])
+	}
+}
+
+func TestStationAPI(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{"/api/stations/katx", http.StatusOK},
+		{"/api/stations/KXYZ", http.StatusNotFound},
+		{"/api/stations/KATX/history", http.StatusOK},
+		{"/api/stations/KXYZ/history", http.StatusNotFound},
+		{"/api/stations/KATX/image", http.StatusOK},
+		{"/api/stations/KRAX/image", http.StatusNotFound},
+		{"/api/stations/KXYZ/image", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d (%s)", tt.path, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
+
+func TestHistoryAPI(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stations/KATX/history", nil))
+
+	var history []monitor.HistoryEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Kind != message.Change || history[0].Changes[0].New != "R215" {
+		t.Errorf("history = %+v, want the VCP change", history)
+	}
+}
+
+func TestIncidentsAPI(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/incidents", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/incidents = %d", rec.Code)
+	}
+
+	var incidents []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &incidents); err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 1 || incidents[0]["station"] != "KLOT" || incidents[0]["started"] != "2024-05-01T11:00:00Z" {
+		t.Fatalf("incidents = %v, want KLOT's", incidents)
+	}
+	if states, _ := incidents[0]["states"].([]any); len(states) != 1 {
+		t.Errorf("states = %v, want one", incidents[0]["states"])
+	}
+}
+
+func TestReceiptsAPI(t *testing.T) {
+	sent := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s := New(Config{Monitor: newAPIFixture(), Receipts: func() []notify.ReceiptStatus {
+		return []notify.ReceiptStatus{{Receipt: "r1", Title: "KATX Update", SentAt: sent, Acknowledged: true, AcknowledgedBy: "u1", AcknowledgedAt: sent.Add(time.Minute)}}
+	}})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/receipts", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/receipts = %d", rec.Code)
+	}
+
+	var receipts []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &receipts); err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 1 || receipts[0]["receipt"] != "r1" || receipts[0]["acknowledged"] != true || receipts[0]["acknowledged_at"] != "2024-05-01T12:01:00Z" {
+		t.Errorf("receipts = %v, want r1 acknowledged", receipts)
+	}
+	if _, ok := receipts[0]["expires_at"]; ok {
+		t.Errorf("receipt = %v, want no expiry time before it is known", receipts[0])
+	}
+
+	rec = httptest.NewRecorder()
+	New(Config{Monitor: newAPIFixture()}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/receipts", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/receipts without Receipts = %d, want 404", rec.Code)
+	}
+}
+
+func TestImageAPI(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stations/KATX/image", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "image/gif" {
+		t.Errorf("Content-Type = %q, want image/gif", got)
+	}
+	if got := rec.Header().Get("Last-Modified"); got != "Wed, 01 May 2024 12:00:00 GMT" {
+		t.Errorf("Last-Modified = %q", got)
+	}
+	if rec.Body.String() != "GIF89a" {
+		t.Errorf("body = %q, want the image bytes", rec.Body)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture()})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET / = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	// The page reads the API with relative URLs so it works behind a
+	// path prefix.
+	for _, want := range []string{`fetch("api/stations"`, `new EventSource("api/events")`} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("dashboard does not contain %s", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /nope = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardHTML is a self-contained page that renders /api/stations and
+// refreshes on /api/events.
+//
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// handleDashboard serves the dashboard.
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(dashboardHTML)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jacaudi/dras/internal/monitor"
+)
+
+// checkResult is one named check in a probe response.
+type checkResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// probeResponse is the body of /healthz and /readyz.
+type probeResponse struct {
+	Status   string                  `json:"status"`
+	Checks   []checkResult           `json:"checks"`
+	Stations []monitor.StationStatus `json:"stations"`
+}
+
+// handleHealthz reports whether the process is alive: the poll loop has
+// ticked within twice the poll interval. It returns 503 otherwise so the
+// orchestrator gets restarted.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	h := s.monitor.Health()
+	now := s.now()
+
+	loop := checkResult{Name: "poll_loop", OK: h.Alive(now), Detail: "poll loop not started"}
+	if h.Started {
+		loop.Detail = fmt.Sprintf("last tick %s ago, interval %s", now.Sub(h.LastTick).Round(time.Second), h.PollInterval)
+	}
+
+	writeProbe(w, "ok", "unhealthy", h, loop)
+}
+
+// handleReadyz reports whether the orchestrator is doing useful work: the
+// initial fetch has completed, a notifier is configured (or notifications
+// are off in dry-run mode) and every extra readiness check passes.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	h := s.monitor.Health()
+
+	results := []checkResult{{Name: "initial_fetch", OK: h.InitialFetchDone}}
+	if !h.InitialFetchDone {
+		results[0].Detail = "initial fetch in progress"
+	}
+
+	notifier := checkResult{Name: "notifier", OK: h.DryRun || h.NotifierConfigured}
+	switch {
+	case h.DryRun:
+		notifier.Detail = "dry run, notifications disabled"
+	case !h.NotifierConfigured:
+		notifier.Detail = "no notifier configured"
+	}
+	results = append(results, notifier)
+
+	for _, c := range s.checks {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		err := c.Run(ctx)
+		cancel()
+		res := checkResult{Name: c.Name, OK: err == nil}
+		if err != nil {
+			res.Detail = err.Error()
+		}
+		results = append(results, res)
+	}
+
+	writeProbe(w, "ready", "not_ready", h, results...)
+}
+
+// writeProbe writes a probe response: 200 with status ok when every check
+// passed, 503 with status failed otherwise.
+func writeProbe(w http.ResponseWriter, ok, failed string, h monitor.Health, checks ...checkResult) {
+	resp := probeResponse{Status: ok, Checks: checks, Stations: h.Stations}
+	code := http.StatusOK
+	for _, c := range checks {
+		if !c.OK {
+			resp.Status = failed
+			code = http.StatusServiceUnavailable
+			break
+		}
+	}
+	writeJSON(w, code, resp)
+}
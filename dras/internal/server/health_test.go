@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/monitor"
+)
+
+func get(t *testing.T, h http.Handler, path string) (int, probeResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body probeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET %s: decode body %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthz(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stations := []monitor.StationStatus{
+		{ID: "KATX", LastPoll: now.Add(-time.Minute), LastSuccess: now.Add(-time.Minute)},
+		{ID: "KRAX", LastPoll: now.Add(-time.Minute), LastError: "NWS unavailable", ConsecutiveFailures: 3},
+	}
+
+	for _, tt := range []struct {
+		name   string
+		health monitor.Health
+		want   int
+	}{
+		{"not started", monitor.Health{PollInterval: 5 * time.Minute}, http.StatusServiceUnavailable},
+		{"ticking", monitor.Health{Started: true, LastTick: now.Add(-6 * time.Minute), PollInterval: 5 * time.Minute, Stations: stations}, http.StatusOK},
+		{"stalled", monitor.Health{Started: true, LastTick: now.Add(-11 * time.Minute), PollInterval: 5 * time.Minute, Stations: stations}, http.StatusServiceUnavailable},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New(Config{Monitor: &fakeMonitor{health: tt.health}})
+			s.now = func() time.Time { return now }
+
+			code, body := get(t, s, "/healthz")
+			if code != tt.want {
+				t.Errorf("status = %d, want %d (body %+v)", code, tt.want, body)
+			}
+			if len(body.Stations) != len(tt.health.Stations) {
+				t.Errorf("stations = %+v, want %d", body.Stations, len(tt.health.Stations))
+			}
+		})
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	ready := monitor.Health{Started: true, InitialFetchDone: true, NotifierConfigured: true, Stations: []monitor.StationStatus{{ID: "KATX"}}}
+	rendererDown := Check{Name: "renderer", Run: func(context.Context) error { return errors.New("connection refused") }}
+	rendererUp := Check{Name: "renderer", Run: func(context.Context) error { return nil }}
+
+	for _, tt := range []struct {
+		name    string
+		health  func(h *monitor.Health)
+		checks  []Check
+		want    int
+		failing string
+	}{
+		{name: "ready", checks: []Check{rendererUp}, want: http.StatusOK},
+		{name: "initial fetch running", health: func(h *monitor.Health) { h.InitialFetchDone = false }, want: http.StatusServiceUnavailable, failing: "initial_fetch"},
+		{name: "no notifier", health: func(h *monitor.Health) { h.NotifierConfigured = false }, want: http.StatusServiceUnavailable, failing: "notifier"},
+		{name: "dry run needs no notifier", health: func(h *monitor.Health) { h.NotifierConfigured, h.DryRun = false, true }, want: http.StatusOK},
+		{name: "renderer down", checks: []Check{rendererDown}, want: http.StatusServiceUnavailable, failing: "renderer"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := ready
+			if tt.health != nil {
+				tt.health(&h)
+			}
+			s := New(Config{Monitor: &fakeMonitor{health: h}, ReadinessChecks: tt.checks})
+
+			code, body := get(t, s, "/readyz")
+			if code != tt.want {
+				t.Errorf("status = %d, want %d (body %+v)", code, tt.want, body)
+			}
+			for _, c := range body.Checks {
+				if c.OK == (c.Name == tt.failing) {
+					t.Errorf("check %+v, want only %q failing", c, tt.failing)
+				}
+			}
+			if body.Stations[0].ID != "KATX" {
+				t.Errorf("stations = %+v, want KATX", body.Stations)
+			}
+		})
+	}
+}
+
+func TestMetricsRouteOnlyWithMetrics(t *testing.T) {
+	s := New(Config{Monitor: &fakeMonitor{}})
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics without metrics = %d, want 404", rec.Code)
+	}
+}
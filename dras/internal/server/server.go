@@ -0,0 +1,147 @@
+// Package server is the orchestrator's HTTP interface: Prometheus metrics,
+// the liveness and readiness probes backed by the monitor's polling state,
+// a read-only JSON API of station state and change history, a
+// Server-Sent Events stream of live station events, a web dashboard built
+// on the two, and an admin API, guarded by a bearer token, that manages the
+// monitored stations at runtime.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/jacaudi/dras/internal/events"
+	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/metrics"
+	"github.com/jacaudi/dras/internal/monitor"
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+// Monitor is the part of monitor.Monitor the server reads from.
+type Monitor interface {
+	Health() monitor.Health
+	Stations() []monitor.StationState
+	Station(id string) (monitor.StationState, bool)
+	History(id string) ([]monitor.HistoryEntry, bool)
+	LatestImage(id string) (*image.Image, bool)
+}
+
+// Check is an extra readiness check, e.g. that the renderer is reachable.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Config configures a Server.
+type Config struct {
+	// Monitor supplies the polling state. Required.
+	Monitor Monitor
+	// Metrics is served on /metrics; nil leaves the route out.
+	Metrics *metrics.Metrics
+	// Events is streamed on /api/events; nil leaves the route out.
+	Events *events.Broker
+	// Receipts lists Pushover emergency receipts on /api/receipts, e.g.
+	// notify.Receipts; nil leaves the route out.
+	Receipts func() []notify.ReceiptStatus
+	// ReadinessChecks run on every /readyz request, in addition to the
+	// monitor's own state.
+	ReadinessChecks []Check
+	// Admin manages the monitored stations, and AdminToken is the bearer
+	// token its routes require. The admin routes are left out unless both
+	// are set.
+	Admin      Admin
+	AdminToken string
+}
+
+// checkTimeout bounds each readiness check.
+const checkTimeout = 3 * time.Second
+
+// Server serves the orchestrator's HTTP endpoints.
+type Server struct {
+	monitor Monitor
+	checks  []Check
+	events  *events.Broker
+	// receipts lists Pushover emergency receipts; see Config.Receipts.
+	receipts func() []notify.ReceiptStatus
+	admin    Admin
+	// adminToken is the bearer token the admin routes require.
+	adminToken string
+	mux        *http.ServeMux
+	// now is the clock and keepAlive the event stream's keep-alive
+	// interval; tests replace them.
+	now       func() time.Time
+	keepAlive time.Duration
+}
+
+// New returns a Server for cfg. It panics if cfg.Monitor is nil.
+func New(cfg Config) *Server {
+	if cfg.Monitor == nil {
+		panic("server.New: Monitor is required")
+	}
+	s := &Server{
+		monitor:    cfg.Monitor,
+		checks:     cfg.ReadinessChecks,
+		events:     cfg.Events,
+		receipts:   cfg.Receipts,
+		admin:      cfg.Admin,
+		adminToken: cfg.AdminToken,
+		mux:        http.NewServeMux(),
+		now:        time.Now,
+		keepAlive:  keepAliveInterval,
+	}
+	s.mux.HandleFunc("GET /{$}", s.handleDashboard)
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.HandleFunc("GET /api/stations", s.handleStations)
+	s.mux.HandleFunc("GET /api/stations/{id}", s.handleStation)
+	s.mux.HandleFunc("GET /api/stations/{id}/history", s.handleHistory)
+	s.mux.HandleFunc("GET /api/stations/{id}/image", s.handleImage)
+	s.mux.HandleFunc("GET /api/incidents", s.handleIncidents)
+	if cfg.Events != nil {
+		s.mux.HandleFunc("GET /api/events", s.handleEvents)
+	}
+	if cfg.Receipts != nil {
+		s.mux.HandleFunc("GET /api/receipts", s.handleReceipts)
+	}
+	if cfg.Admin != nil && cfg.AdminToken != "" {
+		s.mux.HandleFunc("POST /api/stations", s.requireToken(s.handleAddStation))
+		s.mux.HandleFunc("DELETE /api/stations/{id}", s.requireToken(s.handleRemoveStation))
+		s.mux.HandleFunc("POST /api/stations/{id}/pause", s.requireToken(s.handlePauseStation))
+		s.mux.HandleFunc("POST /api/stations/{id}/resume", s.requireToken(s.handleResumeStation))
+		s.mux.HandleFunc("PATCH /api/stations/{id}/alerts", s.requireToken(s.handleSetAlerts))
+	}
+	if cfg.Metrics != nil {
+		s.mux.Handle("GET /metrics", cfg.Metrics.Handler())
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// errorResponse is the body of an API error.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError writes an API error with the given status.
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, errorResponse{Error: fmt.Sprintf(format, args...)})
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Debug("Failed to write HTTP response", "error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jacaudi/dras/internal/events"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment, so
+// proxies and load balancers don't close it.
+const keepAliveInterval = 30 * time.Second
+
+// handleEvents streams station events as Server-Sent Events. Query
+// parameters: station (repeatable or comma-separated) limits the stream to
+// those stations, and replay=N first sends the last N matching events. A
+// reconnecting client's Last-Event-ID header resumes after that event
+// instead.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := eventFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	sub, replay := s.events.Subscribe(filter)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stop nginx and friends from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Tell the browser how long to wait before reconnecting.
+	fmt.Fprint(w, "retry: 5000\n\n")
+
+	for _, ev := range replay {
+		if err := writeEvent(w, ev); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		slog.Debug("Event stream cannot be flushed", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(s.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// resumes from its Last-Event-ID.
+				slog.Debug("Event stream client fell behind, closing", "remote", r.RemoteAddr)
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// eventFilter parses the event stream's query parameters and
+// Last-Event-ID header.
+func eventFilter(r *http.Request) (events.Filter, error) {
+	var f events.Filter
+	for _, v := range r.URL.Query()["station"] {
+		for id := range strings.SplitSeq(v, ",") {
+			if id = strings.ToUpper(strings.TrimSpace(id)); id != "" {
+				f.Stations = append(f.Stations, id)
+			}
+		}
+	}
+	if v := r.URL.Query().Get("replay"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, fmt.Errorf("replay must be a non-negative integer, got %q", v)
+		}
+		f.Replay = n
+	}
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		// An unparseable ID can't be resumed from; fall back to replay.
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			f.After = id
+		}
+	}
+	return f, nil
+}
+
+// writeEvent writes ev as one Server-Sent Event named after its type.
+func writeEvent(w http.ResponseWriter, ev events.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+	return err
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/events"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// sseEvent is one parsed Server-Sent Event.
+type sseEvent struct {
+	id, name string
+	data     events.Event
+}
+
+// readEvent reads the next event from an SSE stream, skipping comments and
+// the retry hint.
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if ev.name != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, "id: "):
+			ev.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev.data); err != nil {
+				t.Fatalf("event data: %v", err)
+			}
+		}
+	}
+}
+
+func openStream(t *testing.T, url string, header http.Header) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %d", url, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+// waitSubscribers waits for the broker to have n subscribers.
+func waitSubscribers(t *testing.T, b *events.Broker, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Subscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Subscribers() = %d, want %d", b.Subscribers(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	broker := events.NewBroker(0)
+	broker.Publish(events.Event{Type: events.Startup, Station: "KATX", New: &radar.Data{VCP: "R35"}})
+	broker.Publish(events.Event{Type: events.Startup, Station: "KRAX", New: &radar.Data{VCP: "R35"}})
+
+	ts := httptest.NewServer(New(Config{Monitor: newAPIFixture(), Events: broker}))
+	t.Cleanup(ts.Close)
+
+	stream := openStream(t, ts.URL+"/api/events?station=katx&replay=5", nil)
+	if ev := readEvent(t, stream); ev.id != "1" || ev.name != "startup" || ev.data.Station != "KATX" || ev.data.New.VCP != "R35" {
+		t.Errorf("replayed event = %+v, want KATX's startup", ev)
+	}
+
+	waitSubscribers(t, broker, 1)
+	broker.Publish(events.Event{Type: events.PollError, Station: "KRAX", Error: "NWS unavailable"})
+	broker.Publish(events.Event{Type: events.Change, Station: "KATX", Changes: []radar.Change{{Field: radar.FieldVCP, Old: "R35", New: "R215"}}})
+	if ev := readEvent(t, stream); ev.id != "4" || ev.name != "change" || ev.data.Changes[0].New != "R215" {
+		t.Errorf("live event = %+v, want KATX's change", ev)
+	}
+}
+
+func TestEventStreamResumesFromLastEventID(t *testing.T) {
+	broker := events.NewBroker(0)
+	for range 3 {
+		broker.Publish(events.Event{Type: events.Change, Station: "KATX"})
+	}
+	ts := httptest.NewServer(New(Config{Monitor: newAPIFixture(), Events: broker}))
+	t.Cleanup(ts.Close)
+
+	stream := openStream(t, ts.URL+"/api/events", http.Header{"Last-Event-Id": {"1"}})
+	for _, want := range []string{"2", "3"} {
+		if ev := readEvent(t, stream); ev.id != want {
+			t.Errorf("resumed event id = %s, want %s", ev.id, want)
+		}
+	}
+}
+
+func TestEventStreamKeepAlive(t *testing.T) {
+	s := New(Config{Monitor: newAPIFixture(), Events: events.NewBroker(0)})
+	s.keepAlive = 10 * time.Millisecond
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	stream := openStream(t, ts.URL+"/api/events", nil)
+	for {
+		line, err := stream.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == ": keep-alive\n" {
+			return
+		}
+	}
+}
+
+func TestEventStreamRoutes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Config{Monitor: newAPIFixture()}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /api/events without a broker = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	New(Config{Monitor: newAPIFixture(), Events: events.NewBroker(0)}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events?replay=-1", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /api/events?replay=-1 = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,200 @@
+// Package state persists the monitor's last-known radar data per station so
+// that a restart neither re-announces every station nor loses a change that
+// happened while dras was down. It also keeps the stations added, removed,
+// paused and re-configured through the admin API.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+// fileVersion is written into every state file so a future format change can
+// be detected on load instead of silently misread.
+const fileVersion = 1
+
+// Record is the persisted state for a single station.
+type Record struct {
+	// Data is the last radar data the monitor compared against (and, for
+	// changes, notified about).
+	Data *radar.Data `json:"data"`
+	// Incident is the station's open incident, if any (see radar.Incident).
+	Incident *radar.Incident `json:"incident,omitempty"`
+	// UpdatedAt is when the record was last written.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store loads and saves per-station records. Implementations must be safe
+// for concurrent use; the monitor processes stations in parallel.
+type Store interface {
+	// Load returns the record for the station. The boolean is false when no
+	// record exists, which is not an error.
+	Load(stationID string) (Record, bool, error)
+	// Save replaces the record for the station.
+	Save(stationID string, rec Record) error
+}
+
+// RuntimeStore is implemented by stores that can also persist runtime
+// station management, so it survives a restart.
+type RuntimeStore interface {
+	// LoadRuntime returns the persisted runtime changes. The boolean is
+	// false when none were persisted, which is not an error.
+	LoadRuntime() (config.Runtime, bool, error)
+	// SaveRuntime replaces the persisted runtime changes.
+	SaveRuntime(rt config.Runtime) error
+}
+
+// fileContents is the on-disk JSON shape of a FileStore.
+type fileContents struct {
+	Version  int               `json:"version"`
+	Stations map[string]Record `json:"stations"`
+	Runtime  *config.Runtime   `json:"runtime,omitempty"`
+}
+
+// FileStore is a Store backed by a single JSON file. The whole file is kept
+// in memory and rewritten atomically (temp file + rename) on every Save, so a
+// crash mid-write leaves the previous contents intact.
+type FileStore struct {
+	path string
+
+	mu      sync.Mutex
+	records map[string]Record
+	runtime *config.Runtime
+}
+
+// NewFileStore opens the JSON state file at path. A missing file is not an
+// error — it is created on the first Save. An unreadable or corrupt file is,
+// so a bad volume mount fails loudly at startup rather than silently
+// re-announcing every station.
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, errors.New("state file path cannot be empty")
+	}
+
+	s := &FileStore{
+		path:    path,
+		records: make(map[string]Record),
+	}
+
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return s, nil
+	case err != nil:
+		return nil, fmt.Errorf("read state file %q: %w", path, err)
+	}
+
+	var contents fileContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return nil, fmt.Errorf("decode state file %q: %w", path, err)
+	}
+	if contents.Version != fileVersion {
+		return nil, fmt.Errorf("state file %q has unsupported version %d (want %d)", path, contents.Version, fileVersion)
+	}
+	for id, rec := range contents.Stations {
+		if rec.Data == nil {
+			continue
+		}
+		s.records[id] = rec
+	}
+	s.runtime = contents.Runtime
+	return s, nil
+}
+
+// Path returns the file the store reads from and writes to.
+func (s *FileStore) Path() string {
+	return s.path
+}
+
+// Load returns the record for the station, if one has been persisted.
+func (s *FileStore) Load(stationID string) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[stationID]
+	if !ok {
+		return Record{}, false, nil
+	}
+	// Hand back a copy so callers can't mutate what is about to be written.
+	data := *rec.Data
+	rec.Data = &data
+	rec.Incident = rec.Incident.Clone()
+	return rec, true, nil
+}
+
+// Save replaces the record for the station and rewrites the state file.
+func (s *FileStore) Save(stationID string, rec Record) error {
+	if rec.Data == nil {
+		return fmt.Errorf("refusing to save empty record for station %s", stationID)
+	}
+	data := *rec.Data
+	rec.Data = &data
+	rec.Incident = rec.Incident.Clone()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[stationID] = rec
+	return s.writeLocked()
+}
+
+// LoadRuntime returns the runtime station management, if any has been
+// persisted.
+func (s *FileStore) LoadRuntime() (config.Runtime, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runtime == nil {
+		return config.Runtime{}, false, nil
+	}
+	return s.runtime.Clone(), true, nil
+}
+
+// SaveRuntime replaces the runtime station management and rewrites the
+// state file.
+func (s *FileStore) SaveRuntime(rt config.Runtime) error {
+	rt = rt.Clone()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runtime = &rt
+	return s.writeLocked()
+}
+
+// writeLocked serializes all records to a temp file in the same directory
+// and renames it over the state file. Callers must hold s.mu.
+func (s *FileStore) writeLocked() error {
+	raw, err := json.MarshalIndent(fileContents{Version: fileVersion, Stations: s.records, Runtime: s.runtime}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp state file in %q: %w", dir, err)
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(raw); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write temp state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("sync temp state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp state file: %w", err)
+	}
+	if err := os.Rename(tmpName, s.path); err != nil {
+		return fmt.Errorf("replace state file %q: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() on missing file: %v", err)
+	}
+	if _, ok, err := store.Load("KATX"); err != nil || ok {
+		t.Fatalf("Load() on empty store = ok %v, err %v; want no record", ok, err)
+	}
+
+	updated := time.Date(2026, 4, 26, 15, 32, 0, 0, time.UTC)
+	data := &radar.Data{
+		Name: "Seattle", VCP: "R35", Mode: "Clear Air",
+		Status: "Operate", OperabilityStatus: "RDA - On-line",
+		PowerSource: "Commercial Utility", GenState: "Off",
+	}
+	if err := store.Save("KATX", Record{Data: data, UpdatedAt: updated}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	// Mutating the caller's copy after Save must not leak into the store.
+	data.VCP = "R12"
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("NewFileStore() on existing file: %v", err)
+	}
+	rec, ok, err := reopened.Load("KATX")
+	if err != nil || !ok {
+		t.Fatalf("Load() after reopen = ok %v, err %v; want record", ok, err)
+	}
+	if rec.Data.VCP != "R35" {
+		t.Errorf("persisted VCP = %q, want R35", rec.Data.VCP)
+	}
+	if rec.Data.Name != "Seattle" || rec.Data.Status != "Operate" {
+		t.Errorf("persisted data = %+v", rec.Data)
+	}
+	if !rec.UpdatedAt.Equal(updated) {
+		t.Errorf("UpdatedAt = %v, want %v", rec.UpdatedAt, updated)
+	}
+}
+
+func TestFileStoreIncidentRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2026, 4, 26, 15, 32, 0, 0, time.UTC)
+	incident := &radar.Incident{
+		Started: started,
+		States:  []radar.IncidentState{{Time: started, Status: "Standby", OperabilityStatus: "RDA - On-line", Severity: radar.SeverityCritical}},
+	}
+	if err := store.Save("KATX", Record{Data: &radar.Data{Status: "Standby"}, Incident: incident, UpdatedAt: started}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	incident.States[0].Status = "Operate"
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, _, err := reopened.Load("KATX")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if rec.Incident == nil || !rec.Incident.Started.Equal(started) || len(rec.Incident.States) != 1 || rec.Incident.States[0].Status != "Standby" {
+		t.Errorf("persisted incident = %+v", rec.Incident)
+	}
+}
+
+func TestFileStoreRuntimeRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := store.LoadRuntime(); err != nil || ok {
+		t.Fatalf("LoadRuntime() on empty store = ok %v, err %v; want nothing", ok, err)
+	}
+
+	off := false
+	rt := config.Runtime{
+		Added:  []string{"KTLX"},
+		Paused: []string{"KATX"},
+		Alerts: map[string]config.AlertOverride{"KATX": {VCP: &off}},
+	}
+	if err := store.SaveRuntime(rt); err != nil {
+		t.Fatalf("SaveRuntime() error: %v", err)
+	}
+	if err := store.Save("KATX", Record{Data: &radar.Data{VCP: "R35"}}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	reopened, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := reopened.LoadRuntime()
+	if err != nil || !ok {
+		t.Fatalf("LoadRuntime() after reopen = ok %v, err %v; want runtime", ok, err)
+	}
+	if len(got.Added) != 1 || got.Added[0] != "KTLX" || got.Paused[0] != "KATX" || *got.Alerts["KATX"].VCP {
+		t.Errorf("LoadRuntime() = %+v, want what was saved", got)
+	}
+	if _, ok, _ := reopened.Load("KATX"); !ok {
+		t.Error("saving the runtime lost the station record")
+	}
+}
+
+func TestFileStoreLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "state.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore() error: %v", err)
+	}
+	for _, vcp := range []string{"R31", "R12", "R212"} {
+		if err := store.Save("KATX", Record{Data: &radar.Data{VCP: vcp}}); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "state.json" {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		t.Errorf("directory contents = %v, want only state.json", names)
+	}
+}
+
+func TestNewFileStoreRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{name: "corrupt json", contents: "{not json"},
+		{name: "unsupported version", contents: `{"version": 99, "stations": {}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatalf("WriteFile() error: %v", err)
+			}
+			if _, err := NewFileStore(path); err == nil {
+				t.Error("NewFileStore() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestFileStoreSaveRejectsNilData(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("NewFileStore() error: %v", err)
+	}
+	if err := store.Save("KATX", Record{}); err == nil {
+		t.Error("Save() with nil data error = nil, want error")
+	}
+}
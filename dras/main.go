@@ -2,29 +2,48 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	// The container image is built FROM scratch, without a zoneinfo
+	// database; quiet-hours time zones need one.
+	_ "time/tzdata"
 
 	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/events"
+	"github.com/jacaudi/dras/internal/httpretry"
 	"github.com/jacaudi/dras/internal/image"
+	"github.com/jacaudi/dras/internal/metrics"
 	"github.com/jacaudi/dras/internal/monitor"
 	"github.com/jacaudi/dras/internal/notify"
 	"github.com/jacaudi/dras/internal/radar"
 	"github.com/jacaudi/dras/internal/renderer"
+	"github.com/jacaudi/dras/internal/server"
+	"github.com/jacaudi/dras/internal/state"
 	"github.com/jacaudi/dras/internal/version"
-	"github.com/jacaudi/nws/cmd/nws"
 )
 
-// The program checks environment variables, initializes services, and starts the monitoring service.
+// The program reads the optional config file and environment variables, initializes services, and starts the monitoring service.
 // If the minuteInterval is not set, it defaults to 10 minutes.
 // If dryrun is enabled, it uses test radar sites for monitoring.
 // Otherwise, it sanitizes the station IDs provided by the user.
 // It sets the UserAgent to the DRAS GitHub repository and fetches and reports radar data.
+//
+// A subcommand after the flags runs a one-shot task instead; see commands.go.
 func main() {
+	configPath := flag.String("config", os.Getenv("DRAS_CONFIG"), "path to a YAML config file (env: DRAS_CONFIG)")
+	flag.Usage = func() { usage(flag.CommandLine.Output()) }
+	flag.Parse()
+	os.Exit(runCommand(*configPath, flag.Args(), os.Stdout, os.Stderr))
+}
+
+// serve runs the monitoring service until ctx is cancelled.
+func serve(ctx context.Context, configPath string) {
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.LoadFile(configPath)
 	if err != nil {
 		fatal("Error loading configuration: %v", err)
 	}
@@ -46,29 +65,96 @@ func main() {
 	// Display runtime configuration (but mask sensitive values)
 	slog.Info("Configuration loaded successfully")
 	slog.Debug("Runtime configuration",
+		"config_file", cfg.ConfigFile,
 		"dry_run", fmt.Sprintf("%t", cfg.DryRun),
 		"check_interval", cfg.CheckInterval.String(),
 		"log_level", cfg.LogLevel,
 	)
 
-	// Set NWS UserAgent
-	userAgent := fmt.Sprintf("dras/%s (+https://github.com/jacaudi/dras)", versionInfo.Version)
+	userAgent := httpUserAgent()
 	slog.Info(fmt.Sprintf("Setting NWS UserAgent to %s", userAgent))
-	nwsConfig := nws.Config{}
-	nwsConfig.SetUserAgent(userAgent)
 
+	// Metrics and the event stream are only collected when the HTTP server
+	// that exposes them is enabled; a nil *metrics.Metrics or *events.Broker
+	// turns every observation into a no-op.
+	var mx *metrics.Metrics
+	var broker *events.Broker
+	if cfg.HTTPAddr != "" {
+		mx = metrics.New()
+		httpretry.SetRetryObserver(mx.ObserveRetry)
+		notify.SetReceiptObserver(mx.ObserveReceipt)
+		broker = events.NewBroker(events.DefaultReplaySize)
+	}
+
+	monitorService, readinessChecks, err := newMonitor(cfg, userAgent, mx, monitor.WithEvents(broker))
+	if err != nil {
+		fatal("Error initializing monitor: %v", err)
+	}
+
+	// Reload the configuration on SIGHUP or when the config file changes.
+	reloads := &reloader{configPath: configPath, monitor: monitorService, current: cfg, metrics: mx}
+	go reloads.run(ctx)
+
+	if cfg.HTTPAddr != "" {
+		handler := server.New(server.Config{
+			Monitor:         monitorService,
+			Metrics:         mx,
+			Events:          broker,
+			Receipts:        notify.Receipts,
+			Admin:           monitorService,
+			AdminToken:      cfg.AdminToken,
+			ReadinessChecks: readinessChecks,
+		})
+		if err := serveHTTP(ctx, cfg.HTTPAddr, handler); err != nil {
+			fatal("Error starting HTTP server: %v", err)
+		}
+		slog.Info("HTTP server listening", "addr", cfg.HTTPAddr)
+	}
+
+	// Start monitoring
+	slog.Info("Starting radar monitoring service")
+	if err := monitorService.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		fatal("Error starting monitor: %v", err)
+	}
+	slog.Info("Shutdown complete")
+}
+
+// httpUserAgent returns the User-Agent of every outbound request DRAS
+// makes. The NWS API asks clients to identify themselves with one.
+func httpUserAgent() string {
+	return fmt.Sprintf("dras/%s (+https://github.com/jacaudi/dras)", version.Get().Version)
+}
+
+// newMonitor builds the monitor for a validated configuration: the
+// notification backends (none in dry-run mode), the radar image source and
+// the persistent state store. mx may be nil. It also returns the readiness
+// checks of the services it set up.
+func newMonitor(cfg *config.Config, userAgent string, mx *metrics.Metrics, opts ...monitor.Option) (*monitor.Monitor, []server.Check, error) {
 	// Initialize services
-	radarService := radar.New()
-	var notifyService *notify.Service
+	radarService := radar.New(radar.Config{BaseURL: cfg.NWSBaseURL, UserAgent: userAgent, OnCache: mx.ObserveNWSCache})
+	if cfg.NWSBaseURL != "" {
+		slog.Info("Using a custom NWS API", "url", cfg.NWSBaseURL)
+	}
+	var notifyService notify.Notifier
+	monitorOpts := append([]monitor.Option{monitor.WithMetrics(mx)}, opts...)
 	if !cfg.DryRun {
-		slog.Debug("Initializing notification service")
-		notifyService = notify.New(cfg.PushoverAPIToken, cfg.PushoverUserKey)
+		slog.Debug("Initializing notification backends")
+		var stationNotifiers map[string]notify.Notifier
+		var err error
+		notifyService, stationNotifiers, err = buildNotifiers(cfg, mx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("notification backend setup failed: %w", err)
+		}
+		backendNames := make([]string, 0, len(cfg.NotifierBackends()))
+		for _, b := range cfg.NotifierBackends() {
+			backendNames = append(backendNames, b.DisplayName())
+		}
+		slog.Info("Notification backends initialized", "notifiers", strings.Join(backendNames, ","))
 
-		// Validate Pushover credentials
-		if err := notifyService.ValidateCredentials(); err != nil {
-			fatal("Pushover credentials validation failed: %v", err)
+		// Stations with their own Pushover recipient get their own notifier.
+		if len(stationNotifiers) > 0 {
+			monitorOpts = append(monitorOpts, monitor.WithStationNotifiers(stationNotifiers))
 		}
-		slog.Info("Pushover credentials validated successfully")
 	} else {
 		slog.Info("Running in dry-run mode, notifications disabled")
 	}
@@ -78,6 +164,7 @@ func main() {
 	//   - Basic   (RENDERER_URL empty, RadarImageEnabled true): legacy ridge GIF fetcher.
 	//   - Disabled (neither): no image attached to notifications.
 	var imageSource image.Source
+	var readinessChecks []server.Check
 	switch {
 	case cfg.RendererURL != "":
 		// Modes are mutually exclusive. If basic-mode settings were also
@@ -90,11 +177,13 @@ func main() {
 				"radar_image_retention", cfg.RadarImageRetention.String(),
 			)
 		}
-		imageSource = renderer.New(renderer.Config{
+		rendererClient := renderer.New(renderer.Config{
 			BaseURL:   cfg.RendererURL,
 			Timeout:   cfg.RendererTimeout,
 			UserAgent: userAgent,
 		})
+		imageSource = rendererClient
+		readinessChecks = append(readinessChecks, server.Check{Name: "renderer", Run: rendererClient.Ping})
 		slog.Info("Radar image source enabled",
 			"mode", "advanced",
 			"renderer_url", cfg.RendererURL,
@@ -109,12 +198,7 @@ func main() {
 		})
 		imageSource = svc
 
-		var pollStations []string
-		if cfg.DryRun {
-			pollStations = []string{"KATX", "KRAX"}
-		} else {
-			pollStations = radar.SanitizeStationIDs(cfg.StationInput)
-		}
+		pollStations := cfg.StationIDs()
 		pollURLs := make([]string, len(pollStations))
 		for i, s := range pollStations {
 			pollURLs[i] = svc.URLFor(s)
@@ -130,13 +214,17 @@ func main() {
 		slog.Info("Radar image source disabled")
 	}
 
-	// Initialize monitor
-	monitorService := monitor.New(radarService, notifyService, imageSource, cfg)
-
-	// Start monitoring
-	slog.Info("Starting radar monitoring service")
-	ctx := context.Background()
-	if err := monitorService.Start(ctx); err != nil {
-		fatal("Error starting monitor: %v", err)
+	// Initialize persistent state. Without STATE_FILE the monitor keeps
+	// state in memory only and re-announces every station on restart.
+	if cfg.StateFile != "" {
+		store, err := state.NewFileStore(cfg.StateFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error opening state file: %w", err)
+		}
+		monitorOpts = append(monitorOpts, monitor.WithStateStore(store))
+		slog.Info("Persistent state enabled", "state_file", cfg.StateFile)
 	}
+
+	// Initialize monitor
+	return monitor.New(radarService, notifyService, imageSource, cfg, monitorOpts...), readinessChecks, nil
 }
@@ -51,7 +51,7 @@ func TestMainIntegration(t *testing.T) {
 			}
 
 			// Initialize services like main() does
-			radarService := radar.New()
+			radarService := radar.New(radar.Config{})
 			if radarService == nil {
 				t.Error("Expected radar service to be initialized")
 			}
@@ -90,7 +90,7 @@ func TestMainIntegration(t *testing.T) {
 			}
 
 			// Initialize services like main() does
-			radarService := radar.New()
+			radarService := radar.New(radar.Config{})
 			if radarService == nil {
 				t.Error("Expected radar service to be initialized")
 			}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/metrics"
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+// buildNotifiers creates the default notifier from every configured backend,
+// plus a notifier for each station with its own Pushover user key. A
+// station's notifier delivers to the same backends as the default one,
+// except that its Pushover messages go to the station's recipient. With mx
+// set, every backend's deliveries are counted in it.
+func buildNotifiers(cfg *config.Config, mx *metrics.Metrics) (notify.Notifier, map[string]notify.Notifier, error) {
+	var targets []notify.Target
+	for _, b := range cfg.NotifierBackends() {
+		n, err := notify.Build(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets = append(targets, notify.Target{Name: b.DisplayName(), Notifier: mx.Notifier(b.DisplayName(), n)})
+	}
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("no notification backends configured")
+	}
+
+	stations := make(map[string]notify.Notifier)
+	for _, id := range cfg.StationIDs() {
+		key := cfg.Station(id).PushoverUserKey
+		if key == "" {
+			continue
+		}
+		pushover := notify.New(cfg.PushoverAPIToken, key, notify.WithPushoverMessages(cfg.PushoverMessages))
+		stationTargets := []notify.Target{{Name: "pushover", Notifier: mx.Notifier("pushover", pushover)}}
+		for _, t := range targets {
+			if t.Name != "pushover" {
+				stationTargets = append(stationTargets, t)
+			}
+		}
+		stations[id] = fanOut(stationTargets)
+	}
+
+	return fanOut(targets), stations, nil
+}
+
+// fanOut returns the only target's notifier as-is, or a notify.Multi over
+// all of them.
+func fanOut(targets []notify.Target) notify.Notifier {
+	if len(targets) == 1 {
+		return targets[0].Notifier
+	}
+	return notify.NewMulti(targets...)
+}
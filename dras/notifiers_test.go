@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/notify"
+)
+
+func TestBuildNotifiers(t *testing.T) {
+	cfg := &config.Config{
+		StationInput:     "KATX,KRAX",
+		PushoverAPIToken: "abcdef1234567890123456789012ab",
+		PushoverUserKey:  "uvwxyz1234567890123456789012uv",
+		Notifiers: []notify.BackendConfig{
+			{Type: "ntfy", Topic: "dras"},
+		},
+		Stations: []config.StationOverride{
+			{ID: "KRAX", PushoverUserKey: "ZYXWVUTSRQPONMLKJIHGFEDCBA4321"},
+		},
+	}
+
+	def, stations, err := buildNotifiers(cfg, nil)
+	if err != nil {
+		t.Fatalf("buildNotifiers() error: %v", err)
+	}
+	multi, ok := def.(*notify.Multi)
+	if !ok || len(multi.Targets()) != 2 {
+		t.Fatalf("default notifier = %T, want Multi over pushover + ntfy", def)
+	}
+
+	if _, ok := stations["KATX"]; ok {
+		t.Error("KATX has its own notifier, want it to use the default")
+	}
+	krax, ok := stations["KRAX"].(*notify.Multi)
+	if !ok {
+		t.Fatalf("KRAX notifier = %T, want Multi", stations["KRAX"])
+	}
+	targets := krax.Targets()
+	if len(targets) != 2 || targets[0].Name != "pushover" || targets[1].Name != "ntfy" {
+		t.Errorf("KRAX targets = %+v, want its own pushover + shared ntfy", targets)
+	}
+	if targets[0].Notifier == multi.Targets()[0].Notifier {
+		t.Error("KRAX pushover target is the shared one, want a per-station recipient")
+	}
+}
+
+func TestBuildNotifiersSingleBackendIsNotWrapped(t *testing.T) {
+	cfg := &config.Config{
+		PushoverAPIToken: "abcdef1234567890123456789012ab",
+		PushoverUserKey:  "uvwxyz1234567890123456789012uv",
+	}
+	def, _, err := buildNotifiers(cfg, nil)
+	if err != nil {
+		t.Fatalf("buildNotifiers() error: %v", err)
+	}
+	if _, ok := def.(*notify.Service); !ok {
+		t.Errorf("default notifier = %T, want *notify.Service", def)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/metrics"
+	"github.com/jacaudi/dras/internal/monitor"
+)
+
+// configWatchInterval is how often the config file's modification time is
+// checked for changes. SIGHUP reloads immediately.
+const configWatchInterval = 30 * time.Second
+
+// reloader re-reads the configuration on SIGHUP or when the config file
+// changes, and hands valid results to the monitor. An invalid configuration
+// is logged and discarded; the monitor keeps running with the old one.
+type reloader struct {
+	configPath string
+	monitor    *monitor.Monitor
+	current    *config.Config
+	// metrics, when set, counts the deliveries of rebuilt notifiers.
+	metrics *metrics.Metrics
+}
+
+// run blocks until ctx is done, reloading on SIGHUP and, when a config file
+// is in use, whenever its modification time or size changes.
+func (r *reloader) run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileChanged <-chan struct{}
+	if r.configPath != "" {
+		fileChanged = watchFile(ctx, r.configPath, configWatchInterval)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload("SIGHUP")
+		case <-fileChanged:
+			r.reload("config file changed")
+		}
+	}
+}
+
+// reload loads and validates the configuration and applies it to the
+// monitor. Settings that only take effect at startup keep their old values.
+func (r *reloader) reload(reason string) {
+	slog.Info("Reloading configuration", "reason", reason, "config_file", r.configPath)
+
+	next, err := config.LoadFile(r.configPath)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		slog.Error("Configuration reload rejected, keeping current configuration", "error", err)
+		return
+	}
+
+	for _, setting := range keepStartupSettings(r.current, next) {
+		slog.Warn("Configuration change requires a restart to take effect", "setting", setting)
+	}
+
+	var opts []monitor.Option
+	if !next.DryRun {
+		notifier, stations, err := buildNotifiers(next, r.metrics)
+		if err != nil {
+			slog.Error("Configuration reload rejected, keeping current configuration", "error", err)
+			return
+		}
+		opts = append(opts, monitor.WithNotifier(notifier), monitor.WithStationNotifiers(stations))
+	}
+	r.monitor.Reload(next, opts...)
+	r.current = next
+}
+
+// keepStartupSettings copies the settings that are only read at startup from
+// prev into next, and returns the names of the ones that differed. These
+// configure services built once in main (image source, state store,
+// logging, HTTP server) and can't be swapped under a running monitor.
+func keepStartupSettings(prev, next *config.Config) []string {
+	var changed []string
+	if prev.DryRun != next.DryRun {
+		changed = append(changed, "DRYRUN")
+		next.DryRun = prev.DryRun
+	}
+	if prev.LogLevel != next.LogLevel {
+		changed = append(changed, "LOG_LEVEL")
+		next.LogLevel = prev.LogLevel
+	}
+	if prev.HTTPAddr != next.HTTPAddr {
+		changed = append(changed, "HTTP_ADDR")
+		next.HTTPAddr = prev.HTTPAddr
+	}
+	if prev.AdminToken != next.AdminToken {
+		changed = append(changed, "ADMIN_TOKEN")
+		next.AdminToken = prev.AdminToken
+	}
+	if prev.StateFile != next.StateFile {
+		changed = append(changed, "STATE_FILE")
+		next.StateFile = prev.StateFile
+	}
+	if prev.NWSBaseURL != next.NWSBaseURL {
+		changed = append(changed, "NWS_BASE_URL")
+		next.NWSBaseURL = prev.NWSBaseURL
+	}
+	if prev.RendererURL != next.RendererURL || prev.RendererTimeout != next.RendererTimeout {
+		changed = append(changed, "RENDERER_*")
+		next.RendererURL, next.RendererTimeout = prev.RendererURL, prev.RendererTimeout
+	}
+	if prev.RadarImageEnabled != next.RadarImageEnabled ||
+		prev.RadarImageURLTmpl != next.RadarImageURLTmpl ||
+		prev.RadarImageRetention != next.RadarImageRetention {
+		changed = append(changed, "RADAR_IMAGE_*")
+		next.RadarImageEnabled = prev.RadarImageEnabled
+		next.RadarImageURLTmpl = prev.RadarImageURLTmpl
+		next.RadarImageRetention = prev.RadarImageRetention
+	}
+	return changed
+}
+
+// watchFile polls path every interval and signals on the returned channel
+// when its modification time or size changes. A file that temporarily
+// disappears (editors that replace rather than rewrite) is not a change;
+// it is compared again once it is back.
+func watchFile(ctx context.Context, path string, interval time.Duration) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	last, _ := os.Stat(path)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+				continue
+			}
+			last = info
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changed
+}
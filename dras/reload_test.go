@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/config"
+	"github.com/jacaudi/dras/internal/monitor"
+	"github.com/jacaudi/dras/internal/notify"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestKeepStartupSettings(t *testing.T) {
+	prev := &config.Config{
+		PushoverAPIToken: "old-token",
+		LogLevel:         "INFO",
+		StateFile:        "/var/lib/dras/state.json",
+		AlertConfig:      radar.AlertConfig{VCP: true},
+	}
+	next := &config.Config{
+		PushoverAPIToken: "new-token",
+		LogLevel:         "DEBUG",
+		StateFile:        "/var/lib/dras/state.json",
+		AlertConfig:      radar.AlertConfig{VCP: true, Status: true},
+	}
+
+	changed := keepStartupSettings(prev, next)
+	if got := strings.Join(changed, ","); got != "LOG_LEVEL" {
+		t.Errorf("changed = %q, want LOG_LEVEL", got)
+	}
+	if next.LogLevel != "INFO" {
+		t.Errorf("startup-only LogLevel not restored: %q", next.LogLevel)
+	}
+	if next.PushoverAPIToken != "new-token" {
+		t.Errorf("PushoverAPIToken = %q, want the reloaded value (notifiers are rebuilt live)", next.PushoverAPIToken)
+	}
+	if !next.AlertConfig.Status {
+		t.Error("live setting AlertConfig.Status was reverted, want it kept")
+	}
+}
+
+func TestReloaderRejectsInvalidConfig(t *testing.T) {
+	for _, key := range []string{"STATION_IDS", "DRYRUN", "INTERVAL", "PUSHOVER_API_TOKEN", "PUSHOVER_USER_KEY"} {
+		t.Setenv(key, "")
+	}
+	path := filepath.Join(t.TempDir(), "dras.yaml")
+	writeFile(t, path, "dry_run: true\ninterval: 5\n")
+
+	current, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error: %v", err)
+	}
+	m := monitor.New(radar.NewMockDataFetcher(), notify.NewMockNotifier(), nil, current)
+	r := &reloader{configPath: path, monitor: m, current: current}
+
+	writeFile(t, path, "dry_run: true\ninterval: 0\n")
+	r.reload("test")
+	if r.current != current {
+		t.Error("invalid config replaced the running one")
+	}
+
+	writeFile(t, path, "dry_run: true\ninterval: 2\n")
+	r.reload("test")
+	if r.current == current || r.current.CheckInterval != 2*time.Minute {
+		t.Errorf("valid config not applied: current interval %v", r.current.CheckInterval)
+	}
+}
+
+func TestWatchFileSignalsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dras.yaml")
+	writeFile(t, path, "interval: 5\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := watchFile(ctx, path, 10*time.Millisecond)
+
+	select {
+	case <-changed:
+		t.Fatal("watchFile signalled before the file changed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	writeFile(t, path, "interval: 10\n")
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("watchFile did not signal after the file changed")
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// httpShutdownTimeout bounds how long in-flight HTTP requests may take to
+// finish once the process is stopping.
+const httpShutdownTimeout = 5 * time.Second
+
+// serveHTTP listens on addr and serves handler until ctx is done. It
+// returns once the listener is open, so a port that is already in use is
+// reported at startup; the server itself runs in the background.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Requests inherit ctx so long-lived event streams end when the
+		// process stops instead of holding up Shutdown.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
+	context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("HTTP server shutdown", "error", err)
+		}
+	})
+	return nil
+}
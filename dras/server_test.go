@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jacaudi/dras/internal/metrics"
+	"github.com/jacaudi/dras/internal/radar"
+)
+
+func TestServeHTTPServesMetricsUntilCancelled(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	mx := metrics.New()
+	mx.SetRadarState("KATX", &radar.Data{VCP: "R35", Mode: "Clear Air", Status: "Operate"})
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", mx.Handler())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := serveHTTP(ctx, addr, mux); err != nil {
+		t.Fatalf("serveHTTP() error: %v", err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `dras_radar_info{mode="Clear Air",station="KATX",status="Operate",vcp="R35"} 1`) {
+		t.Errorf("metrics missing radar state:\n%s", body)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := http.Get("http://" + addr + "/metrics"); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server still serving after ctx was cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServeHTTPReportsListenErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if err := serveHTTP(context.Background(), ln.Addr().String(), http.NotFoundHandler()); err == nil {
+		t.Error("serveHTTP() on a port in use succeeded, want an error")
+	}
+}